@@ -3,3 +3,4 @@
 # Vars exported to the build info
 echo TECTONIC_VERSION "${TECTONIC_VERSION}"
 echo BUILD_TIME "$(date -u '+%Y-%m-%dT%H:%M:%S%z')"
+echo STABLE_GIT_COMMIT "$(git rev-parse --short HEAD 2>/dev/null || echo unknown)"
@@ -43,6 +43,38 @@ EOF
   default = "1.0"
 }
 
+variable "tectonic_ntp_servers" {
+  type    = "list"
+  default = []
+
+  description = <<EOF
+(optional) NTP servers the nodes synchronize their clock with, instead of the default pool servers.
+Required in networks without access to the public pool servers.
+
+Example: `["ntp1.example.com", "10.0.0.123"]`
+EOF
+}
+
+variable "tectonic_api_port" {
+  type    = "string"
+  default = "6443"
+
+  description = <<EOF
+(optional) The port the API is reachable on, e.g. 443 in networks blocking the default port.
+This applies only to AWS, where the API load balancers forward it to port 6443 of the masters.
+EOF
+}
+
+variable "tectonic_extra_manifests" {
+  description = <<EOF
+(internal) File names of the user supplied manifests, copied from the manifests-extra directory
+of the cluster into generated/manifests, to be installed on the bootstrap node.
+EOF
+
+  type    = "list"
+  default = []
+}
+
 variable "tectonic_image_re" {
   description = <<EOF
 (internal) Regular expression used to extract repo and tag components
@@ -76,6 +108,22 @@ variable "tectonic_container_images" {
   }
 }
 
+variable "tectonic_container_image_overrides" {
+  description = <<EOF
+(optional) Container images overriding the defaults in tectonic_container_images, keyed by component,
+e.g. to use custom builds or a mirror in disconnected environments.
+EOF
+
+  type    = "map"
+  default = {}
+}
+
+locals {
+  tectonic_container_images = "${merge(var.tectonic_container_images, var.tectonic_container_image_overrides)}"
+  tectonic_cluster_domain   = "${var.tectonic_cluster_domain != "" ? var.tectonic_cluster_domain : var.tectonic_base_domain}"
+  tectonic_ingress_domain   = "${var.tectonic_ingress_domain != "" ? var.tectonic_ingress_domain : "${var.tectonic_cluster_name}.${local.tectonic_cluster_domain}"}"
+}
+
 variable "tectonic_container_base_images" {
   description = "(internal) Base images of the components to use"
   type        = "map"
@@ -138,6 +186,7 @@ variable "tectonic_worker_count" {
   description = <<EOF
 The number of worker nodes to be created.
 This applies only to cloud platforms.
+Set to zero, with a single master, for a single-node cluster whose master also runs the workloads.
 EOF
 }
 
@@ -165,6 +214,19 @@ This applies only to cloud platforms.
 EOF
 }
 
+variable "tectonic_cluster_domain" {
+  type    = "string"
+  default = ""
+
+  description = <<EOF
+(optional) The DNS domain the host names of the cluster, e.g. `<tectonic_cluster_name>-api`, are in,
+if it should differ from `tectonic_base_domain`. It must be `tectonic_base_domain` or one of its subdomains,
+since the records are created in the zone of `tectonic_base_domain`.
+
+Example: `prod.openshift.example.com`
+EOF
+}
+
 variable "tectonic_cluster_name" {
   type = "string"
 
@@ -176,6 +238,17 @@ Note: This field MUST be set manually prior to creating the cluster.
 EOF
 }
 
+variable "tectonic_ingress_domain" {
+  type    = "string"
+  default = ""
+
+  description = <<EOF
+(optional) The domain under which applications are exposed by the ingress controller,
+if it should differ from the default `<tectonic_cluster_name>.<tectonic_cluster_domain>`.
+A wildcard DNS record for this domain, pointing at the ingress load balancer, must be created by the user.
+EOF
+}
+
 variable "tectonic_pull_secret_path" {
   type    = "string"
   default = ""
@@ -229,13 +302,13 @@ EOF
 variable "tectonic_update_server" {
   type        = "string"
   default     = "https://tectonic.update.core-os.net"
-  description = "(internal) The URL of the Tectonic Omaha update server"
+  description = "(optional) The URL of the Tectonic Omaha update server, e.g. a mirror for disconnected clusters"
 }
 
 variable "tectonic_update_channel" {
   type        = "string"
   default     = "tectonic-1.9-production"
-  description = "(internal) The Tectonic Omaha update channel"
+  description = "(optional) The Tectonic Omaha update channel, e.g. a pre-release channel for testing"
 }
 
 variable "tectonic_update_app_id" {
@@ -256,14 +329,41 @@ Note: This field MUST be in all lower-case e-mail address format and set manuall
 EOF
 }
 
+variable "tectonic_admin_cert_common_name" {
+  type    = "string"
+  default = "system:admin"
+
+  description = <<EOF
+(optional) The common name, i.e. the user, of the client certificate of the admin kubeconfig.
+EOF
+}
+
+variable "tectonic_admin_cert_organization" {
+  type    = "string"
+  default = "system:masters"
+
+  description = <<EOF
+(optional) The organization, i.e. the group, of the client certificate of the admin kubeconfig.
+EOF
+}
+
+variable "tectonic_admin_cert_validity_hours" {
+  type    = "string"
+  default = "26280"
+
+  description = <<EOF
+(optional) The validity period, in hours, of the client certificate of the admin kubeconfig.
+EOF
+}
+
 variable "tectonic_admin_password" {
   type = "string"
 
   description = <<EOF
 (internal) The admin user password to login to the Tectonic Console.
 
-Note: This field MUST be set manually prior to creating the cluster. Backslashes and double quotes must
-also be escaped.
+Note: When the cluster config has no admin password, the installer generates one and writes it to
+auth/admin-password in the cluster directory. Backslashes and double quotes must also be escaped.
 EOF
 }
 
@@ -272,7 +372,8 @@ variable "tectonic_ca_cert" {
   default = ""
 
   description = <<EOF
-(optional) The content of the PEM-encoded CA certificate, used to generate all cluster certificates.
+(optional) The path of the PEM-encoded CA certificate, used to sign the intermediate CAs issuing
+the cluster certificates, and the TNC certificate.
 If left blank, a CA certificate will be automatically generated.
 EOF
 }
@@ -282,11 +383,21 @@ variable "tectonic_ca_key" {
   default = ""
 
   description = <<EOF
-(optional) The content of the PEM-encoded CA key, used to generate Tectonic all cluster certificates.
+(optional) The path of the PEM-encoded CA key matching `tectonic_ca_cert`.
 This field is mandatory if `tectonic_ca_cert` is set.
 EOF
 }
 
+variable "tectonic_ca_signed_path" {
+  type    = "string"
+  default = ""
+
+  description = <<EOF
+(internal) Path of the intermediate CAs and TNC certificate signed by the installer with the root CA
+signer command, relative to the cluster directory, used instead of signing them with `tectonic_ca_key`.
+EOF
+}
+
 variable "tectonic_ca_key_alg" {
   type    = "string"
   default = "RSA"
@@ -298,6 +409,63 @@ This field is mandatory if `tectonic_ca_cert` is set.
 EOF
 }
 
+variable "tectonic_tls_additional_trust_bundle" {
+  type    = "string"
+  default = ""
+
+  description = <<EOF
+(optional) The path of PEM-encoded CA certificates added to the trust store of the nodes,
+e.g. of a corporate TLS-intercepting proxy.
+EOF
+}
+
+variable "tectonic_tls_ca_validity_hours" {
+  type    = "string"
+  default = "26280"
+
+  description = <<EOF
+(optional) The validity period, in hours, of the generated certificate authorities.
+EOF
+}
+
+variable "tectonic_tls_cert_validity_hours" {
+  type    = "string"
+  default = "26280"
+
+  description = <<EOF
+(optional) The validity period, in hours, of the generated certificates.
+It must not be longer than `tectonic_tls_ca_validity_hours`.
+EOF
+}
+
+variable "tectonic_tls_extra_dns_names" {
+  type    = "list"
+  default = []
+
+  description = <<EOF
+(internal) The DNS names of the `tls.extraSANs` of the config, added to the API server and TNC certificates.
+EOF
+}
+
+variable "tectonic_tls_extra_ip_addresses" {
+  type    = "list"
+  default = []
+
+  description = <<EOF
+(internal) The IP addresses of the `tls.extraSANs` of the config, added to the API server and TNC certificates.
+EOF
+}
+
+variable "tectonic_tls_rsa_bits" {
+  type    = "string"
+  default = "2048"
+
+  description = <<EOF
+(optional) The size, in bits, of the RSA keys of the generated certificate authorities and certificates.
+Must be one of 2048, 3072 or 4096.
+EOF
+}
+
 variable "tectonic_stats_url" {
   type        = "string"
   default     = "https://stats-collector.tectonic.com"
@@ -325,6 +493,16 @@ variable "tectonic_kubelet_debug_config" {
   description = "(internal) debug flags for the kubelet (used in CI only)"
 }
 
+variable "tectonic_ignition_bootstrap_overrides" {
+  type    = "string"
+  default = ""
+
+  description = <<EOF
+(internal) Path of the ignition config appended to the bootstrap one, generated by the installer
+from the bootstrap-overrides directory of the cluster.
+EOF
+}
+
 variable "tectonic_ignition_master" {
   type    = "string"
   default = ""
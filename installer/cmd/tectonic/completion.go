@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// fishCompletionTemplate defers to kingpin's --completion-bash flag, which
+// completes commands and flags for the given words, like the bash and zsh scripts do.
+const fishCompletionTemplate = `
+function __complete_{{.App.Name}}
+    set -l words (commandline -opc)
+    set -e words[1]
+    {{.App.Name}} --completion-bash $words
+end
+
+complete -c {{.App.Name}} -f -a '(__complete_{{.App.Name}})'
+`
+
+var completionTemplates = map[string]string{
+	"bash": kingpin.BashCompletionTemplate,
+	"fish": fishCompletionTemplate,
+	"zsh":  kingpin.ZshCompletionTemplate,
+}
+
+// printCompletion writes the completion script for the given shell to stdout.
+func printCompletion(shell string) error {
+	app := kingpin.CommandLine
+	ctx, err := app.ParseContext(nil)
+	if err != nil {
+		return err
+	}
+	app.Writer(os.Stdout)
+	return app.UsageForContextWithTemplate(ctx, 2, completionTemplates[shell])
+}
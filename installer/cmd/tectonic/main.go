@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	log "github.com/Sirupsen/logrus"
 	"gopkg.in/alecthomas/kingpin.v2"
 
+	"github.com/openshift/installer/installer/pkg/config"
+	"github.com/openshift/installer/installer/pkg/version"
 	"github.com/openshift/installer/installer/pkg/workflow"
 )
 
 var (
 	clusterInitCommand    = kingpin.Command("init", "Initialize a new Tectonic cluster")
 	clusterInitConfigFlag = clusterInitCommand.Flag("config", "Cluster specification file").Required().ExistingFile()
+	clusterInitEditFlag   = clusterInitCommand.Flag("edit", "Open the config copied into the cluster directory in $VISUAL or $EDITOR and validate it again once saved").Bool()
 
 	clusterInstallCommand          = kingpin.Command("install", "Create a new Tectonic cluster")
 	clusterInstallTLSCommand       = clusterInstallCommand.Command("tls", "Generate TLS Certificates.")
@@ -21,38 +28,158 @@ var (
 	clusterInstallFullCommand      = clusterInstallCommand.Command("full", "Create a new Tectonic cluster").Default()
 	clusterInstallJoinCommand      = clusterInstallCommand.Command("join", "Create master and worker nodes to join an exisiting Tectonic cluster.")
 	clusterInstallDirFlag          = clusterInstallCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	clusterInstallPlanFlag         = clusterInstallFullCommand.Flag("plan", "Show the infrastructure plan and ask for confirmation before creating it").Bool()
+	clusterInstallBundleFlag       = clusterInstallAssetsCommand.Flag("bundle", "Also bundle the generated manifests, in the order they are applied, as generated/manifests-bundle.<format>").Enum(workflow.BundleYAML, workflow.BundleTarball)
 
-	clusterDestroyCommand = kingpin.Command("destroy", "Destroy an existing Tectonic cluster")
-	clusterDestroyDirFlag = clusterDestroyCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	clusterRegenerateCommand      = kingpin.Command("regenerate", "Regenerate assets of an existing Tectonic cluster directory")
+	clusterRegenerateCertsCommand = clusterRegenerateCommand.Command("certs", "Re-issue the TLS certificates and the manifests and ignition configs embedding them, before the cluster is bootstrapped.")
+	clusterRegenerateDirFlag      = clusterRegenerateCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+
+	clusterDestroyCommand         = kingpin.Command("destroy", "Destroy an existing Tectonic cluster")
+	clusterDestroyDirFlag         = clusterDestroyCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	clusterDestroyDryRunFlag      = clusterDestroyCommand.Flag("dry-run", "List the resources that would be destroyed, without destroying them").Bool()
+	clusterDestroyParallelismFlag = clusterDestroyCommand.Flag("parallelism", "How many resources may be destroyed concurrently, 0 for terraform's default of 10").Default("0").Envar("TECTONIC_DESTROY_PARALLELISM").Int()
+	clusterDestroyRetainFlag      = clusterDestroyCommand.Flag("retain", "Address of a resource or module to keep, as listed by --dry-run (e.g. \"aws_route53_zone.tectonic_int\"); can be repeated").Strings()
+	clusterDestroyTimeoutFlag     = clusterDestroyCommand.Flag("timeout", "How long each destroy step may take before being interrupted (e.g. \"30m\"), 0 for no limit").Default("30m").Envar("TECTONIC_DESTROY_TIMEOUT").Duration()
+
+	fleetCommand            = kingpin.Command("fleet", "Create or destroy a cluster for every config file of a directory, concurrently")
+	fleetInstallCommand     = fleetCommand.Command("install", "Initialize, in the current directory, and create the clusters of the config files, resuming those already initialized")
+	fleetDestroyCommand     = fleetCommand.Command("destroy", "Destroy the clusters of the config files, initialized in the current directory")
+	fleetConfigsFlag        = fleetCommand.Flag("configs", "Directory of the cluster config files (*.yaml or *.yml)").Required().ExistingDir()
+	fleetParallelFlag       = fleetCommand.Flag("parallel", "How many clusters may be created or destroyed at once, 0 for all of them").Default("4").Int()
+	fleetDestroyTimeoutFlag = fleetDestroyCommand.Flag("timeout", "How long each destroy step of each cluster may take before being interrupted (e.g. \"30m\"), 0 for no limit").Default("30m").Duration()
+
+	waitForCommand                  = kingpin.Command("wait-for", "Wait for install-time events")
+	waitForBootstrapCompleteCommand = waitForCommand.Command("bootstrap-complete", "Wait until the API of a cluster, created with \"install bootstrap\", is healthy")
+	waitForInstallCompleteCommand   = waitForCommand.Command("install-complete", "Wait until the console of a cluster is served and print how to log in")
+	waitForDirFlag                  = waitForCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	waitForBootstrapTimeoutFlag     = waitForBootstrapCompleteCommand.Flag("timeout", "How long to wait (e.g. \"30m\")").Default("30m").Envar("TECTONIC_BOOTSTRAP_TIMEOUT").Duration()
+	waitForInstallTimeoutFlag       = waitForInstallCompleteCommand.Flag("timeout", "How long to wait (e.g. \"30m\")").Default("40m").Envar("TECTONIC_INSTALL_TIMEOUT").Duration()
+
+	gatherCommand              = kingpin.Command("gather", "Gather debugging data")
+	gatherBootstrapCommand     = gatherCommand.Command("bootstrap", "Gather, over SSH, the logs of the bootstrap node and optionally of the masters, and once the API is up the logs of the failing pods, into a redacted tarball")
+	gatherDirFlag              = gatherCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	gatherBootstrapAddressFlag = gatherBootstrapCommand.Flag("bootstrap", "Address of the bootstrap node").Required().String()
+	gatherMasterAddressesFlag  = gatherBootstrapCommand.Flag("master", "Address of a master node (may be repeated)").Strings()
+
+	analyzeCommand   = kingpin.Command("analyze", "Print the likely root causes of a failed install, and how to remediate them, recognized in a tarball written by \"gather\"")
+	analyzeBundleArg = analyzeCommand.Arg("bundle", "Tarball written by \"gather\"").Required().ExistingFile()
+
+	pxeCommand         = kingpin.Command("pxe", "Write the iPXE and GRUB configs booting nodes over the network with the Ignition config of the cluster, to pxe/<role>.ipxe and pxe/<role>.grub.cfg")
+	pxeDirFlag         = pxeCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	pxeRoleFlag        = pxeCommand.Flag("role", "Role of the nodes").Default(workflow.PXERoleWorker).Enum(workflow.PXERoleMaster, workflow.PXERoleWorker)
+	pxeIgnitionURLFlag = pxeCommand.Flag("ignition-base-url", "URL the Ignition configs of the cluster directory (e.g. ignition-worker.ign) are served at").Required().String()
+
+	dnsRecordsCommand    = kingpin.Command("dns-records", "Print the DNS records of an existing AWS cluster, e.g. for creating them in other DNS servers")
+	dnsRecordsDirFlag    = dnsRecordsCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+	dnsRecordsFormatFlag = dnsRecordsCommand.Flag("format", "Output format; Route 53 alias records are CNAME records in BIND zone files").Default(workflow.DNSRecordsJSON).Enum(workflow.DNSRecordsJSON, workflow.DNSRecordsBIND)
+
+	gcCommand    = kingpin.Command("gc", "List the AWS resources of clusters not initialized in a directory, e.g. left over by failed CI runs, and optionally delete them")
+	gcDirFlag    = gcCommand.Flag("dir", "Directory the clusters are initialized in").Required().ExistingDir()
+	gcRegionFlag = gcCommand.Flag("region", "AWS region to scan").Required().String()
+	gcDeleteFlag = gcCommand.Flag("delete", "Delete the resources, once confirmed for each cluster").Bool()
+
+	inventoryCommand = kingpin.Command("inventory", "Write an Ansible inventory of the machines of an existing cluster to inventory.ini")
+	inventoryDirFlag = inventoryCommand.Flag("dir", "Cluster directory").Default(".").ExistingDir()
+
+	migrateCommand    = kingpin.Command("migrate", "Upgrade a Tectonic config.yaml written for an older installer to the current schema")
+	migrateConfigFlag = migrateCommand.Flag("config", "config.yaml file").Required().ExistingFile()
+
+	explainCommand  = kingpin.Command("explain", "Document a field of the config file (e.g. \"aws.master\")")
+	explainFieldArg = explainCommand.Arg("field", "Dotted path of the field; the whole config if omitted").String()
+
+	completionCommand  = kingpin.Command("completion", "Print a shell completion script (e.g. \"source <(tectonic completion bash)\")")
+	completionShellArg = completionCommand.Arg("shell", "Shell to complete for").Required().Enum("bash", "fish", "zsh")
+
+	sshKeygenCommand    = kingpin.Command("ssh-keygen", "Generate an SSH key pair and print its public key, e.g. for the libvirt sshKey of the config")
+	sshKeygenOutputFlag = sshKeygenCommand.Flag("output", "Private key file; the public key is written next to it with a .pub suffix").Default("id_rsa").String()
+
+	versionCommand    = kingpin.Command("version", "Print version information")
+	versionOutputFlag = versionCommand.Flag("output", "Output format").Default("text").Enum("text", "json")
 
 	convertCommand    = kingpin.Command("convert", "Convert a tfvars.json to a Tectonic config.yaml")
 	convertConfigFlag = convertCommand.Flag("config", "tfvars.json file").Required().ExistingFile()
 
-	logLevel = kingpin.Flag("log-level", "log level (e.g. \"debug\")").Default("info").Enum("debug", "info", "warn", "error", "fatal", "panic")
+	logLevel     = kingpin.Flag("log-level", "log level (e.g. \"debug\")").Default("info").Enum("debug", "info", "warn", "error", "fatal", "panic")
+	logFormat    = kingpin.Flag("log-format", "log format (e.g. \"json\")").Default("text").Enum("text", "json")
+	progressFile = kingpin.Flag("progress-file", "File to which the current install phase is written, as JSON").String()
+	notifyURL    = kingpin.Flag("notify-url", "URL to which a JSON summary of the run is POSTed once it finishes").Envar("TECTONIC_NOTIFY_URL").String()
+	timingsFile  = kingpin.Flag("timings-file", "File to which the duration of the run, its phases and its steps is written, as JSON, once it finishes").String()
 )
 
 func main() {
 	var w workflow.Workflow
 
-	switch kingpin.Parse() {
+	command := kingpin.Parse()
+	switch command {
 	case clusterInitCommand.FullCommand():
-		w = workflow.InitWorkflow(*clusterInitConfigFlag)
+		w = workflow.InitWorkflow(*clusterInitConfigFlag, *clusterInitEditFlag)
 	case clusterInstallFullCommand.FullCommand():
-		w = workflow.InstallFullWorkflow(*clusterInstallDirFlag)
+		w = workflow.InstallFullWorkflow(*clusterInstallDirFlag, *clusterInstallPlanFlag)
 	case clusterInstallTLSCommand.FullCommand():
 		w = workflow.InstallTLSWorkflow(*clusterInstallDirFlag)
 	case clusterInstallTLSNewCommand.FullCommand():
 		w = workflow.InstallTLSNewWorkflow(*clusterInstallDirFlag)
 	case clusterInstallAssetsCommand.FullCommand():
-		w = workflow.InstallAssetsWorkflow(*clusterInstallDirFlag)
+		w = workflow.InstallAssetsWorkflow(*clusterInstallDirFlag, *clusterInstallBundleFlag)
 	case clusterInstallBootstrapCommand.FullCommand():
 		w = workflow.InstallBootstrapWorkflow(*clusterInstallDirFlag)
 	case clusterInstallJoinCommand.FullCommand():
 		w = workflow.InstallJoinWorkflow(*clusterInstallDirFlag)
+	case clusterRegenerateCertsCommand.FullCommand():
+		w = workflow.RegenerateCertsWorkflow(*clusterRegenerateDirFlag)
 	case clusterDestroyCommand.FullCommand():
-		w = workflow.DestroyWorkflow(*clusterDestroyDirFlag)
+		if *clusterDestroyDryRunFlag {
+			w = workflow.DestroyDryRunWorkflow(*clusterDestroyDirFlag, *clusterDestroyRetainFlag)
+		} else {
+			w = workflow.DestroyWorkflow(*clusterDestroyDirFlag, *clusterDestroyTimeoutFlag, *clusterDestroyParallelismFlag, *clusterDestroyRetainFlag)
+		}
+	case fleetInstallCommand.FullCommand():
+		w = workflow.FleetInstallWorkflow(*fleetConfigsFlag, *fleetParallelFlag)
+	case fleetDestroyCommand.FullCommand():
+		w = workflow.FleetDestroyWorkflow(*fleetConfigsFlag, *fleetParallelFlag, *fleetDestroyTimeoutFlag)
+	case waitForBootstrapCompleteCommand.FullCommand():
+		w = workflow.WaitForBootstrapCompleteWorkflow(*waitForDirFlag, *waitForBootstrapTimeoutFlag)
+	case waitForInstallCompleteCommand.FullCommand():
+		w = workflow.WaitForInstallCompleteWorkflow(*waitForDirFlag, *waitForInstallTimeoutFlag)
+	case gatherBootstrapCommand.FullCommand():
+		w = workflow.GatherBootstrapWorkflow(*gatherDirFlag, *gatherBootstrapAddressFlag, *gatherMasterAddressesFlag)
+	case analyzeCommand.FullCommand():
+		w = workflow.AnalyzeWorkflow(*analyzeBundleArg)
+	case pxeCommand.FullCommand():
+		w = workflow.PXEWorkflow(*pxeDirFlag, *pxeRoleFlag, *pxeIgnitionURLFlag)
+	case dnsRecordsCommand.FullCommand():
+		w = workflow.DNSRecordsWorkflow(*dnsRecordsDirFlag, *dnsRecordsFormatFlag)
+	case gcCommand.FullCommand():
+		w = workflow.GCWorkflow(*gcDirFlag, *gcRegionFlag, *gcDeleteFlag)
+	case inventoryCommand.FullCommand():
+		w = workflow.InventoryWorkflow(*inventoryDirFlag)
 	case convertCommand.FullCommand():
 		w = workflow.ConvertWorkflow(*convertConfigFlag)
+	case migrateCommand.FullCommand():
+		w = workflow.MigrateWorkflow(*migrateConfigFlag)
+	case explainCommand.FullCommand():
+		doc, err := config.Explain(*explainFieldArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(doc)
+		return
+	case completionCommand.FullCommand():
+		if err := printCompletion(*completionShellArg); err != nil {
+			log.Fatalf("failed to generate completion script: %v", err)
+		}
+		return
+	case sshKeygenCommand.FullCommand():
+		pub, err := writeSSHKeyPair(*sshKeygenOutputFlag)
+		if err != nil {
+			log.Fatalf("failed to generate SSH key pair: %v", err)
+		}
+		fmt.Println(pub)
+		return
+	case versionCommand.FullCommand():
+		printVersion(*versionOutputFlag)
+		return
 	}
 
 	l, err := log.ParseLevel(*logLevel)
@@ -61,9 +188,52 @@ func main() {
 		log.Fatalf("invalid log-level: %v", err)
 	}
 	log.SetLevel(l)
+	if *logFormat == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+
+	if *progressFile != "" {
+		w.ReportProgressTo(*progressFile)
+	}
+	if *timingsFile != "" {
+		w.ReportTimingsTo(*timingsFile)
+	}
+	if *notifyURL != "" {
+		w.NotifyTo(*notifyURL, command)
+	}
+	if err := w.ExecuteContext(interruptContext()); err != nil {
+		log.WithFields(log.Fields{"code": workflow.ErrorCode(err), "exitCode": workflow.ExitCode(err)}).Error(err)
+		os.Exit(workflow.ExitCode(err))
+	}
+}
+
+// interruptContext returns a context canceled on the first SIGINT or SIGTERM,
+// letting the workflow stop cleanly; the second one exits right away, killing
+// TerraForm, which runs in its own process group and would otherwise go on.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Warn("Interrupted, stopping once the current step saved its state (interrupt again to exit right away)...")
+		cancel()
+		<-signals
+		workflow.KillTerraform()
+		os.Exit(workflow.ExitCodeInterrupted)
+	}()
+	return ctx
+}
 
-	if err := w.Execute(); err != nil {
-		log.Fatal(err)
-		os.Exit(1)
+func printVersion(output string) {
+	info := version.Get()
+	if output != "json" {
+		fmt.Println(info)
+		return
+	}
+	data, err := info.JSON()
+	if err != nil {
+		log.Fatalf("failed to marshal version information: %v", err)
 	}
+	fmt.Println(data)
 }
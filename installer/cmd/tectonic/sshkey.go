@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/openshift/installer/installer/pkg/tls"
+)
+
+// sshKeySize is the size, in bits, of the generated SSH keys.
+const sshKeySize = 4096
+
+// writeSSHKeyPair generates an RSA key pair, writes its private key to path
+// and its public key to path.pub, and returns the public key.
+// Existing files are never overwritten.
+func writeSSHKeyPair(path string) (string, error) {
+	for _, p := range []string{path, path + ".pub"} {
+		if _, err := os.Stat(p); err == nil {
+			return "", fmt.Errorf("%s already exists", p)
+		}
+	}
+
+	key, err := tls.PrivateKeyWithSize(sshKeySize)
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, []byte(tls.PrivateKeyToPem(key)), 0600); err != nil {
+		return "", err
+	}
+	pub := tls.OpenSSHPublicKey(&key.PublicKey)
+	if err := ioutil.WriteFile(path+".pub", []byte(pub+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return pub, nil
+}
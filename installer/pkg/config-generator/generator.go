@@ -2,11 +2,14 @@ package configgenerator
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"sort"
 	"strings"
 
 	"github.com/apparentlymart/go-cidr/cidr"
@@ -19,6 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openshift/installer/installer/pkg/config"
+	"github.com/openshift/installer/installer/pkg/config/aws"
 )
 
 const (
@@ -32,6 +36,11 @@ const (
 	ingressConfigIngressKind      = "haproxy-router"
 	certificatesStrategy          = "userProvidedCA"
 	identityAPIService            = "tectonic-identity-api.tectonic-system.svc.cluster.local"
+	userCABundleConfigMapName     = "user-ca-bundle"
+	userCABundleConfigMapKey      = "ca-bundle.crt"
+	// cloudProviderConfigPath is where the kubelet and the control plane read
+	// the cloud provider config.
+	cloudProviderConfigPath = "/etc/kubernetes/cloud/config"
 )
 
 // ConfigGenerator defines the cluster config generation for a cluster.
@@ -96,6 +105,27 @@ func (c *ConfigGenerator) TectonicSystem() (string, error) {
 	})
 }
 
+// UserCABundle returns, if successful, a yaml string for the ConfigMap holding the additional trust bundle.
+func (c *ConfigGenerator) UserCABundle() (string, error) {
+	bundle, err := ioutil.ReadFile(c.TLS.AdditionalTrustBundlePath)
+	if err != nil {
+		return "", err
+	}
+	return marshalYAML(configurationObject{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		Metadata: metadata{
+			Name:      userCABundleConfigMapName,
+			Namespace: "kube-system",
+		},
+		Data: data{
+			userCABundleConfigMapKey: string(bundle),
+		},
+	})
+}
+
 // CoreConfig returns, if successful, a yaml string for the on-disk kco-config.
 func (c *ConfigGenerator) CoreConfig() (string, error) {
 	coreConfig, err := c.coreConfig()
@@ -151,6 +181,9 @@ func (c *ConfigGenerator) coreConfig() (*kubecore.OperatorConfig, error) {
 	coreConfig.DNSConfig.ClusterIP = cidrhost
 
 	coreConfig.CloudProviderConfig.CloudConfigPath = ""
+	if c.CloudProviderConfig() != "" {
+		coreConfig.CloudProviderConfig.CloudConfigPath = cloudProviderConfigPath
+	}
 	coreConfig.CloudProviderConfig.CloudProviderProfile = k8sCloudProvider(c.Cluster.Platform)
 
 	coreConfig.RoutingConfig.Subdomain = c.getBaseAddress()
@@ -200,9 +233,9 @@ func (c *ConfigGenerator) tncoConfig() (*tnco.OperatorConfig, error) {
 
 	tncoConfig.ControllerConfig.ClusterDNSIP = cidrhost
 	tncoConfig.ControllerConfig.Platform = tectonicCloudProvider(c.Platform)
-	tncoConfig.ControllerConfig.CloudProviderConfig = "" // TODO(yifan): Get CloudProviderConfig.
+	tncoConfig.ControllerConfig.CloudProviderConfig = c.CloudProviderConfig()
 	tncoConfig.ControllerConfig.ClusterName = c.Cluster.Name
-	tncoConfig.ControllerConfig.BaseDomain = c.Cluster.BaseDomain
+	tncoConfig.ControllerConfig.BaseDomain = c.Cluster.DNSDomain()
 	tncoConfig.ControllerConfig.EtcdInitialCount = c.Cluster.NodeCount(c.Cluster.Etcd.NodePools)
 	tncoConfig.ControllerConfig.AdditionalConfigs = []string{} // TODO(yifan): Get additional configs.
 	tncoConfig.ControllerConfig.NodePoolUpdateLimit = nil      // TODO(yifan): Get the node pool update limit.
@@ -218,7 +251,13 @@ func (c *ConfigGenerator) utilityConfig() (*tectonicutility.OperatorConfig, erro
 		},
 	}
 
-	utilityConfig.StatsEmitterConfig.StatsURL = statsEmitterConfigStatsURL
+	// without a URL, the stats emitter reports nowhere
+	if !c.Cluster.Stats.Disabled {
+		utilityConfig.StatsEmitterConfig.StatsURL = statsEmitterConfigStatsURL
+		if c.Cluster.Stats.URL != "" {
+			utilityConfig.StatsEmitterConfig.StatsURL = c.Cluster.Stats.URL
+		}
+	}
 
 	utilityConfig.TectonicConfigMapConfig.CertificatesStrategy = certificatesStrategy
 	utilityConfig.TectonicConfigMapConfig.ClusterID = c.Cluster.Internal.ClusterID
@@ -273,21 +312,25 @@ func marshalYAML(obj interface{}) (string, error) {
 func (c *ConfigGenerator) getEtcdServersURLs() string {
 	etcdServers := make([]string, c.Cluster.NodeCount(c.Cluster.Etcd.NodePools))
 	for i := range etcdServers {
-		etcdServers[i] = fmt.Sprintf("https://%s-etcd-%v.%s:2379", c.Cluster.Name, i, c.Cluster.BaseDomain)
+		etcdServers[i] = fmt.Sprintf("https://%s-etcd-%v.%s:2379", c.Cluster.Name, i, c.Cluster.DNSDomain())
 	}
 	return strings.Join(etcdServers, ",")
 }
 
 func (c *ConfigGenerator) getAPIServerURL() string {
-	return fmt.Sprintf("https://%s-api.%s:6443", c.Cluster.Name, c.Cluster.BaseDomain)
+	return fmt.Sprintf("https://%s:%d", c.Cluster.APIHost(), c.Cluster.Networking.APIPort)
 }
 
+// getBaseAddress returns the domain applications are exposed under by the ingress controller.
 func (c *ConfigGenerator) getBaseAddress() string {
-	return fmt.Sprintf("%s.%s", c.Cluster.Name, c.Cluster.BaseDomain)
+	if c.Cluster.IngressDomain != "" {
+		return c.Cluster.IngressDomain
+	}
+	return fmt.Sprintf("%s.%s", c.Cluster.Name, c.Cluster.DNSDomain())
 }
 
 func (c *ConfigGenerator) getOicdIssuerURL() string {
-	return fmt.Sprintf("https://%s.%s/identity", c.Cluster.Name, c.Cluster.BaseDomain)
+	return fmt.Sprintf("https://%s.%s/identity", c.Cluster.Name, c.Cluster.DNSDomain())
 }
 
 // generateRandomID reproduce tf random_id behaviour
@@ -317,13 +360,31 @@ func GenerateClusterID(byteLength int) (string, error) {
 		return "", err
 	}
 	bytes, err := base64.RawURLEncoding.DecodeString(randomID)
+	return formatClusterID(bytes), nil
+}
+
+// ClusterIDFromSeed derives a cluster ID, in the format of GenerateClusterID,
+// from the given seed, so the same seed always yields the same ID.
+func ClusterIDFromSeed(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return formatClusterID(sum[:16])
+}
+
+// formatClusterID formats 16 bytes as a UUID-like cluster ID.
+func formatClusterID(bytes []byte) string {
 	hexStr := hex.EncodeToString(bytes)
 	return fmt.Sprintf("%s-%s-%s-%s-%s",
 		hexStr[0:8],
 		hexStr[8:12],
 		hexStr[12:16],
 		hexStr[16:20],
-		hexStr[20:32]), nil
+		hexStr[20:32])
+}
+
+// GeneratePassword returns a random password, of the given number of random
+// bytes encoded in URL-safe base64.
+func GeneratePassword(byteLength int) (string, error) {
+	return generateRandomID(byteLength)
 }
 
 // cidrhost takes an IP address range in CIDR notation
@@ -345,6 +406,50 @@ func cidrhost(iprange string, hostNum int) (string, error) {
 	return ip.String(), nil
 }
 
+// cloudProviderServices are the names the cloud provider of Kubernetes knows
+// the services of aws.serviceEndpoints under, when they differ.
+var cloudProviderServices = map[string]string{
+	aws.ServiceELB: "elasticloadbalancing",
+}
+
+// CloudProviderConfig returns the config of the cloud provider of Kubernetes,
+// in the format of its --cloud-config flag, or an empty string when the
+// provider runs with its defaults.
+func (c *ConfigGenerator) CloudProviderConfig() string {
+	cc := c.AWS.CloudConfig
+	if c.Platform != config.PlatformAWS || (cc == (aws.CloudConfig{}) && len(c.AWS.ServiceEndpoints) == 0) {
+		return ""
+	}
+	// the resources of the cluster are tagged with kubernetes.io/cluster/<name>
+	cfg := fmt.Sprintf("[Global]\nKubernetesClusterID = %s\n", c.Name)
+	if cc.DisableSecurityGroupIngress {
+		cfg += "DisableSecurityGroupIngress = true\n"
+	}
+	if cc.DisableStrictZoneCheck {
+		cfg += "DisableStrictZoneCheck = true\n"
+	}
+	if cc.ELBSecurityGroup != "" {
+		cfg += fmt.Sprintf("ElbSecurityGroup = %s\n", cc.ELBSecurityGroup)
+	}
+	if cc.RoleARN != "" {
+		cfg += fmt.Sprintf("RoleARN = %s\n", cc.RoleARN)
+	}
+	// the provider calls the same endpoints as terraform
+	services := make([]string, 0, len(c.AWS.ServiceEndpoints))
+	for service := range c.AWS.ServiceEndpoints {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for i, service := range services {
+		name := service
+		if n, ok := cloudProviderServices[service]; ok {
+			name = n
+		}
+		cfg += fmt.Sprintf("\n[ServiceOverride \"%d\"]\nService = %s\nRegion = %s\nURL = %s\nSigningRegion = %s\n", i+1, name, c.AWS.Region, c.AWS.ServiceEndpoints[service], c.AWS.Region)
+	}
+	return cfg
+}
+
 // Converts a platform to the cloudProvider that k8s understands
 func k8sCloudProvider(platform config.Platform) string {
 	switch platform {
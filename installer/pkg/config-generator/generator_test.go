@@ -5,9 +5,17 @@ import (
 	"crypto/x509/pkix"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	ignconfigtypes "github.com/coreos/ignition/config/v2_2/types"
 	"github.com/openshift/installer/installer/pkg/config"
+	"github.com/openshift/installer/installer/pkg/config/aws"
 	"github.com/openshift/installer/installer/pkg/tls"
 )
 
@@ -52,6 +60,28 @@ func TestUrlFunctions(t *testing.T) {
 	}
 }
 
+func TestClusterIDFromSeed(t *testing.T) {
+	id := ClusterIDFromSeed("prod-eu")
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("Test case format: expected a UUID-like ID, got: %s", id)
+	}
+	if again := ClusterIDFromSeed("prod-eu"); again != id {
+		t.Errorf("Test case same seed: expected: %s, got: %s", id, again)
+	}
+	if other := ClusterIDFromSeed("prod-us"); other == id {
+		t.Errorf("Test case other seed: expected an ID other than %s", id)
+	}
+}
+
+func TestGetBaseAddressIngressDomain(t *testing.T) {
+	config := initConfig(t, "test.yaml")
+	config.IngressDomain = "apps.example.com"
+
+	if got, expected := config.getBaseAddress(), "apps.example.com"; got != expected {
+		t.Errorf("Test case getBaseAddress: expected: %s, got: %s", expected, got)
+	}
+}
+
 func TestGetEtcdServersURLs(t *testing.T) {
 	testCases := []struct {
 		test       string
@@ -131,7 +161,7 @@ func TestGenerateCert(t *testing.T) {
 			CommonName:         "test-self-signed-ca",
 			OrganizationalUnit: []string{"openshift"},
 		},
-		Validity: validityThreeYears,
+		Validity: config.DefaultTLSValidity,
 	}
 	caCert, err := tls.SelfSignedCACert(caCfg, caKey)
 	if err != nil {
@@ -151,7 +181,7 @@ func TestGenerateCert(t *testing.T) {
 				KeyUsages:    x509.KeyUsageKeyEncipherment,
 				DNSNames:     []string{"test-api.kubernetes.default"},
 				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-				Validity:     validityThreeYears,
+				Validity:     config.DefaultTLSValidity,
 				IsCA:         false,
 			},
 			clusterDir: "./",
@@ -159,7 +189,7 @@ func TestGenerateCert(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		_, _, err := generateCert(c.clusterDir, caKey, caCert, keyPath, certPath, c.cfg)
+		_, _, err := generateCert(c.clusterDir, tls.DefaultKeySize, caKey, caCert, keyPath, certPath, c.cfg)
 		if err != nil {
 			no := "no"
 			if c.err {
@@ -176,3 +206,510 @@ func TestGenerateCert(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCertificates(t *testing.T) {
+	key, err := tls.PrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate Private Key: %v", err)
+	}
+	cfg := &tls.CertCfg{
+		Subject: pkix.Name{
+			CommonName:         "test-self-signed-ca",
+			OrganizationalUnit: []string{"openshift"},
+		},
+		Validity: config.DefaultTLSValidity,
+		IsCA:     true,
+	}
+	cert, err := tls.SelfSignedCACert(cfg, key)
+	if err != nil {
+		t.Fatalf("failed to generate self signed certificate: %v", err)
+	}
+
+	cases := []struct {
+		data  string
+		certs int
+		err   bool
+	}{
+		{
+			data:  tls.CertToPem(cert),
+			certs: 1,
+		},
+		{
+			data:  tls.CertToPem(cert) + tls.CertToPem(cert),
+			certs: 2,
+		},
+		{
+			data:  tls.PrivateKeyToPem(key) + tls.CertToPem(cert),
+			certs: 1,
+		},
+		{
+			data: tls.PrivateKeyToPem(key),
+			err:  true,
+		},
+		{
+			data: "",
+			err:  true,
+		},
+	}
+	for i, c := range cases {
+		certs, err := parseCertificates([]byte(c.data))
+		if (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+		if len(certs) != c.certs {
+			t.Errorf("test case %d: expected %d certificates, got %d", i, c.certs, len(certs))
+		}
+	}
+}
+
+func TestUserCABundle(t *testing.T) {
+	bundle := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	bundlePath := "./test-bundle.crt"
+	if err := ioutil.WriteFile(bundlePath, []byte(bundle), 0644); err != nil {
+		t.Fatalf("Test case TestUserCABundle: failed to write bundle: %s", err)
+	}
+	defer os.Remove(bundlePath)
+
+	config := initConfig(t, "test.yaml")
+	config.TLS.AdditionalTrustBundlePath = bundlePath
+	got, err := config.UserCABundle()
+	if err != nil {
+		t.Errorf("Test case TestUserCABundle: failed to get UserCABundle(): %s", err)
+	}
+	expected := `apiVersion: v1
+data:
+  ca-bundle.crt: |
+    -----BEGIN CERTIFICATE-----
+    MIIB
+    -----END CERTIFICATE-----
+kind: ConfigMap
+metadata:
+  name: user-ca-bundle
+  namespace: kube-system
+`
+	if got != expected {
+		t.Errorf("Test case TestUserCABundle: expected: %s, got: %s", expected, got)
+	}
+}
+
+func TestBootstrapOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap_overrides")
+	if err != nil {
+		t.Fatalf("failed to create overrides dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if cfg, err := bootstrapOverrides(dir); err != nil || len(cfg.Storage.Files) != 0 || len(cfg.Systemd.Units) != 0 {
+		t.Errorf("expected an empty config without overrides, got: %+v, %v", cfg, err)
+	}
+
+	files := map[string]os.FileMode{
+		"files/etc/motd":                      0644,
+		"files/opt/debug.sh":                  0755,
+		"units/debug.service":                 0644,
+		"units/kubelet.service.d/10-log.conf": 0644,
+	}
+	for name, mode := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), mode); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := bootstrapOverrides(dir)
+	if err != nil {
+		t.Fatalf("failed to read overrides: %v", err)
+	}
+	if len(cfg.Storage.Files) != 2 {
+		t.Fatalf("expected 2 files, got: %+v", cfg.Storage.Files)
+	}
+	for i, expected := range []struct {
+		path string
+		mode int
+	}{{"/etc/motd", 0644}, {"/opt/debug.sh", 0755}} {
+		if f := cfg.Storage.Files[i]; f.Path != expected.path || *f.Mode != expected.mode {
+			t.Errorf("expected file %s with mode %o, got: %s with mode %o", expected.path, expected.mode, f.Path, *f.Mode)
+		}
+	}
+	if len(cfg.Systemd.Units) != 2 {
+		t.Fatalf("expected 2 units, got: %+v", cfg.Systemd.Units)
+	}
+	if u := cfg.Systemd.Units[0]; u.Name != "debug.service" || !u.Enable || u.Contents != "units/debug.service" {
+		t.Errorf("unexpected unit: %+v", u)
+	}
+	if u := cfg.Systemd.Units[1]; u.Name != "kubelet.service" || u.Enable || len(u.Dropins) != 1 || u.Dropins[0].Name != "10-log.conf" {
+		t.Errorf("unexpected drop-in unit: %+v", u)
+	}
+}
+
+func TestAppendIgnOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition_overrides")
+	if err != nil {
+		t.Fatalf("failed to create overrides dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"files/etc/motd": "motd",
+		"10-sysctl.ign":  `{"ignition": {"version": "2.2.0"}, "storage": {"files": [{"filesystem": "root", "path": "/etc/sysctl.d/10-test.conf", "contents": {"source": "data:,vm.swappiness%3D0"}, "mode": 420}]}}`,
+		"20-extra.ign":   `{"ignition": {"version": "2.2.0", "config": {"append": [{"source": "https://example.com/extra.ign"}]}}, "systemd": {"units": [{"name": "extra.service", "enabled": true, "contents": "[Service]\n"}]}}`,
+		"README":         "not a fragment",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := parseIgnFile("")
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	cfg.Ignition.Config.Append = []ignconfigtypes.ConfigReference{{Source: "https://test-tnc.example.com/config/worker"}}
+	if err := appendIgnOverrides(cfg, dir); err != nil {
+		t.Fatalf("failed to append overrides: %v", err)
+	}
+
+	var paths []string
+	for _, f := range cfg.Storage.Files {
+		paths = append(paths, f.Path)
+	}
+	if expected := []string{"/etc/motd", "/etc/sysctl.d/10-test.conf"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected files: %v, got: %v", expected, paths)
+	}
+	if len(cfg.Systemd.Units) != 1 || cfg.Systemd.Units[0].Name != "extra.service" {
+		t.Errorf("expected the extra.service unit, got: %+v", cfg.Systemd.Units)
+	}
+	var sources []string
+	for _, r := range cfg.Ignition.Config.Append {
+		sources = append(sources, r.Source)
+	}
+	if expected := []string{"https://test-tnc.example.com/config/worker", "https://example.com/extra.ign"}; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("expected appended configs: %v, got: %v", expected, sources)
+	}
+	if cfg.Ignition.Version != ignVersion {
+		t.Errorf("expected version %s, got: %s", ignVersion, cfg.Ignition.Version)
+	}
+
+	if err := appendIgnOverrides(cfg, filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("expected no error without overrides, got: %v", err)
+	}
+}
+
+func TestGenerateTLSConfig(t *testing.T) {
+	clusterDir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to create cluster dir: %s", err)
+	}
+	defer os.RemoveAll(clusterDir)
+	if err := os.MkdirAll(filepath.Join(clusterDir, filepath.Dir(rootCACertPath)), 0755); err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to create TLS dir: %s", err)
+	}
+
+	config := initConfig(t, "test.yaml")
+	if err := config.GenerateTLSConfig(clusterDir); err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to generate TLS assets: %s", err)
+	}
+	for _, path := range []string{rootCACertPath, kubeCACertPath, etcdClientCertPath, ingressCACertPath, apiServerCertPath, tncCertPath} {
+		if _, err := os.Stat(filepath.Join(clusterDir, path)); err != nil {
+			t.Errorf("Test case TestGenerateTLSConfig: expected %s, got: %s", path, err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(clusterDir, kubeletCertPath))
+	if err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to read kubelet certificate: %s", err)
+	}
+	certs, err := parseCertificates(data)
+	if err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to parse kubelet certificate: %s", err)
+	}
+	if expected := "kube-ca"; certs[0].Issuer.CommonName != expected {
+		t.Errorf("Test case TestGenerateTLSConfig: expected kubelet certificate issuer: %s, got: %s", expected, certs[0].Issuer.CommonName)
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(clusterDir, etcdMetricCertPath))
+	if err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to read etcd metrics certificate: %s", err)
+	}
+	certs, err = parseCertificates(data)
+	if err != nil {
+		t.Fatalf("Test case TestGenerateTLSConfig: failed to parse etcd metrics certificate: %s", err)
+	}
+	// only the etcd CA is trusted by etcd
+	if expected := "etcd"; certs[0].Issuer.CommonName != expected {
+		t.Errorf("Test case TestGenerateTLSConfig: expected etcd metrics certificate issuer: %s, got: %s", expected, certs[0].Issuer.CommonName)
+	}
+}
+
+func TestGenerateSignedCAs(t *testing.T) {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl is not available")
+	}
+	clusterDir, err := ioutil.TempDir("", "signed")
+	if err != nil {
+		t.Fatalf("Test case TestGenerateSignedCAs: failed to create cluster dir: %s", err)
+	}
+	defer os.RemoveAll(clusterDir)
+
+	key, err := tls.PrivateKey()
+	if err != nil {
+		t.Fatalf("Test case TestGenerateSignedCAs: failed to generate key: %s", err)
+	}
+	caCert, err := tls.SelfSignedCACert(&tls.CertCfg{
+		Subject:   pkix.Name{CommonName: "corporate-ca", OrganizationalUnit: []string{"pki"}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  time.Hour * 24,
+		IsCA:      true,
+	}, key)
+	if err != nil {
+		t.Fatalf("Test case TestGenerateSignedCAs: failed to generate CA: %s", err)
+	}
+	// the key is kept out of the cluster directory, as a KMS or an HSM would
+	keyDir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatalf("Test case TestGenerateSignedCAs: failed to create key dir: %s", err)
+	}
+	defer os.RemoveAll(keyDir)
+	keyPath := filepath.Join(keyDir, "ca.key")
+	certPath := filepath.Join(keyDir, "ca.crt")
+	for path, content := range map[string]string{keyPath: tls.PrivateKeyToPem(key), certPath: tls.CertToPem(caCert)} {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Test case TestGenerateSignedCAs: failed to write %s: %s", path, err)
+		}
+	}
+
+	config := initConfig(t, "test.yaml")
+	config.CA.RootCACertPath = certPath
+	// the signer command of the tls package tests signs with the key at TEST_SIGNER_KEY
+	config.CA.RootCASignerCommand = "../tls/fixtures/signer.sh"
+	defer os.Unsetenv("TEST_SIGNER_KEY")
+	if err := os.Setenv("TEST_SIGNER_KEY", filepath.Join(keyDir, "missing.key")); err != nil {
+		t.Fatalf("Test case TestGenerateSignedCAs: failed to set the signer key: %s", err)
+	}
+	if err := config.GenerateSignedCAs(clusterDir); err == nil {
+		t.Fatal("Test case TestGenerateSignedCAs: expected signing with a missing key to fail, got: <nil>")
+	}
+	// a failed run leaves no signed CAs behind, so that the next one signs them all
+	if _, err := os.Stat(filepath.Join(clusterDir, "generated/tls-signed")); !os.IsNotExist(err) {
+		t.Fatalf("Test case TestGenerateSignedCAs: expected no signed CAs after a failed run, got: %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(clusterDir, "generated/tls-signed*")); len(matches) != 0 {
+		t.Fatalf("Test case TestGenerateSignedCAs: expected the temporary signed CAs to be removed, got: %v", matches)
+	}
+
+	os.Setenv("TEST_SIGNER_KEY", keyPath)
+	if err := config.GenerateSignedCAs(clusterDir); err != nil {
+		t.Fatalf("Test case TestGenerateSignedCAs: failed to sign CAs: %s", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	for _, name := range []string{"kube-ca", "aggregator-ca", "service-serving-ca", "etcd-ca", "tnc"} {
+		data, err := ioutil.ReadFile(filepath.Join(clusterDir, "generated/tls-signed", name+".crt"))
+		if err != nil {
+			t.Fatalf("Test case TestGenerateSignedCAs: failed to read %s certificate: %s", name, err)
+		}
+		certs, err := parseCertificates(data)
+		if err != nil {
+			t.Fatalf("Test case TestGenerateSignedCAs: failed to parse %s certificate: %s", name, err)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+			t.Errorf("Test case TestGenerateSignedCAs: expected %s certificate signed by the root CA, got: %s", name, err)
+		}
+	}
+}
+
+func TestIgnCfgToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ign")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := parseIgnFile("")
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	cfg.Systemd.Units = []ignconfigtypes.Unit{{Name: "debug.service", Enable: true, Contents: "[Service]\n"}}
+
+	path := filepath.Join(dir, "test.ign")
+	if err := ignCfgToFile(*cfg, path); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err := parseIgnFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config back: %v", err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("expected: %+v, got: %+v", cfg, got)
+	}
+}
+
+func TestValidateIgnConfig(t *testing.T) {
+	mode := 0644
+	file := func(path, source string) ignconfigtypes.File {
+		return ignconfigtypes.File{
+			Node:          ignconfigtypes.Node{Filesystem: "root", Path: path},
+			FileEmbedded1: ignconfigtypes.FileEmbedded1{Contents: ignconfigtypes.FileContents{Source: source}, Mode: &mode},
+		}
+	}
+	testCases := []struct {
+		test          string
+		files         []ignconfigtypes.File
+		units         []ignconfigtypes.Unit
+		expectedError string
+	}{
+		{
+			test:  "Valid",
+			files: []ignconfigtypes.File{file("/etc/a", "data:,a"), file("/etc/b", "data:;base64,Yg==")},
+			units: []ignconfigtypes.Unit{{Name: "a.service", Contents: "[Service]\n"}},
+		},
+		{
+			test:          "Bad base64",
+			files:         []ignconfigtypes.File{file("/etc/a", "data:;base64,not base64!")},
+			expectedError: "file /etc/a: ",
+		},
+		{
+			test:          "Relative path",
+			files:         []ignconfigtypes.File{file("etc/a", "data:,a")},
+			expectedError: "file etc/a: ",
+		},
+		{
+			test:          "Duplicate path",
+			files:         []ignconfigtypes.File{file("/etc/a", "data:,a"), file("/etc/a", "data:,b")},
+			expectedError: "file /etc/a: path declared more than once",
+		},
+		{
+			test:          "Duplicate unit",
+			units:         []ignconfigtypes.Unit{{Name: "a.service", Contents: "[Service]\n"}, {Name: "a.service", Contents: "[Unit]\n"}},
+			expectedError: "unit a.service: declared more than once",
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg, err := parseIgnFile("")
+		if err != nil {
+			t.Fatalf("failed to create config: %v", err)
+		}
+		cfg.Storage.Files = tc.files
+		cfg.Systemd.Units = tc.units
+		err = validateIgnConfig(*cfg)
+		if (tc.expectedError == "" && err != nil) || (tc.expectedError != "" && (err == nil || !strings.HasPrefix(err.Error(), tc.expectedError))) {
+			t.Errorf("Test case %s: expected error: %q, got: %v", tc.test, tc.expectedError, err)
+		}
+	}
+}
+
+func TestHostNetworkUnits(t *testing.T) {
+	testCases := []struct {
+		test     string
+		network  config.HostNetwork
+		expected map[string]string
+	}{
+		{
+			test:     "None",
+			expected: map[string]string{},
+		},
+		{
+			test: "Bond",
+			network: config.HostNetwork{
+				Bonds: []config.Bond{{Name: "bond0", Interfaces: []string{"eno1", "eno2"}, Mode: "active-backup"}},
+			},
+			expected: map[string]string{
+				"10-bond0.netdev":             "[NetDev]\nName=bond0\nKind=bond\n\n[Bond]\nMode=active-backup\nMIIMonitorSec=0.1\n",
+				"10-bond0-interfaces.network": "[Match]\nName=eno1 eno2\n\n[Network]\nBond=bond0\n",
+				"20-bond0.network":            "[Match]\nName=bond0\n\n[Network]\nDHCP=yes\n",
+			},
+		},
+		{
+			test: "VLANs on a bond and an interface",
+			network: config.HostNetwork{
+				Bonds: []config.Bond{{Name: "bond0", Interfaces: []string{"eno*"}}},
+				VLANs: []config.VLAN{{ID: 100, Link: "bond0"}, {ID: 200, Link: "bond0"}, {ID: 300, Link: "ens1"}},
+			},
+			expected: map[string]string{
+				"10-bond0.netdev":             "[NetDev]\nName=bond0\nKind=bond\n\n[Bond]\nMode=802.3ad\nMIIMonitorSec=0.1\nLACPTransmitRate=fast\nTransmitHashPolicy=layer3+4\n",
+				"10-bond0-interfaces.network": "[Match]\nName=eno*\n\n[Network]\nBond=bond0\n",
+				"20-bond0.network":            "[Match]\nName=bond0\n\n[Network]\nVLAN=bond0.100\nVLAN=bond0.200\nDHCP=no\n",
+				"20-ens1.network":             "[Match]\nName=ens1\n\n[Network]\nVLAN=ens1.300\nDHCP=no\n",
+				"30-bond0.100.netdev":         "[NetDev]\nName=bond0.100\nKind=vlan\n\n[VLAN]\nId=100\n",
+				"30-bond0.100.network":        "[Match]\nName=bond0.100\n\n[Network]\nDHCP=yes\n",
+				"30-bond0.200.netdev":         "[NetDev]\nName=bond0.200\nKind=vlan\n\n[VLAN]\nId=200\n",
+				"30-bond0.200.network":        "[Match]\nName=bond0.200\n\n[Network]\nDHCP=yes\n",
+				"30-ens1.300.netdev":          "[NetDev]\nName=ens1.300\nKind=vlan\n\n[VLAN]\nId=300\n",
+				"30-ens1.300.network":         "[Match]\nName=ens1.300\n\n[Network]\nDHCP=yes\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		got := map[string]string{}
+		for _, u := range hostNetworkUnits(tc.network) {
+			got[u.Name] = u.Contents
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestCloudProviderConfig(t *testing.T) {
+	testCases := []struct {
+		test      string
+		platform  config.Platform
+		cfg       aws.CloudConfig
+		endpoints map[string]string
+		expected  string
+	}{
+		{
+			test:     "Defaults",
+			platform: config.PlatformAWS,
+			expected: "",
+		},
+		{
+			test:     "Libvirt",
+			platform: config.PlatformLibvirt,
+			cfg:      aws.CloudConfig{DisableStrictZoneCheck: true},
+			expected: "",
+		},
+		{
+			test:     "Settings",
+			platform: config.PlatformAWS,
+			cfg:      aws.CloudConfig{DisableSecurityGroupIngress: true, ELBSecurityGroup: "sg-0123abcd", RoleARN: "arn:aws:iam::123456789012:role/k8s"},
+			expected: "[Global]\nKubernetesClusterID = test\nDisableSecurityGroupIngress = true\nElbSecurityGroup = sg-0123abcd\nRoleARN = arn:aws:iam::123456789012:role/k8s\n",
+		},
+		{
+			test:     "Service endpoints",
+			platform: config.PlatformAWS,
+			endpoints: map[string]string{
+				aws.ServiceEC2: "https://ec2.example.com",
+				aws.ServiceELB: "https://elb.example.com",
+			},
+			expected: "[Global]\nKubernetesClusterID = test\n" +
+				"\n[ServiceOverride \"1\"]\nService = ec2\nRegion = us-gov-west-1\nURL = https://ec2.example.com\nSigningRegion = us-gov-west-1\n" +
+				"\n[ServiceOverride \"2\"]\nService = elasticloadbalancing\nRegion = us-gov-west-1\nURL = https://elb.example.com\nSigningRegion = us-gov-west-1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		c := ConfigGenerator{config.Cluster{Name: "test", Platform: tc.platform}}
+		c.AWS.CloudConfig = tc.cfg
+		c.AWS.Region = "us-gov-west-1"
+		c.AWS.ServiceEndpoints = tc.endpoints
+		if got := c.CloudProviderConfig(); got != tc.expected {
+			t.Errorf("Test case %s: expected: %q, got: %q", tc.test, tc.expected, got)
+		}
+	}
+}
@@ -1,14 +1,21 @@
 package configgenerator
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	ignconfig "github.com/coreos/ignition/config/v2_2"
 	ignconfigtypes "github.com/coreos/ignition/config/v2_2/types"
+	ignvalidate "github.com/coreos/ignition/config/validate"
+	"github.com/coreos/ignition/config/validate/report"
 	"github.com/openshift/installer/installer/pkg/config"
 	"github.com/vincent-petithory/dataurl"
 )
@@ -21,6 +28,19 @@ var (
 		"etcd":   config.IgnitionEtcd,
 	}
 	caPath = "generated/tls/root-ca.crt"
+	// bootstrapOverridesPath is the directory of the cluster holding the
+	// files, under files/, and systemd units and drop-ins, under units/,
+	// added to the bootstrap ignition config.
+	bootstrapOverridesPath = "bootstrap-overrides"
+	// ignitionOverridesPath is the directory of the cluster holding, in a
+	// directory per role, e.g. worker/, the ignition fragments (*.ign) merged
+	// into the ignition config of the role, and its files and systemd units,
+	// laid out like the bootstrap overrides.
+	ignitionOverridesPath = "ignition-overrides"
+	// timesyncdConfigPath is the configuration of the clock synchronization of Container Linux.
+	timesyncdConfigPath = "/etc/systemd/timesyncd.conf"
+	// trustBundlePath is where Container Linux picks up additional trusted CA certificates.
+	trustBundlePath = "/etc/ssl/certs/additional-trust-bundle.pem"
 )
 
 func (c *ConfigGenerator) poolToRoleMap() map[string]string {
@@ -57,9 +77,25 @@ func (c *ConfigGenerator) GenerateIgnConfig(clusterDir string) error {
 			return err
 		}
 
+		if err = c.appendIgnitionCABundle(ignCfg); err != nil {
+			return err
+		}
+
+		if err = c.embedTrustBundle(ignCfg); err != nil {
+			return err
+		}
+
+		c.embedNTPConfig(ignCfg)
+
+		c.embedHostNetwork(ignCfg, role)
+
 		// agentless platforms (e.g. libvirt) need to embed the ssh key
 		c.embedUserBlock(ignCfg)
 
+		if err = appendIgnOverrides(ignCfg, filepath.Join(clusterDir, ignitionOverridesPath, role)); err != nil {
+			return fmt.Errorf("failed to apply the %s ignition overrides: %v", role, err)
+		}
+
 		fileTargetPath := filepath.Join(clusterDir, ignFilesPath[role])
 		if err = ignCfgToFile(*ignCfg, fileTargetPath); err != nil {
 			return err
@@ -68,6 +104,145 @@ func (c *ConfigGenerator) GenerateIgnConfig(clusterDir string) error {
 	return nil
 }
 
+// GenerateBootstrapOverrides generates, if successful, the ign config appended to
+// the bootstrap one, from the content of the bootstrap-overrides directory of the cluster.
+// The config is empty when there is no such directory.
+func (c *ConfigGenerator) GenerateBootstrapOverrides(clusterDir string) error {
+	ignCfg, err := bootstrapOverrides(filepath.Join(clusterDir, bootstrapOverridesPath))
+	if err != nil {
+		return fmt.Errorf("failed to GenerateBootstrapOverrides: %v", err)
+	}
+	return ignCfgToFile(*ignCfg, filepath.Join(clusterDir, config.IgnitionBootstrapOverrides))
+}
+
+func bootstrapOverrides(dir string) (*ignconfigtypes.Config, error) {
+	ignCfg := &ignconfigtypes.Config{Ignition: ignconfigtypes.Ignition{Version: ignVersion}}
+
+	filesDir := filepath.Join(dir, "files")
+	err := filepath.Walk(filesDir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == filesDir {
+			return filepath.SkipDir
+		}
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(filesDir, path)
+		if err != nil {
+			return err
+		}
+		mode := 0644
+		if info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		ignCfg.Storage.Files = append(ignCfg.Storage.Files, ignconfigtypes.File{
+			Node: ignconfigtypes.Node{
+				Filesystem: "root",
+				Path:       "/" + filepath.ToSlash(rel),
+			},
+			FileEmbedded1: ignconfigtypes.FileEmbedded1{
+				Contents: ignconfigtypes.FileContents{
+					Source: dataurl.EncodeBytes(data),
+				},
+				Mode: &mode,
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unitsDir := filepath.Join(dir, "units")
+	entries, err := ioutil.ReadDir(unitsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			data, err := ioutil.ReadFile(filepath.Join(unitsDir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			unit := bootstrapUnit(ignCfg, entry.Name())
+			unit.Contents = string(data)
+			unit.Enable = true
+			continue
+		}
+		// drop-ins of a unit, e.g. units/kubelet.service.d/10-debug.conf
+		name := strings.TrimSuffix(entry.Name(), ".d")
+		dropins, err := ioutil.ReadDir(filepath.Join(unitsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, dropin := range dropins {
+			data, err := ioutil.ReadFile(filepath.Join(unitsDir, entry.Name(), dropin.Name()))
+			if err != nil {
+				return nil, err
+			}
+			unit := bootstrapUnit(ignCfg, name)
+			unit.Dropins = append(unit.Dropins, ignconfigtypes.SystemdDropin{
+				Name:     dropin.Name(),
+				Contents: string(data),
+			})
+		}
+	}
+	return ignCfg, nil
+}
+
+// appendIgnOverrides merges into the ign config the files and units of the
+// overrides directory, and then its ignition fragments, in the order of their
+// names. Nothing is merged when there is no such directory.
+func appendIgnOverrides(ignCfg *ignconfigtypes.Config, dir string) error {
+	overrides, err := bootstrapOverrides(dir)
+	if err != nil {
+		return err
+	}
+	fragments := []*ignconfigtypes.Config{overrides}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".ign" {
+			continue
+		}
+		fragment, err := parseIgnFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if fragment.Ignition.Config.Replace != nil {
+			return fmt.Errorf("%s: fragments cannot replace the ignition config", entry.Name())
+		}
+		fragments = append(fragments, fragment)
+	}
+
+	for _, fragment := range fragments {
+		// Append takes the config references of the new config; keep those of
+		// the node, e.g. to the TNC, and add those of the fragment
+		references := ignCfg.Ignition.Config
+		references.Append = append(references.Append, fragment.Ignition.Config.Append...)
+		*ignCfg = ignconfig.Append(*ignCfg, *fragment)
+		ignCfg.Ignition.Config = references
+	}
+	return nil
+}
+
+// bootstrapUnit returns the unit of the ign config with the given name, adding it if needed.
+func bootstrapUnit(ignCfg *ignconfigtypes.Config, name string) *ignconfigtypes.Unit {
+	for i := range ignCfg.Systemd.Units {
+		if ignCfg.Systemd.Units[i].Name == name {
+			return &ignCfg.Systemd.Units[i]
+		}
+	}
+	ignCfg.Systemd.Units = append(ignCfg.Systemd.Units, ignconfigtypes.Unit{Name: name})
+	return &ignCfg.Systemd.Units[len(ignCfg.Systemd.Units)-1]
+}
+
 func parseIgnFile(filePath string) (*ignconfigtypes.Config, error) {
 	if filePath == "" {
 		ignition := &ignconfigtypes.Ignition{
@@ -110,6 +285,135 @@ func (c *ConfigGenerator) appendCertificateAuthority(ignCfg *ignconfigtypes.Conf
 	return nil
 }
 
+// appendIgnitionCABundle makes ignition trust the user supplied CA certificates, if any,
+// e.g. of a TLS-intercepting proxy, when fetching the remainder of the config.
+func (c *ConfigGenerator) appendIgnitionCABundle(ignCfg *ignconfigtypes.Config) error {
+	if c.TLS.IgnitionCABundlePath == "" {
+		return nil
+	}
+	return c.appendCertificateAuthority(ignCfg, c.TLS.IgnitionCABundlePath)
+}
+
+// embedTrustBundle installs the user supplied additional trust bundle, if any, into the node trust store.
+func (c *ConfigGenerator) embedTrustBundle(ignCfg *ignconfigtypes.Config) error {
+	if c.TLS.AdditionalTrustBundlePath == "" {
+		return nil
+	}
+	bundle, err := ioutil.ReadFile(c.TLS.AdditionalTrustBundlePath)
+	if err != nil {
+		return err
+	}
+
+	mode := 0644
+	ignCfg.Storage.Files = append(ignCfg.Storage.Files, ignconfigtypes.File{
+		Node: ignconfigtypes.Node{
+			Filesystem: "root",
+			Path:       trustBundlePath,
+		},
+		FileEmbedded1: ignconfigtypes.FileEmbedded1{
+			Contents: ignconfigtypes.FileContents{
+				Source: dataurl.EncodeBytes(bundle),
+			},
+			Mode: &mode,
+		},
+	})
+
+	return nil
+}
+
+// embedNTPConfig points the clock synchronization to the user supplied NTP servers, if any.
+func (c *ConfigGenerator) embedNTPConfig(ignCfg *ignconfigtypes.Config) {
+	if len(c.Networking.NTPServers) == 0 {
+		return
+	}
+	mode := 0644
+	ignCfg.Storage.Files = append(ignCfg.Storage.Files, ignconfigtypes.File{
+		Node: ignconfigtypes.Node{
+			Filesystem: "root",
+			Path:       timesyncdConfigPath,
+		},
+		FileEmbedded1: ignconfigtypes.FileEmbedded1{
+			Contents: ignconfigtypes.FileContents{
+				Source: dataurl.EncodeBytes([]byte(fmt.Sprintf("[Time]\nNTP=%s\n", strings.Join(c.Networking.NTPServers, " ")))),
+			},
+			Mode: &mode,
+		},
+	})
+}
+
+// embedHostNetwork configures the bonds and VLANs of the given role, if any,
+// with systemd-networkd.
+func (c *ConfigGenerator) embedHostNetwork(ignCfg *ignconfigtypes.Config, role string) {
+	n := c.Worker.HostNetwork
+	if role == "master" {
+		n = c.Master.HostNetwork
+	}
+	ignCfg.Networkd.Units = append(ignCfg.Networkd.Units, hostNetworkUnits(n)...)
+}
+
+// hostNetworkUnits returns the systemd-networkd units creating the bonds and
+// VLANs. Their .network units sort before the default one of Container Linux,
+// which configures every other interface with DHCP.
+func hostNetworkUnits(n config.HostNetwork) []ignconfigtypes.Networkdunit {
+	vlans := map[string][]string{}
+	var links []string
+	for _, v := range n.VLANs {
+		if _, ok := vlans[v.Link]; !ok {
+			links = append(links, v.Link)
+		}
+		vlans[v.Link] = append(vlans[v.Link], v.Name())
+	}
+	// linkNetwork configures the addressing of the link name, unless VLANs are on it
+	linkNetwork := func(name string) string {
+		network := fmt.Sprintf("[Match]\nName=%s\n\n[Network]\n", name)
+		if len(vlans[name]) == 0 {
+			return network + "DHCP=yes\n"
+		}
+		for _, v := range vlans[name] {
+			network += fmt.Sprintf("VLAN=%s\n", v)
+		}
+		return network + "DHCP=no\n"
+	}
+
+	var units []ignconfigtypes.Networkdunit
+	bonds := map[string]bool{}
+	for _, b := range n.Bonds {
+		bonds[b.Name] = true
+		mode := b.Mode
+		if mode == "" {
+			mode = "802.3ad"
+		}
+		netdev := fmt.Sprintf("[NetDev]\nName=%s\nKind=bond\n\n[Bond]\nMode=%s\nMIIMonitorSec=0.1\n", b.Name, mode)
+		if mode == "802.3ad" {
+			netdev += "LACPTransmitRate=fast\nTransmitHashPolicy=layer3+4\n"
+		}
+		units = append(units,
+			ignconfigtypes.Networkdunit{Name: fmt.Sprintf("10-%s.netdev", b.Name), Contents: netdev},
+			ignconfigtypes.Networkdunit{
+				Name:     fmt.Sprintf("10-%s-interfaces.network", b.Name),
+				Contents: fmt.Sprintf("[Match]\nName=%s\n\n[Network]\nBond=%s\n", strings.Join(b.Interfaces, " "), b.Name),
+			},
+			ignconfigtypes.Networkdunit{Name: fmt.Sprintf("20-%s.network", b.Name), Contents: linkNetwork(b.Name)},
+		)
+	}
+	// the VLANs may also be on interfaces which are not bonded
+	for _, link := range links {
+		if !bonds[link] {
+			units = append(units, ignconfigtypes.Networkdunit{Name: fmt.Sprintf("20-%s.network", link), Contents: linkNetwork(link)})
+		}
+	}
+	for _, v := range n.VLANs {
+		units = append(units,
+			ignconfigtypes.Networkdunit{
+				Name:     fmt.Sprintf("30-%s.netdev", v.Name()),
+				Contents: fmt.Sprintf("[NetDev]\nName=%s\nKind=vlan\n\n[VLAN]\nId=%d\n", v.Name(), v.ID),
+			},
+			ignconfigtypes.Networkdunit{Name: fmt.Sprintf("30-%s.network", v.Name()), Contents: linkNetwork(v.Name())},
+		)
+	}
+	return units
+}
+
 func (c *ConfigGenerator) embedUserBlock(ignCfg *ignconfigtypes.Config) {
 	if c.Platform == config.PlatformLibvirt {
 		userBlock := ignconfigtypes.PasswdUser{
@@ -144,7 +448,7 @@ func (c *ConfigGenerator) getTNCURL(role string) string {
 		u = func() *url.URL {
 			return &url.URL{
 				Scheme: scheme,
-				Host:   fmt.Sprintf("%s-tnc.%s:%d", c.Name, c.BaseDomain, port),
+				Host:   fmt.Sprintf("%s-tnc.%s:%d", c.Name, c.DNSDomain(), port),
 				Path:   fmt.Sprintf("/config/%s", role),
 			}
 		}().String()
@@ -152,11 +456,81 @@ func (c *ConfigGenerator) getTNCURL(role string) string {
 	return u
 }
 
+// validateIgnConfig validates the config against the Ignition spec, naming
+// the file or unit at fault, and rejects paths and units declared twice,
+// which Ignition does not reject but resolves by overwriting.
+func validateIgnConfig(ignCfg ignconfigtypes.Config) error {
+	var errs []string
+	add := func(prefix string, r report.Report) {
+		for _, e := range r.Entries {
+			if e.Kind == report.EntryError {
+				errs = append(errs, prefix+e.Message)
+			}
+		}
+	}
+
+	paths := map[string]bool{}
+	addPath := func(kind string, n ignconfigtypes.Node) {
+		key := n.Filesystem + ":" + n.Path
+		if paths[key] {
+			errs = append(errs, fmt.Sprintf("%s %s: path declared more than once", kind, n.Path))
+		}
+		paths[key] = true
+	}
+	for _, f := range ignCfg.Storage.Files {
+		add(fmt.Sprintf("file %s: ", f.Path), ignvalidate.ValidateWithoutSource(reflect.ValueOf(f)))
+		addPath("file", f.Node)
+	}
+	for _, d := range ignCfg.Storage.Directories {
+		addPath("directory", d.Node)
+	}
+	for _, l := range ignCfg.Storage.Links {
+		addPath("link", l.Node)
+	}
+	units := map[string]bool{}
+	for _, u := range ignCfg.Systemd.Units {
+		add(fmt.Sprintf("unit %s: ", u.Name), ignvalidate.ValidateWithoutSource(reflect.ValueOf(u)))
+		if units[u.Name] {
+			errs = append(errs, fmt.Sprintf("unit %s: declared more than once", u.Name))
+		}
+		units[u.Name] = true
+	}
+
+	// the rest of the config, the files and units being validated above
+	rest := ignCfg
+	rest.Storage.Files = nil
+	rest.Systemd.Units = nil
+	add("", ignvalidate.ValidateWithoutSource(reflect.ValueOf(rest)))
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ignCfgToFile encodes the ignition config straight into the file rather
+// than through an intermediate buffer, since embedded files such as the
+// bootstrap overrides can make it large.
+// The config is validated first, so that errors are not discovered by the
+// nodes on their first boot.
 func ignCfgToFile(ignCfg ignconfigtypes.Config, filePath string) error {
-	data, err := json.MarshalIndent(&ignCfg, "", "  ")
+	if err := validateIgnConfig(ignCfg); err != nil {
+		return fmt.Errorf("invalid ignition config %s: %v", filePath, err)
+	}
+	f, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
-
-	return writeFile(filePath, string(data))
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(&ignCfg); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
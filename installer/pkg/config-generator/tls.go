@@ -1,17 +1,22 @@
 package configgenerator
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/openshift/installer/installer/pkg/config"
 	"github.com/openshift/installer/installer/pkg/tls"
 )
 
@@ -28,6 +33,8 @@ const (
 	etcdCAKeyPath            = "generated/newTLS/etcd-ca.key"
 	etcdClientCertPath       = "generated/newTLS/etcd-client.crt"
 	etcdClientKeyPath        = "generated/newTLS/etcd-client.key"
+	etcdMetricCertPath       = "generated/newTLS/etcd-metric.crt"
+	etcdMetricKeyPath        = "generated/newTLS/etcd-metric.key"
 	ingressCACertPath        = "generated/newTLS/ingress-ca.crt"
 	ingressCertPath          = "generated/newTLS/ingress.crt"
 	ingressKeyPath           = "generated/newTLS/ingress.key"
@@ -43,211 +50,373 @@ const (
 	serviceServingCAKeyPath  = "generated/newTLS/service-serving-ca.key"
 	tncCertPath              = "generated/newTLS/tnc.crt"
 	tncKeyPath               = "generated/newTLS/tnc.key"
-
-	validityThreeYears = time.Hour * 24 * 365 * 3
 )
 
 // GenerateTLSConfig fetches and validates the TLS cert files
 // If no file paths were provided, the certs will be auto-generated
 func (c *ConfigGenerator) GenerateTLSConfig(clusterDir string) error {
-	var caKey *rsa.PrivateKey
+	var caKey crypto.Signer
 	var caCert *x509.Certificate
 	var err error
 
-	if c.CA.RootCAKeyPath == "" && c.CA.RootCACertPath == "" {
-		caCert, caKey, err = generateRootCert(clusterDir)
+	if c.CA.RootCACertPath == "" {
+		caCert, caKey, err = generateRootCert(clusterDir, c.TLS.KeySize, c.TLS.CAValidity)
 		if err != nil {
 			return fmt.Errorf("failed to generate root CA certificate and key pair: %v", err)
 		}
 	} else {
 		// copy key and certificates
-		caCert, caKey, err = getCertFiles(clusterDir, c.CA.RootCACertPath, c.CA.RootCAKeyPath)
+		caCert, err = getCertFiles(clusterDir, c.CA.RootCACertPath, c.CA.RootCAKeyPath)
 		if err != nil {
 			return fmt.Errorf("failed to process CA certificate and key pair: %v", err)
 		}
+		caKey, err = c.rootCASigner(caCert)
+		if err != nil {
+			return fmt.Errorf("failed to process CA key: %v", err)
+		}
 	}
 
-	// generate kube CA
-	cfg := &tls.CertCfg{
-		Subject:   pkix.Name{CommonName: "kube-ca", OrganizationalUnit: []string{"bootkube"}},
-		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  validityThreeYears,
-		IsCA:      true,
-	}
-	kubeCAKey, kubeCACert, err := generateCert(clusterDir, caKey, caCert, kubeCAKeyPath, kubeCACertPath, cfg)
-	if err != nil {
-		return fmt.Errorf("failed to generate kubernetes CA: %v", err)
-	}
-
-	// generate etcd CA
-	cfg = &tls.CertCfg{
-		Subject:   pkix.Name{CommonName: "etcd", OrganizationalUnit: []string{"etcd"}},
-		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		IsCA:      true,
-	}
-	etcdCAKey, etcdCACert, err := generateCert(clusterDir, caKey, caCert, etcdCAKeyPath, etcdCACertPath, cfg)
-	if err != nil {
-		return fmt.Errorf("failed to generate etcd CA: %v", err)
-	}
-
-	// generate etcd client certificate
-	cfg = &tls.CertCfg{
-		Subject:      pkix.Name{CommonName: "etcd", OrganizationalUnit: []string{"etcd"}},
-		KeyUsages:    x509.KeyUsageKeyEncipherment,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-	}
-	if _, _, err := generateCert(clusterDir, etcdCAKey, etcdCACert, etcdClientKeyPath, etcdClientCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate etcd client certificate: %v", err)
-	}
-
-	// generate aggregator CA
-	cfg = &tls.CertCfg{
-		Subject:   pkix.Name{CommonName: "aggregator", OrganizationalUnit: []string{"bootkube"}},
-		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  validityThreeYears,
-		IsCA:      true,
-	}
-	if _, _, err := generateCert(clusterDir, caKey, caCert, aggregatorCAKeyPath, aggregatorCACertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate aggregator CA: %v", err)
-	}
-
-	// generate service-serving CA
-	cfg = &tls.CertCfg{
-		Subject:   pkix.Name{CommonName: "service-serving", OrganizationalUnit: []string{"bootkube"}},
-		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  validityThreeYears,
-		IsCA:      true,
-	}
-	if _, _, err := generateCert(clusterDir, caKey, caCert, serviceServingCAKeyPath, serviceServingCACertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate service-serving CA: %v", err)
-	}
-
-	// Ingress certs
-	if copyFile(kubeCACertPath, ingressCACertPath); err != nil {
-		return fmt.Errorf("failed to import kube CA cert into ingress-ca.crt: %v", err)
-	}
-
-	baseAddress := c.getBaseAddress()
-	cfg = &tls.CertCfg{
-		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
-		DNSNames: []string{
-			baseAddress,
-			fmt.Sprintf("%s.%s", "*", baseAddress),
+	// the kube and etcd CAs sign the other certificates, generate them first
+	var kubeCAKey, etcdCAKey *rsa.PrivateKey
+	var kubeCACert, etcdCACert *x509.Certificate
+	err = runParallel(
+		// generate kube CA
+		func() error {
+			cfg := &tls.CertCfg{
+				Subject:   pkix.Name{CommonName: "kube-ca", OrganizationalUnit: []string{"bootkube"}},
+				KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+				Validity:  c.TLS.CAValidity,
+				IsCA:      true,
+			}
+			var err error
+			kubeCAKey, kubeCACert, err = generateCert(clusterDir, c.TLS.KeySize, caKey, caCert, kubeCAKeyPath, kubeCACertPath, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to generate kubernetes CA: %v", err)
+			}
+			return nil
 		},
-		Subject:  pkix.Name{CommonName: baseAddress, Organization: []string{"ingress"}},
-		Validity: validityThreeYears,
-		IsCA:     false,
-	}
-
-	if _, _, err := generateCert(clusterDir, kubeCAKey, kubeCACert, ingressKeyPath, ingressCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate ingress CA: %v", err)
-	}
-
-	// Kube admin certs
-	cfg = &tls.CertCfg{
-		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
-		Subject:      pkix.Name{CommonName: "system:admin", Organization: []string{"system:masters"}},
-		Validity:     validityThreeYears,
-		IsCA:         false,
-	}
-
-	if _, _, err = generateCert(clusterDir, kubeCAKey, kubeCACert, adminKeyPath, adminCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate kube admin certificate: %v", err)
+		// generate etcd CA, self-signed so that only the certificates it
+		// issues are trusted by etcd, and not those of the root CA
+		func() error {
+			cfg := &tls.CertCfg{
+				Subject:   pkix.Name{CommonName: "etcd", OrganizationalUnit: []string{"etcd"}},
+				KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+				Validity:  c.TLS.CAValidity,
+				IsCA:      true,
+			}
+			var err error
+			etcdCAKey, etcdCACert, err = generateSelfSignedCA(clusterDir, c.TLS.KeySize, etcdCAKeyPath, etcdCACertPath, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to generate etcd CA: %v", err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
 	}
 
-	// Kube API server certs
 	apiServerAddress, err := cidrhost(c.Cluster.Networking.ServiceCIDR, 1)
 	if err != nil {
 		return fmt.Errorf("can't resolve api server host address: %v", err)
 	}
-	cfg = &tls.CertCfg{
-		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
-		Subject:      pkix.Name{CommonName: "kube-apiserver", Organization: []string{"kube-master"}},
-		DNSNames: []string{
-			fmt.Sprintf("%s-api.%s", c.Name, c.BaseDomain),
-			"kubernetes", "kubernetes.default",
-			"kubernetes.default.svc",
-			"kubernetes.default.svc.cluster.local",
+	extraDNSNames, extraIPAddresses := config.SplitSANs(c.TLS.ExtraSANs)
+
+	tasks := []func() error{
+		// generate etcd client certificate
+		func() error {
+			cfg := &tls.CertCfg{
+				Subject:      pkix.Name{CommonName: "etcd", OrganizationalUnit: []string{"etcd"}},
+				KeyUsages:    x509.KeyUsageKeyEncipherment,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				Validity:     c.TLS.CertValidity,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, etcdCAKey, etcdCACert, etcdClientKeyPath, etcdClientCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate etcd client certificate: %v", err)
+			}
+			return nil
+		},
+		// generate aggregator CA
+		func() error {
+			cfg := &tls.CertCfg{
+				Subject:   pkix.Name{CommonName: "aggregator", OrganizationalUnit: []string{"bootkube"}},
+				KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+				Validity:  c.TLS.CAValidity,
+				IsCA:      true,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, caKey, caCert, aggregatorCAKeyPath, aggregatorCACertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate aggregator CA: %v", err)
+			}
+			return nil
+		},
+		// generate service-serving CA
+		func() error {
+			cfg := &tls.CertCfg{
+				Subject:   pkix.Name{CommonName: "service-serving", OrganizationalUnit: []string{"bootkube"}},
+				KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+				Validity:  c.TLS.CAValidity,
+				IsCA:      true,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, caKey, caCert, serviceServingCAKeyPath, serviceServingCACertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate service-serving CA: %v", err)
+			}
+			return nil
+		},
+		// Ingress certs
+		func() error {
+			if err := copyFile(filepath.Join(clusterDir, kubeCACertPath), filepath.Join(clusterDir, ingressCACertPath)); err != nil {
+				return fmt.Errorf("failed to import kube CA cert into ingress-ca.crt: %v", err)
+			}
+
+			baseAddress := c.getBaseAddress()
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				DNSNames: []string{
+					baseAddress,
+					fmt.Sprintf("%s.%s", "*", baseAddress),
+				},
+				Subject:  pkix.Name{CommonName: baseAddress, Organization: []string{"ingress"}},
+				Validity: c.TLS.CertValidity,
+				IsCA:     false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, kubeCAKey, kubeCACert, ingressKeyPath, ingressCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate ingress CA: %v", err)
+			}
+			return nil
+		},
+		// Kube admin certs
+		func() error {
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				Subject:      pkix.Name{CommonName: c.Admin.AdminCert.CommonName, Organization: []string{c.Admin.AdminCert.Organization}},
+				Validity:     c.Admin.AdminCert.Validity,
+				IsCA:         false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, kubeCAKey, kubeCACert, adminKeyPath, adminCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate kube admin certificate: %v", err)
+			}
+			return nil
+		},
+		// Kube API server certs
+		func() error {
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				Subject:      pkix.Name{CommonName: "kube-apiserver", Organization: []string{"kube-master"}},
+				DNSNames: []string{
+					fmt.Sprintf("%s-api.%s", c.Name, c.DNSDomain()),
+					"kubernetes", "kubernetes.default",
+					"kubernetes.default.svc",
+					"kubernetes.default.svc.cluster.local",
+				},
+				Validity:    c.TLS.CertValidity,
+				IPAddresses: []net.IP{net.ParseIP(apiServerAddress)},
+				IsCA:        false,
+			}
+			cfg.DNSNames = append(cfg.DNSNames, extraDNSNames...)
+			cfg.IPAddresses = append(cfg.IPAddresses, extraIPAddresses...)
+
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, kubeCAKey, kubeCACert, apiServerKeyPath, apiServerCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate kube api server certificate: %v", err)
+			}
+			return nil
+		},
+		// Kube API openshift certs
+		func() error {
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				Subject:      pkix.Name{CommonName: "openshift-apiserver", Organization: []string{"kube-master"}},
+				DNSNames: []string{
+					fmt.Sprintf("%s-%s.%s", c.Name, "api", c.DNSDomain()),
+					"openshift-apiserver",
+					"openshift-apiserver.kube-system",
+					"openshift-apiserver.kube-system.svc",
+					"openshift-apiserver.kube-system.svc.cluster.local",
+					"localhost", "127.0.0.1"},
+				Validity:    c.TLS.CertValidity,
+				IPAddresses: []net.IP{net.ParseIP(apiServerAddress)},
+				IsCA:        false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, kubeCAKey, kubeCACert, osAPIServerKeyPath, osAPIServerCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate openshift api server certificate: %v", err)
+			}
+			return nil
+		},
+		// Kube API proxy certs
+		func() error {
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				Subject:      pkix.Name{CommonName: "kube-apiserver-proxy", Organization: []string{"kube-master"}},
+				Validity:     c.TLS.CertValidity,
+				IsCA:         false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, kubeCAKey, kubeCACert, apiServerProxyKeyPath, apiServerProxyCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate kube api proxy certificate: %v", err)
+			}
+			return nil
+		},
+		// Kubelet certs
+		func() error {
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				Subject:      pkix.Name{CommonName: "system:serviceaccount:kube-system:default", Organization: []string{"system:serviceaccounts:kube-system"}},
+				Validity:     c.TLS.CertValidity,
+				IsCA:         false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, kubeCAKey, kubeCACert, kubeletKeyPath, kubeletCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate kubelet certificate: %v", err)
+			}
+			return nil
+		},
+		// TNC certs
+		func() error {
+			tncDomain := fmt.Sprintf("%s-tnc.%s", c.Name, c.DNSDomain())
+			cfg := &tls.CertCfg{
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+				DNSNames:     append([]string{tncDomain}, extraDNSNames...),
+				IPAddresses:  extraIPAddresses,
+				Subject:      pkix.Name{CommonName: tncDomain},
+				Validity:     c.TLS.CertValidity,
+				IsCA:         false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, caKey, caCert, tncKeyPath, tncCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate tnc certificate: %v", err)
+			}
+			return nil
+		},
+		// etcd metrics client certificate
+		func() error {
+			cfg := &tls.CertCfg{
+				KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				Subject:      pkix.Name{CommonName: "system:etcd-metric", Organization: []string{"system:etcd-metrics"}},
+				Validity:     c.TLS.CertValidity,
+				IsCA:         false,
+			}
+			if _, _, err := generateCert(clusterDir, c.TLS.KeySize, etcdCAKey, etcdCACert, etcdMetricKeyPath, etcdMetricCertPath, cfg); err != nil {
+				return fmt.Errorf("failed to generate etcd metrics client certificate: %v", err)
+			}
+			return nil
 		},
-		Validity:    validityThreeYears,
-		IPAddresses: []net.IP{net.ParseIP(apiServerAddress)},
-		IsCA:        false,
 	}
 
-	if _, _, err := generateCert(clusterDir, kubeCAKey, kubeCACert, apiServerKeyPath, apiServerCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate kube api server certificate: %v", err)
-	}
+	return runParallel(tasks...)
+}
 
-	// Kube API openshift certs
-	cfg = &tls.CertCfg{
-		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
-		Subject:      pkix.Name{CommonName: "openshift-apiserver", Organization: []string{"kube-master"}},
-		DNSNames: []string{
-			fmt.Sprintf("%s-%s.%s", c.Name, "api", c.BaseDomain),
-			"openshift-apiserver",
-			"openshift-apiserver.kube-system",
-			"openshift-apiserver.kube-system.svc",
-			"openshift-apiserver.kube-system.svc.cluster.local",
-			"localhost", "127.0.0.1"},
-		Validity:    validityThreeYears,
-		IPAddresses: []net.IP{net.ParseIP(apiServerAddress)},
-		IsCA:        false,
+// GenerateSignedCAs signs, with the root CA signer command, the intermediate
+// CAs and the TNC certificate the tls step otherwise signs with the root CA key,
+// and writes them to config.SignedCAsPath for it, so that the root CA key never
+// has to be on disk. The certificates are signed in a temporary directory moved
+// into place once all of them are, so a failed run leaves none behind.
+func (c *ConfigGenerator) GenerateSignedCAs(clusterDir string) error {
+	if c.CA.RootCASignerCommand == "" {
+		return nil
 	}
-
-	if _, _, err := generateCert(clusterDir, kubeCAKey, kubeCACert, osAPIServerKeyPath, osAPIServerCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate openshift api server certificate: %v", err)
+	signedDir := filepath.Join(clusterDir, config.SignedCAsPath)
+	if err := os.MkdirAll(filepath.Dir(signedDir), os.ModeDir|0755); err != nil {
+		return fmt.Errorf("failed to create signed CAs directory: %v", err)
 	}
-
-	// Kube API proxy certs
-	cfg = &tls.CertCfg{
-		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-		Subject:      pkix.Name{CommonName: "kube-apiserver-proxy", Organization: []string{"kube-master"}},
-		Validity:     validityThreeYears,
-		IsCA:         false,
+	tmpDir, err := ioutil.TempDir(filepath.Dir(signedDir), filepath.Base(signedDir))
+	if err != nil {
+		return fmt.Errorf("failed to create signed CAs directory: %v", err)
 	}
-
-	if _, _, err := generateCert(clusterDir, kubeCAKey, kubeCACert, apiServerProxyKeyPath, apiServerProxyCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate kube api proxy certificate: %v", err)
+	defer os.RemoveAll(tmpDir)
+	certData, err := ioutil.ReadFile(c.CA.RootCACertPath)
+	if err != nil {
+		return fmt.Errorf("failed to read CA certificate: %v", err)
 	}
-
-	// Kubelet certs
-	cfg = &tls.CertCfg{
-		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-		Subject:      pkix.Name{CommonName: "system:serviceaccount:kube-system:default", Organization: []string{"system:serviceaccounts:kube-system"}},
-		Validity:     validityThreeYears,
-		IsCA:         false,
+	certs, err := parseCertificates(certData)
+	if err != nil {
+		return fmt.Errorf("failed to process CA certificate: %v", err)
 	}
-
-	if _, _, err := generateCert(clusterDir, kubeCAKey, kubeCACert, kubeletKeyPath, kubeletCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate kubelet certificate: %v", err)
+	caCert := certs[0]
+	caKey, err := c.rootCASigner(caCert)
+	if err != nil {
+		return err
+	}
+
+	tncDomain := fmt.Sprintf("%s-tnc.%s", c.Name, c.DNSDomain())
+	extraDNSNames, extraIPAddresses := config.SplitSANs(c.TLS.ExtraSANs)
+	var tasks []func() error
+	for _, cert := range []struct {
+		name string
+		cfg  *tls.CertCfg
+	}{
+		{name: "kube-ca", cfg: intermediateCACfg("kube-ca", "bootkube", c.TLS.CAValidity)},
+		{name: "aggregator-ca", cfg: intermediateCACfg("aggregator", "bootkube", c.TLS.CAValidity)},
+		{name: "service-serving-ca", cfg: intermediateCACfg("service-serving", "bootkube", c.TLS.CAValidity)},
+		{name: "etcd-ca", cfg: intermediateCACfg("etcd-ca", "etcd", c.TLS.CAValidity)},
+		{name: "tnc", cfg: &tls.CertCfg{
+			ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			DNSNames:     append([]string{tncDomain}, extraDNSNames...),
+			IPAddresses:  extraIPAddresses,
+			Subject:      pkix.Name{CommonName: tncDomain},
+			Validity:     c.TLS.CertValidity,
+		}},
+	} {
+		cert := cert
+		tasks = append(tasks, func() error {
+			if _, _, err := generateCert(tmpDir, c.TLS.KeySize, caKey, caCert, cert.name+".key", cert.name+".crt", cert.cfg); err != nil {
+				return fmt.Errorf("failed to sign %s certificate: %v", cert.name, err)
+			}
+			return nil
+		})
+	}
+	if err := runParallel(tasks...); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpDir, os.ModeDir|0755); err != nil {
+		return fmt.Errorf("failed to create signed CAs directory: %v", err)
+	}
+	if err := os.Rename(tmpDir, signedDir); err != nil {
+		return fmt.Errorf("failed to move the signed CAs into place: %v", err)
 	}
+	return nil
+}
 
-	// TNC certs
-	tncDomain := fmt.Sprintf("%s-tnc.%s", c.Name, c.BaseDomain)
-	cfg = &tls.CertCfg{
-		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		DNSNames:     []string{tncDomain},
-		Subject:      pkix.Name{CommonName: tncDomain},
-		Validity:     validityThreeYears,
-		IsCA:         false,
+// intermediateCACfg returns the config of an intermediate CA signed by the root CA.
+func intermediateCACfg(commonName, unit string, validity time.Duration) *tls.CertCfg {
+	return &tls.CertCfg{
+		Subject:   pkix.Name{CommonName: commonName, OrganizationalUnit: []string{unit}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  validity,
+		IsCA:      true,
 	}
+}
 
-	if _, _, err := generateCert(clusterDir, caKey, caCert, tncKeyPath, tncCertPath, cfg); err != nil {
-		return fmt.Errorf("failed to generate tnc certificate: %v", err)
+// runParallel runs the given functions concurrently, since generating RSA
+// keys is CPU-bound, and returns the first error in argument order.
+func runParallel(fns ...func() error) error {
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		wg.Add(1)
+		go func(i int, fn func() error) {
+			defer wg.Done()
+			errs[i] = fn()
+		}(i, fn)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// generatePrivateKey generates and writes the private key to disk
-func generatePrivateKey(clusterDir string, path string) (*rsa.PrivateKey, error) {
+// generatePrivateKey generates and writes the private key, of keySize bits, to disk
+func generatePrivateKey(clusterDir string, keySize int, path string) (*rsa.PrivateKey, error) {
+	if keySize == 0 {
+		keySize = tls.DefaultKeySize
+	}
 	fileTargetPath := filepath.Join(clusterDir, path)
-	key, err := tls.PrivateKey()
+	key, err := tls.PrivateKeyWithSize(keySize)
 	if err != nil {
 		return nil, fmt.Errorf("error writing private key: %v", err)
 	}
@@ -258,29 +427,34 @@ func generatePrivateKey(clusterDir string, path string) (*rsa.PrivateKey, error)
 }
 
 // generateRootCert creates the rootCAKey and rootCACert
-func generateRootCert(clusterDir string) (cert *x509.Certificate, key *rsa.PrivateKey, err error) {
+func generateRootCert(clusterDir string, keySize int, validity time.Duration) (cert *x509.Certificate, key crypto.Signer, err error) {
 	// generate key and certificate
-	caKey, err := generatePrivateKey(clusterDir, rootCAKeyPath)
+	caKey, err := generatePrivateKey(clusterDir, keySize, rootCAKeyPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate private key: %v", err)
 	}
-	caCert, err := generateRootCA(clusterDir, caKey)
+	caCert, err := generateRootCA(clusterDir, caKey, validity)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create a certificate: %v", err)
 	}
 	return caCert, caKey, nil
 }
 
-// getCertFiles copies the given cert/key files into the generated folder and returns their contents
-func getCertFiles(clusterDir string, certPath string, keyPath string) (*x509.Certificate, *rsa.PrivateKey, error) {
-	keyDst := filepath.Join(clusterDir, rootCAKeyPath)
-	if err := copyFile(keyPath, keyDst); err != nil {
-		return nil, nil, fmt.Errorf("failed to write file: %v", err)
+// getCertFiles copies the given cert/key files into the generated folder and returns the certificate.
+// The key file is optional, the key being held by the signer command in its absence.
+// The certificate file may hold an intermediate CA followed by the rest of its chain,
+// in which case the first certificate is used to sign the cluster certificates.
+func getCertFiles(clusterDir string, certPath string, keyPath string) (*x509.Certificate, error) {
+	if keyPath != "" {
+		keyDst := filepath.Join(clusterDir, rootCAKeyPath)
+		if err := copyFile(keyPath, keyDst); err != nil {
+			return nil, fmt.Errorf("failed to write file: %v", err)
+		}
 	}
 
 	certDst := filepath.Join(clusterDir, rootCACertPath)
 	if err := copyFile(certPath, certDst); err != nil {
-		return nil, nil, fmt.Errorf("failed to write file: %v", err)
+		return nil, fmt.Errorf("failed to write file: %v", err)
 	}
 	// content validation occurs in pkg/config/validate.go
 	// if it fails here, something went wrong
@@ -288,34 +462,58 @@ func getCertFiles(clusterDir string, certPath string, keyPath string) (*x509.Cer
 	if err != nil {
 		panic(err)
 	}
-	certPem, _ := pem.Decode([]byte(string(certData)))
-	keyData, err := ioutil.ReadFile(keyPath)
-	if err != nil {
-		panic(err)
-	}
-	keyPem, _ := pem.Decode([]byte(string(keyData)))
-	key, err := x509.ParsePKCS1PrivateKey(keyPem.Bytes)
+	certs, err := parseCertificates(certData)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to process private key: %v", err)
+		return nil, fmt.Errorf("failed to process certificate: %v", err)
 	}
-	certs, err := x509.ParseCertificates(certPem.Bytes)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to process certificate: %v", err)
+
+	return certs[0], nil
+}
+
+// rootCASigner returns the signer backend of the key of the user supplied
+// root CA cert: its signer command if any, else its key file.
+func (c *ConfigGenerator) rootCASigner(cert *x509.Certificate) (crypto.Signer, error) {
+	if c.CA.RootCASignerCommand != "" {
+		return tls.CommandSigner(c.CA.RootCASignerCommand, cert.PublicKey), nil
 	}
+	return tls.FileSigner(c.CA.RootCAKeyPath)
+}
 
-	return certs[0], key, nil
+// parseCertificates returns all the certificates found in the given PEM data, in order.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
 }
 
 // generateCert creates a key, csr & a signed cert
 func generateCert(clusterDir string,
-	caKey *rsa.PrivateKey,
+	keySize int,
+	caKey crypto.Signer,
 	caCert *x509.Certificate,
 	keyPath string,
 	certPath string,
 	cfg *tls.CertCfg) (*rsa.PrivateKey, *x509.Certificate, error) {
 
 	// create a private key
-	key, err := generatePrivateKey(clusterDir, keyPath)
+	key, err := generatePrivateKey(clusterDir, keySize, keyPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate private key: %v", err)
 	}
@@ -340,7 +538,7 @@ func generateCert(clusterDir string,
 }
 
 // generateRootCA creates and returns the root CA
-func generateRootCA(path string, key *rsa.PrivateKey) (*x509.Certificate, error) {
+func generateRootCA(path string, key *rsa.PrivateKey, validity time.Duration) (*x509.Certificate, error) {
 	fileTargetPath := filepath.Join(path, rootCACertPath)
 	cfg := &tls.CertCfg{
 		Subject: pkix.Name{
@@ -348,7 +546,7 @@ func generateRootCA(path string, key *rsa.PrivateKey) (*x509.Certificate, error)
 			OrganizationalUnit: []string{"openshift"},
 		},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  validityThreeYears,
+		Validity:  validity,
 		IsCA:      true,
 	}
 	cert, err := tls.SelfSignedCACert(cfg, key)
@@ -361,10 +559,26 @@ func generateRootCA(path string, key *rsa.PrivateKey) (*x509.Certificate, error)
 	return cert, nil
 }
 
+// generateSelfSignedCA generates a key and a self-signed CA certificate and writes them to the given paths.
+func generateSelfSignedCA(clusterDir string, keySize int, keyPath, certPath string, cfg *tls.CertCfg) (*rsa.PrivateKey, *x509.Certificate, error) {
+	key, err := generatePrivateKey(clusterDir, keySize, keyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate private key: %v", err)
+	}
+	cert, err := tls.SelfSignedCACert(cfg, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating self signed certificate: %v", err)
+	}
+	if err := writeFile(filepath.Join(clusterDir, certPath), tls.CertToPem(cert)); err != nil {
+		return nil, nil, err
+	}
+	return key, cert, nil
+}
+
 func generateSignedCert(cfg *tls.CertCfg,
 	csr *x509.CertificateRequest,
 	key *rsa.PrivateKey,
-	caKey *rsa.PrivateKey,
+	caKey crypto.Signer,
 	caCert *x509.Certificate,
 	clusterDir string,
 	path string) (*x509.Certificate, error) {
@@ -27,7 +27,7 @@ func copyFile(fromFilePath, toFilePath string) error {
 		return err
 	}
 	defer from.Close()
-	to, err := os.OpenFile(toFilePath, os.O_RDWR|os.O_CREATE, 0666)
+	to, err := os.OpenFile(toFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 	if err != nil {
 		return err
 	}
@@ -10,29 +10,69 @@ const (
 	EndpointsPrivate Endpoints = "private"
 	// EndpointsPublic represents the configuration for using only public endpoints.
 	EndpointsPublic Endpoints = "public"
+	// LoadBalancerClassic is the classic Elastic Load Balancer type.
+	LoadBalancerClassic = "classic"
+	// LoadBalancerNetwork is the Network Load Balancer type.
+	LoadBalancerNetwork = "network"
+	// ServiceEC2 names the EC2 service in ServiceEndpoints.
+	ServiceEC2 = "ec2"
+	// ServiceELB names the ELB service in ServiceEndpoints.
+	ServiceELB = "elb"
+	// ServiceIAM names the IAM service in ServiceEndpoints.
+	ServiceIAM = "iam"
+	// ServiceRoute53 names the Route53 service in ServiceEndpoints.
+	ServiceRoute53 = "route53"
+	// ServiceS3 names the S3 service in ServiceEndpoints.
+	ServiceS3 = "s3"
+	// ServiceSTS names the STS service in ServiceEndpoints.
+	ServiceSTS = "sts"
 	// DefaultVPCCIDRBlock is the default CIDR range for an AWS VPC.
 	DefaultVPCCIDRBlock = "10.0.0.0/16"
 	// DefaultProfile is the default AWS credentials profile to use.
 	DefaultProfile = "default"
 	// DefaultRegion is the default AWS region for the cluster.
 	DefaultRegion = "eu-west-1"
+	// MaxVPCDNSServers is the maximum number of DNS servers of a DHCP options set.
+	MaxVPCDNSServers = 4
 )
 
 // AWS converts AWS related config.
 type AWS struct {
-	AutoScalingGroupExtraTags []map[string]string `json:"tectonic_autoscaling_group_extra_tags,omitempty" yaml:"autoScalingGroupExtraTags,omitempty"`
-	EC2AMIOverride            string              `json:"tectonic_aws_ec2_ami_override,omitempty" yaml:"ec2AMIOverride,omitempty"`
-	Endpoints                 Endpoints           `json:"tectonic_aws_endpoints,omitempty" yaml:"endpoints,omitempty"`
-	Etcd                      `json:",inline" yaml:"etcd,omitempty"`
-	External                  `json:",inline" yaml:"external,omitempty"`
-	ExtraTags                 map[string]string `json:"tectonic_aws_extra_tags,omitempty" yaml:"extraTags,omitempty"`
-	InstallerRole             string            `json:"tectonic_aws_installer_role,omitempty" yaml:"installerRole,omitempty"`
-	Master                    `json:",inline" yaml:"master,omitempty"`
-	Profile                   string `json:"tectonic_aws_profile,omitempty" yaml:"profile,omitempty"`
-	Region                    string `json:"tectonic_aws_region,omitempty" yaml:"region,omitempty"`
-	SSHKey                    string `json:"tectonic_aws_ssh_key,omitempty" yaml:"sshKey,omitempty"`
-	VPCCIDRBlock              string `json:"tectonic_aws_vpc_cidr_block,omitempty" yaml:"vpcCIDRBlock,omitempty"`
-	Worker                    `json:",inline" yaml:"worker,omitempty"`
+	APIExternalLoadBalancerType string              `json:"tectonic_aws_api_external_lb_type,omitempty" yaml:"apiExternalLoadBalancerType,omitempty"`
+	AutoScalingGroupExtraTags   []map[string]string `json:"tectonic_autoscaling_group_extra_tags,omitempty" yaml:"autoScalingGroupExtraTags,omitempty"`
+	CloudConfig                 CloudConfig         `json:"-" yaml:"cloudConfig,omitempty"`
+	EC2AMIOverride              string              `json:"tectonic_aws_ec2_ami_override,omitempty" yaml:"ec2AMIOverride,omitempty"`
+	Endpoints                   Endpoints           `json:"tectonic_aws_endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Etcd                        `json:",inline" yaml:"etcd,omitempty"`
+	External                    `json:",inline" yaml:"external,omitempty"`
+	ExtraTags                   map[string]string `json:"tectonic_aws_extra_tags,omitempty" yaml:"extraTags,omitempty"`
+	InstallerRole               string            `json:"tectonic_aws_installer_role,omitempty" yaml:"installerRole,omitempty"`
+	Master                      `json:",inline" yaml:"master,omitempty"`
+	MaxRetries                  int               `json:"tectonic_aws_max_retries,omitempty" yaml:"maxRetries,omitempty"`
+	Profile                     string            `json:"tectonic_aws_profile,omitempty" yaml:"profile,omitempty"`
+	Region                      string            `json:"tectonic_aws_region,omitempty" yaml:"region,omitempty"`
+	ServiceEndpoints            map[string]string `json:"tectonic_aws_service_endpoints,omitempty" yaml:"serviceEndpoints,omitempty"`
+	SSHKey                      string            `json:"tectonic_aws_ssh_key,omitempty" yaml:"sshKey,omitempty"`
+	VPCCIDRBlock                string            `json:"tectonic_aws_vpc_cidr_block,omitempty" yaml:"vpcCIDRBlock,omitempty"`
+	VPCDNSServers               []string          `json:"tectonic_aws_vpc_dns_servers,omitempty" yaml:"vpcDNSServers,omitempty"`
+	Worker                      `json:",inline" yaml:"worker,omitempty"`
+}
+
+// CloudConfig converts the settings of the AWS cloud provider of Kubernetes,
+// rendered into the cloud config of the cluster.
+type CloudConfig struct {
+	// DisableSecurityGroupIngress, if set, keeps the cloud provider from
+	// opening the security groups of the nodes to the load balancers of the
+	// services, e.g. when they are managed outside of the cluster.
+	DisableSecurityGroupIngress bool `json:"-" yaml:"disableSecurityGroupIngress,omitempty"`
+	// DisableStrictZoneCheck, if set, lets the nodes be in other availability
+	// zones than the masters.
+	DisableStrictZoneCheck bool `json:"-" yaml:"disableStrictZoneCheck,omitempty"`
+	// ELBSecurityGroup is the security group of the load balancers of the
+	// services, instead of one created for each of them.
+	ELBSecurityGroup string `json:"-" yaml:"elbSecurityGroup,omitempty"`
+	// RoleARN is the IAM role the cloud provider assumes to call the AWS APIs.
+	RoleARN string `json:"-" yaml:"roleARN,omitempty"`
 }
 
 // External converts external related config.
@@ -60,6 +100,7 @@ type EtcdRootVolume struct {
 
 // Master converts master related config.
 type Master struct {
+	BootstrapEC2Type string            `json:"tectonic_aws_bootstrap_ec2_type,omitempty" yaml:"bootstrapEC2Type,omitempty"`
 	CustomSubnets    map[string]string `json:"tectonic_aws_master_custom_subnets,omitempty" yaml:"customSubnets,omitempty"`
 	EC2Type          string            `json:"tectonic_aws_master_ec2_type,omitempty" yaml:"ec2Type,omitempty"`
 	ExtraSGIDs       []string          `json:"tectonic_aws_master_extra_sg_ids,omitempty" yaml:"extraSGIDs,omitempty"`
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 
 	"github.com/coreos/tectonic-config/config/tectonic-network"
 	"gopkg.in/yaml.v2"
@@ -12,12 +13,16 @@ import (
 )
 
 const (
+	// IgnitionBootstrapOverrides is the relative path to the ign cfg appended to the bootstrap one from the tf working directory
+	IgnitionBootstrapOverrides = "ignition-bootstrap-overrides.ign"
 	// IgnitionMaster is the relative path to the ign master cfg from the tf working directory
 	IgnitionMaster = "ignition-master.ign"
 	// IgnitionWorker is the relative path to the ign worker cfg from the tf working directory
 	IgnitionWorker = "ignition-worker.ign"
 	// IgnitionEtcd is the relative path to the ign etcd cfg from the tf working directory
 	IgnitionEtcd = "ignition-etcd.ign"
+	// SignedCAsPath is the relative path to the certificates signed with the root CA signer command from the tf working directory
+	SignedCAsPath = "generated/tls-signed"
 	// PlatformAWS is the platform for a cluster launched on AWS.
 	PlatformAWS Platform = "aws"
 	// PlatformLibvirt is the platform for a cluster launched on libvirt.
@@ -27,6 +32,9 @@ const (
 // Platform indicates the target platform of the cluster.
 type Platform string
 
+// Platforms is the list of supported platforms.
+var Platforms = []Platform{PlatformAWS, PlatformLibvirt}
+
 // UnmarshalYAML unmarshals and verifies the platform.
 func (p *Platform) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var data string
@@ -38,7 +46,7 @@ func (p *Platform) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	switch platform {
 	case PlatformAWS, PlatformLibvirt:
 	default:
-		return fmt.Errorf("invalid platform specified (%s); must be one of %s", platform, []Platform{PlatformAWS, PlatformLibvirt})
+		return fmt.Errorf("invalid platform specified (%s); must be one of %s", platform, Platforms)
 	}
 
 	*p = platform
@@ -46,6 +54,13 @@ func (p *Platform) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 var defaultCluster = Cluster{
+	Admin: Admin{
+		AdminCert: AdminCert{
+			CommonName:   "system:admin",
+			Organization: "system:masters",
+			Validity:     DefaultTLSValidity,
+		},
+	},
 	AWS: aws.AWS{
 		Endpoints:    aws.EndpointsAll,
 		Profile:      aws.DefaultProfile,
@@ -66,34 +81,96 @@ var defaultCluster = Cluster{
 		},
 	},
 	Networking: Networking{
+		APIPort:     DefaultAPIPort,
 		MTU:         "1480",
 		PodCIDR:     "10.2.0.0/16",
 		ServiceCIDR: "10.3.0.0/16",
 		Type:        tectonicnetwork.NetworkCanal,
 	},
+	TLS: TLS{
+		CAValidity:   DefaultTLSValidity,
+		CertValidity: DefaultTLSValidity,
+		KeySize:      DefaultTLSKeySize,
+	},
 }
 
 // Cluster defines the config for a cluster.
 type Cluster struct {
-	Admin           `json:",inline" yaml:"admin,omitempty"`
-	aws.AWS         `json:",inline" yaml:"aws,omitempty"`
-	BaseDomain      string `json:"tectonic_base_domain,omitempty" yaml:"baseDomain,omitempty"`
-	CA              `json:",inline" yaml:"CA,omitempty"`
-	ContainerLinux  `json:",inline" yaml:"containerLinux,omitempty"`
-	Etcd            `json:",inline" yaml:"etcd,omitempty"`
-	IgnitionEtcd    string `json:"tectonic_ignition_etcd,omitempty" yaml:"-"`
-	IgnitionMaster  string `json:"tectonic_ignition_master,omitempty" yaml:"-"`
-	IgnitionWorker  string `json:"tectonic_ignition_worker,omitempty" yaml:"-"`
-	Internal        `json:",inline" yaml:"-"`
-	libvirt.Libvirt `json:",inline" yaml:"libvirt,omitempty"`
-	LicensePath     string `json:"tectonic_license_path,omitempty" yaml:"licensePath,omitempty"`
-	Master          `json:",inline" yaml:"master,omitempty"`
-	Name            string `json:"tectonic_cluster_name,omitempty" yaml:"name,omitempty"`
-	Networking      `json:",inline" yaml:"networking,omitempty"`
-	NodePools       `json:"-" yaml:"nodePools"`
-	Platform        Platform `json:"tectonic_platform" yaml:"platform,omitempty"`
-	PullSecretPath  string   `json:"tectonic_pull_secret_path,omitempty" yaml:"pullSecretPath,omitempty"`
-	Worker          `json:",inline" yaml:"worker,omitempty"`
+	AdditionalPullSecretPaths  []string `json:"-" yaml:"additionalPullSecretPaths,omitempty"`
+	Admin                      `json:",inline" yaml:"admin,omitempty"`
+	aws.AWS                    `json:",inline" yaml:"aws,omitempty"`
+	BaseDomain                 string `json:"tectonic_base_domain,omitempty" yaml:"baseDomain,omitempty"`
+	CA                         `json:",inline" yaml:"CA,omitempty"`
+	ClusterDomain              string            `json:"tectonic_cluster_domain,omitempty" yaml:"clusterDomain,omitempty"`
+	ClusterIDSeed              string            `json:"-" yaml:"clusterIDSeed,omitempty"`
+	ContainerImages            map[string]string `json:"tectonic_container_image_overrides,omitempty" yaml:"containerImages,omitempty"`
+	ContainerLinux             `json:",inline" yaml:"containerLinux,omitempty"`
+	Etcd                       `json:",inline" yaml:"etcd,omitempty"`
+	ExtraManifests             []string `json:"tectonic_extra_manifests,omitempty" yaml:"-"`
+	Hooks                      `json:"-" yaml:"hooks,omitempty"`
+	IgnitionBootstrapOverrides string `json:"tectonic_ignition_bootstrap_overrides,omitempty" yaml:"-"`
+	IgnitionEtcd               string `json:"tectonic_ignition_etcd,omitempty" yaml:"-"`
+	IgnitionMaster             string `json:"tectonic_ignition_master,omitempty" yaml:"-"`
+	IgnitionWorker             string `json:"tectonic_ignition_worker,omitempty" yaml:"-"`
+	IngressDomain              string `json:"tectonic_ingress_domain,omitempty" yaml:"ingressDomain,omitempty"`
+	Internal                   `json:",inline" yaml:"-"`
+	libvirt.Libvirt            `json:",inline" yaml:"libvirt,omitempty"`
+	LicensePath                string `json:"tectonic_license_path,omitempty" yaml:"licensePath,omitempty"`
+	Master                     `json:",inline" yaml:"master,omitempty"`
+	Name                       string `json:"tectonic_cluster_name,omitempty" yaml:"name,omitempty"`
+	Networking                 `json:",inline" yaml:"networking,omitempty"`
+	NodePools                  `json:"-" yaml:"nodePools"`
+	Platform                   Platform `json:"tectonic_platform" yaml:"platform,omitempty"`
+	PullSecretPath             string   `json:"tectonic_pull_secret_path,omitempty" yaml:"pullSecretPath,omitempty"`
+	RequestedClusterID         string   `json:"-" yaml:"clusterID,omitempty"`
+	Stats                      `json:"-" yaml:"stats,omitempty"`
+	TLS                        `json:",inline" yaml:"tls,omitempty"`
+	Update                     `json:",inline" yaml:"update,omitempty"`
+	Worker                     `json:",inline" yaml:"worker,omitempty"`
+}
+
+// DNSDomain returns the domain the host names of the cluster, e.g. of its API, are in:
+// the cluster domain if set, the base domain otherwise.
+func (c Cluster) DNSDomain() string {
+	if c.ClusterDomain != "" {
+		return c.ClusterDomain
+	}
+	return c.BaseDomain
+}
+
+// APIHost returns the host name the API of the cluster is served on.
+func (c Cluster) APIHost() string {
+	return fmt.Sprintf("%s-api.%s", c.Name, c.DNSDomain())
+}
+
+// applyMachineCIDR makes the machine CIDR, if set, the network of the nodes
+// of the platform, unless the platform-specific one is set too.
+func (c *Cluster) applyMachineCIDR() {
+	if c.Networking.MachineCIDR == "" {
+		return
+	}
+	switch c.Platform {
+	case PlatformAWS:
+		if c.AWS.VPCCIDRBlock == aws.DefaultVPCCIDRBlock {
+			c.AWS.VPCCIDRBlock = c.Networking.MachineCIDR
+		}
+	case PlatformLibvirt:
+		if c.Libvirt.Network.IPRange == "" {
+			c.Libvirt.Network.IPRange = c.Networking.MachineCIDR
+		}
+	}
+}
+
+// platformMachineCIDR returns the network of the nodes of the platform, and
+// the name of its field.
+func (c Cluster) platformMachineCIDR() (string, string) {
+	switch c.Platform {
+	case PlatformAWS:
+		return c.AWS.VPCCIDRBlock, "aws vpcCIDRBlock"
+	case PlatformLibvirt:
+		return c.Libvirt.Network.IPRange, "libvirt network ipRange"
+	}
+	return "", ""
 }
 
 // NodeCount will return the number of nodes specified in NodePools with matching names.
@@ -111,12 +188,39 @@ func (c Cluster) NodeCount(names []string) int {
 	return count
 }
 
+// SplitSANs separates the given subject alternative names, e.g. tls.extraSANs,
+// into DNS names and IP addresses.
+func SplitSANs(sans []string) (dnsNames []string, ipAddresses []net.IP) {
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			ipAddresses = append(ipAddresses, ip)
+			continue
+		}
+		dnsNames = append(dnsNames, san)
+	}
+	return dnsNames, ipAddresses
+}
+
 // TFVars will return the config for the cluster in tfvars format.
 func (c *Cluster) TFVars() (string, error) {
 	c.Etcd.Count = c.NodeCount(c.Etcd.NodePools)
 	c.Master.Count = c.NodeCount(c.Master.NodePools)
 	c.Worker.Count = c.NodeCount(c.Worker.NodePools)
 
+	c.Admin.AdminCert.ValidityHours = int(c.Admin.AdminCert.Validity.Hours())
+	c.TLS.CAValidityHours = int(c.TLS.CAValidity.Hours())
+	c.TLS.CertValidityHours = int(c.TLS.CertValidity.Hours())
+	var extraIPAddresses []net.IP
+	c.TLS.ExtraDNSNames, extraIPAddresses = SplitSANs(c.TLS.ExtraSANs)
+	c.TLS.ExtraIPAddresses = nil
+	for _, ip := range extraIPAddresses {
+		c.TLS.ExtraIPAddresses = append(c.TLS.ExtraIPAddresses, ip.String())
+	}
+	if c.CA.RootCASignerCommand != "" {
+		c.CA.SignedCAsPath = SignedCAsPath
+	}
+
+	c.IgnitionBootstrapOverrides = IgnitionBootstrapOverrides
 	c.IgnitionMaster = IgnitionMaster
 	c.IgnitionWorker = IgnitionWorker
 	c.IgnitionEtcd = IgnitionEtcd
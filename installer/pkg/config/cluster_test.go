@@ -0,0 +1,20 @@
+package config
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestSplitSANs(t *testing.T) {
+	dnsNames, ipAddresses := SplitSANs([]string{"api.example.com", "192.168.0.10", "vip.example.com", "fd00::10"})
+
+	expectedDNSNames := []string{"api.example.com", "vip.example.com"}
+	if !reflect.DeepEqual(dnsNames, expectedDNSNames) {
+		t.Errorf("Test case TestSplitSANs: expected DNS names: %v, got: %v", expectedDNSNames, dnsNames)
+	}
+	expectedIPAddresses := []net.IP{net.ParseIP("192.168.0.10"), net.ParseIP("fd00::10")}
+	if !reflect.DeepEqual(ipAddresses, expectedIPAddresses) {
+		t.Errorf("Test case TestSplitSANs: expected IP addresses: %v, got: %v", expectedIPAddresses, ipAddresses)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Explain documents the config field at the given dotted path (e.g. "aws.master"),
+// as derived from the struct tags of the config types: its type, the terraform
+// variable it is passed as, if any, and its sub-fields.
+// An empty path documents the whole config.
+func Explain(path string) (string, error) {
+	t := reflect.TypeOf(Cluster{})
+	var tfVar string
+	if path != "" {
+		for _, name := range strings.Split(path, ".") {
+			f, ok := yamlField(structType(t), name)
+			if !ok {
+				return "", fmt.Errorf("field %q does not exist in %q", name, path)
+			}
+			t = f.Type
+			tfVar = terraformVariable(f)
+		}
+	}
+
+	var buf bytes.Buffer
+	if path != "" {
+		fmt.Fprintf(&buf, "FIELD:    %s\n", path)
+	}
+	fmt.Fprintf(&buf, "TYPE:     %s\n", typeName(t))
+	if tfVar != "" {
+		fmt.Fprintf(&buf, "VARIABLE: %s\n", tfVar)
+	}
+
+	if st := structType(t); st != nil {
+		buf.WriteString("\nFIELDS:\n")
+		w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		for _, f := range yamlFields(st) {
+			fmt.Fprintf(w, "  %s\t%s", yamlName(f), typeName(f.Type))
+			if v := terraformVariable(f); v != "" {
+				fmt.Fprintf(w, "\t%s", v)
+			}
+			fmt.Fprintln(w)
+		}
+		w.Flush()
+	}
+	return buf.String(), nil
+}
+
+// structType returns the struct type which the fields of a value of type t
+// are documented by, if any: t itself, or the element type of a list or map.
+func structType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// yamlFields returns the fields of t that can be set in the config file.
+func yamlFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous || yamlName(f) == "" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func yamlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if t == nil {
+		return reflect.StructField{}, false
+	}
+	for _, f := range yamlFields(t) {
+		if yamlName(f) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// yamlName returns the name of the field in the config file, or "" if it cannot be set there.
+func yamlName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// terraformVariable returns the name of the terraform variable the field is passed as, if any.
+func terraformVariable(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", typeName(t.Key()), typeName(t.Elem()))
+	}
+	return t.String()
+}
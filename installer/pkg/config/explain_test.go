@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		path     string
+		contains []string
+		err      bool
+	}{
+		{
+			path:     "",
+			contains: []string{"TYPE:     Cluster", "baseDomain", "tectonic_base_domain", "nodePools"},
+		},
+		{
+			path:     "aws.master",
+			contains: []string{"FIELD:    aws.master", "TYPE:     Master", "ec2Type", "tectonic_aws_master_ec2_type", "rootVolume"},
+		},
+		{
+			path:     "aws.master.rootVolume.iops",
+			contains: []string{"TYPE:     int", "VARIABLE: tectonic_aws_master_root_volume_iops"},
+		},
+		{
+			path:     "nodePools.count",
+			contains: []string{"TYPE:     int"},
+		},
+		{
+			path: "aws.nope",
+			err:  true,
+		},
+		{
+			path: "baseDomain.nope",
+			err:  true,
+		},
+	}
+	for i, c := range cases {
+		got, err := Explain(c.path)
+		if (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+			continue
+		}
+		for _, s := range c.contains {
+			if !strings.Contains(got, s) {
+				t.Errorf("test case %d: expected output to contain %q, got:\n%s", i, s, got)
+			}
+		}
+	}
+}
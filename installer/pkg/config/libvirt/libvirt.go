@@ -12,15 +12,57 @@ const (
 	DefaultDNSServer = "8.8.8.8"
 	// DefaultIfName is the default interface name for libvirt.
 	DefaultIfName = "osbr0"
+	// FirmwareBIOS boots the domains with the default BIOS.
+	FirmwareBIOS = "bios"
+	// FirmwareUEFI boots the domains with an UEFI firmware, e.g. OVMF.
+	FirmwareUEFI = "uefi"
 )
 
 // Libvirt encompasses configuration specific to libvirt.
 type Libvirt struct {
-	URI           string `json:"tectonic_libvirt_uri,omitempty" yaml:"uri"`
-	SSHKey        string `json:"tectonic_libvirt_ssh_key,omitempty" yaml:"sshKey"`
-	QCOWImagePath string `json:"tectonic_coreos_qcow_path,omitempty" yaml:"imagePath"`
-	Network       `json:",inline" yaml:"network"`
-	MasterIPs     []string `json:"tectonic_libvirt_master_ips,omitempty" yaml:"masterIPs"`
+	BootstrapDiskSize int `json:"tectonic_libvirt_bootstrap_disk_size,omitempty" yaml:"bootstrapDiskSize,omitempty"`
+	BootstrapMemory   int `json:"tectonic_libvirt_bootstrap_memory,omitempty" yaml:"bootstrapMemory,omitempty"`
+	BootstrapVCPU     int `json:"tectonic_libvirt_bootstrap_vcpu,omitempty" yaml:"bootstrapVCPU,omitempty"`
+	Firmware          `json:",inline" yaml:"firmware,omitempty"`
+	URI               string `json:"tectonic_libvirt_uri,omitempty" yaml:"uri"`
+	SSHKey            string `json:"tectonic_libvirt_ssh_key,omitempty" yaml:"sshKey"`
+	QCOWImagePath     string `json:"tectonic_coreos_qcow_path,omitempty" yaml:"imagePath"`
+	Network           `json:",inline" yaml:"network"`
+	MasterIPs         []string `json:"tectonic_libvirt_master_ips,omitempty" yaml:"masterIPs"`
+	Etcd              `json:",inline" yaml:"etcd,omitempty"`
+	Master            `json:",inline" yaml:"master,omitempty"`
+	Worker            `json:",inline" yaml:"worker,omitempty"`
+}
+
+// Etcd converts the size of the etcd domains.
+type Etcd struct {
+	DiskSize int `json:"tectonic_libvirt_etcd_disk_size,omitempty" yaml:"diskSize,omitempty"`
+	Memory   int `json:"tectonic_libvirt_etcd_memory,omitempty" yaml:"memory,omitempty"`
+	VCPU     int `json:"tectonic_libvirt_etcd_vcpu,omitempty" yaml:"vcpu,omitempty"`
+}
+
+// Master converts the size of the master domains.
+type Master struct {
+	DiskSize int `json:"tectonic_libvirt_master_disk_size,omitempty" yaml:"diskSize,omitempty"`
+	Memory   int `json:"tectonic_libvirt_master_memory,omitempty" yaml:"memory,omitempty"`
+	VCPU     int `json:"tectonic_libvirt_master_vcpu,omitempty" yaml:"vcpu,omitempty"`
+}
+
+// Worker converts the size of the worker domains.
+type Worker struct {
+	DiskSize int `json:"tectonic_libvirt_worker_disk_size,omitempty" yaml:"diskSize,omitempty"`
+	Memory   int `json:"tectonic_libvirt_worker_memory,omitempty" yaml:"memory,omitempty"`
+	VCPU     int `json:"tectonic_libvirt_worker_vcpu,omitempty" yaml:"vcpu,omitempty"`
+}
+
+// Firmware describes the firmware the libvirt domains boot with.
+type Firmware struct {
+	// Type is FirmwareBIOS or FirmwareUEFI, empty meaning FirmwareBIOS.
+	Type string `json:"tectonic_libvirt_firmware,omitempty" yaml:"type,omitempty"`
+	// Loader is the path of the UEFI firmware code, e.g. OVMF_CODE.fd.
+	Loader string `json:"tectonic_libvirt_firmware_loader,omitempty" yaml:"loader,omitempty"`
+	// NVRAMTemplate is the path of the UEFI variables store the NVRAM of each domain is copied from, e.g. OVMF_VARS.fd.
+	NVRAMTemplate string `json:"tectonic_libvirt_firmware_nvram_template,omitempty" yaml:"nvramTemplate,omitempty"`
 }
 
 // Network describes a libvirt network configuration.
@@ -29,6 +71,8 @@ type Network struct {
 	IfName    string `json:"tectonic_libvirt_network_if,omitempty" yaml:"ifName"`
 	DNSServer string `json:"tectonic_libvirt_resolver,omitempty" yaml:"dnsServer"`
 	IPRange   string `json:"tectonic_libvirt_ip_range,omitempty" yaml:"ipRange"`
+	// DNSHosts maps extra host names to the IP the network's dnsmasq resolves them to.
+	DNSHosts map[string]string `json:"tectonic_libvirt_dns_hosts,omitempty" yaml:"dnsHosts,omitempty"`
 }
 
 // TFVars fills in computed Terraform variables.
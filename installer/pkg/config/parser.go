@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"reflect"
+	"sort"
 
 	"gopkg.in/yaml.v2"
 )
@@ -13,6 +16,7 @@ func ParseConfig(data []byte) (*Cluster, error) {
 	if err := yaml.Unmarshal(data, &cluster); err != nil {
 		return nil, err
 	}
+	cluster.applyMachineCIDR()
 
 	return &cluster, nil
 }
@@ -27,6 +31,54 @@ func ParseConfigFile(path string) (*Cluster, error) {
 	return ParseConfig(data)
 }
 
+// UnknownFields returns the dotted paths of the fields of a yaml config which
+// are not part of the current schema, and are therefore ignored when parsing it.
+func UnknownFields(data []byte) ([]string, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	unknown := unknownFields("", raw, reflect.TypeOf(Cluster{}))
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+func unknownFields(path string, raw interface{}, t reflect.Type) []string {
+	st := structType(t)
+	if st == nil {
+		return nil
+	}
+	var unknown []string
+	switch raw := raw.(type) {
+	case map[interface{}]interface{}:
+		if t.Kind() == reflect.Map {
+			// the keys of maps are free-form; only check the values
+			for k, v := range raw {
+				unknown = append(unknown, unknownFields(fmt.Sprintf("%s.%v", path, k), v, t.Elem())...)
+			}
+			return unknown
+		}
+		for k, v := range raw {
+			name := fmt.Sprint(k)
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			f, ok := yamlField(st, name)
+			if !ok {
+				unknown = append(unknown, fieldPath)
+				continue
+			}
+			unknown = append(unknown, unknownFields(fieldPath, v, f.Type)...)
+		}
+	case []interface{}:
+		for i, v := range raw {
+			unknown = append(unknown, unknownFields(fmt.Sprintf("%s[%d]", path, i), v, t)...)
+		}
+	}
+	return unknown
+}
+
 // ParseInternal parses a yaml string and returns, if successful, an internal.
 func ParseInternal(data []byte) (*Internal, error) {
 	internal := &Internal{}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected []string
+	}{
+		{
+			data: `
+name: test
+aws:
+  region: eu-west-1
+  master:
+    ec2Type: t2.medium
+  extraTags:
+    owner: me
+nodePools:
+  - name: master
+    count: 1
+`,
+			expected: nil,
+		},
+		{
+			data: `
+name: test
+dns: {}
+aws:
+  master:
+    instanceType: t2.medium
+nodePools:
+  - name: master
+    replicas: 1
+`,
+			expected: []string{"aws.master.instanceType", "dns", "nodePools[0].replicas"},
+		},
+	}
+	for i, c := range cases {
+		got, err := UnknownFields([]byte(c.data))
+		if err != nil {
+			t.Errorf("test case %d: expected no error, got %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("test case %d: expected %v, got %v", i, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// pullSecret is a Docker pull secret, as produced by docker login.
+type pullSecret struct {
+	Auths map[string]json.RawMessage `json:"auths"`
+}
+
+// PullSecretPaths returns the paths of the pull secrets of the cluster: the
+// pull secret first, then the additional ones.
+func (c Cluster) PullSecretPaths() []string {
+	var paths []string
+	if c.PullSecretPath != "" {
+		paths = append(paths, c.PullSecretPath)
+	}
+	return append(paths, c.AdditionalPullSecretPaths...)
+}
+
+// MergePullSecrets returns the pull secret holding the credentials of every
+// registry of the pull secrets at paths.
+// A registry configured by several of them must have the same credentials in
+// each, the registries being compared without their scheme, e.g. https://.
+func MergePullSecrets(paths []string) ([]byte, error) {
+	merged := pullSecret{Auths: map[string]json.RawMessage{}}
+	// sources maps the registries to the registry and the path merged for them
+	sources := map[string][2]string{}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var secret pullSecret
+		if err := json.Unmarshal(data, &secret); err != nil {
+			return nil, fmt.Errorf("invalid pull secret %q: %v", path, err)
+		}
+		if len(secret.Auths) == 0 {
+			return nil, fmt.Errorf("invalid pull secret %q: no auths", path)
+		}
+
+		registries := make([]string, 0, len(secret.Auths))
+		for registry := range secret.Auths {
+			registries = append(registries, registry)
+		}
+		sort.Strings(registries)
+		for _, registry := range registries {
+			auth := secret.Auths[registry]
+			key := normalizeRegistry(registry)
+			if source, ok := sources[key]; ok {
+				same, err := sameAuth(merged.Auths[source[0]], auth)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pull secret %q: registry %s: %v", path, registry, err)
+				}
+				if !same {
+					return nil, fmt.Errorf("conflicting credentials for registry %s in pull secrets %q and %q", key, source[1], path)
+				}
+				continue
+			}
+			sources[key] = [2]string{registry, path}
+			merged.Auths[registry] = auth
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// normalizeRegistry returns the registry without its scheme and trailing slash.
+func normalizeRegistry(registry string) string {
+	if i := strings.Index(registry, "://"); i >= 0 {
+		registry = registry[i+3:]
+	}
+	return strings.TrimSuffix(registry, "/")
+}
+
+// sameAuth returns whether the credentials a and b are equal, regardless of
+// the formatting of their JSON.
+func sameAuth(a, b json.RawMessage) (bool, error) {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(va, vb), nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergePullSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pull-secret")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	secrets := map[string]string{
+		"release":  `{"auths": {"quay.io": {"auth":"cmVsZWFzZQ==","email":"a@example.com"}}}`,
+		"mirror":   `{"auths": {"mirror.example.com:5000": {"auth": "bWlycm9y"}}}`,
+		"same":     `{"auths":{"https://quay.io/":{"email":"a@example.com","auth":"cmVsZWFzZQ=="}}}`,
+		"conflict": `{"auths": {"https://quay.io": {"auth": "b3RoZXI="}}}`,
+		"empty":    `{}`,
+		"invalid":  `{"auths": `,
+	}
+	for name, content := range secrets {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write pull secret: %v", err)
+		}
+	}
+
+	cases := []struct {
+		paths    []string
+		expected string
+		err      bool
+	}{
+		{
+			paths:    []string{"release"},
+			expected: `{"auths":{"quay.io":{"auth":"cmVsZWFzZQ==","email":"a@example.com"}}}`,
+			err:      false,
+		},
+		{
+			paths:    []string{"release", "mirror"},
+			expected: `{"auths":{"mirror.example.com:5000":{"auth":"bWlycm9y"},"quay.io":{"auth":"cmVsZWFzZQ==","email":"a@example.com"}}}`,
+			err:      false,
+		},
+		{
+			paths:    []string{"release", "same"},
+			expected: `{"auths":{"quay.io":{"auth":"cmVsZWFzZQ==","email":"a@example.com"}}}`,
+			err:      false,
+		},
+		{
+			paths: []string{"release", "mirror", "conflict"},
+			err:   true,
+		},
+		{
+			paths: []string{"release", "empty"},
+			err:   true,
+		},
+		{
+			paths: []string{"release", "invalid"},
+			err:   true,
+		},
+		{
+			paths: []string{"release", "missing"},
+			err:   true,
+		},
+	}
+
+	for i, c := range cases {
+		var paths []string
+		for _, p := range c.paths {
+			paths = append(paths, filepath.Join(dir, p))
+		}
+		got, err := MergePullSecrets(paths)
+		if (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+			continue
+		}
+		if !c.err && string(got) != c.expected {
+			t.Errorf("test case %d: expected %s, got %s", i, c.expected, got)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package config
 
-import "github.com/coreos/tectonic-config/config/tectonic-network"
+import (
+	"fmt"
+	"time"
+
+	"github.com/coreos/tectonic-config/config/tectonic-network"
+)
 
 // ContainerLinuxChannel indicates the selected Container Linux channel.
 type ContainerLinuxChannel string
@@ -14,19 +19,41 @@ const (
 	ContainerLinuxChannelAlpha ContainerLinuxChannel = "alpha"
 	// ContainerLinuxVersionLatest is the string to indicate the latest Container Linux version.
 	ContainerLinuxVersionLatest = "latest"
+	// DefaultTLSValidity is the default validity period of the generated CAs and certificates.
+	DefaultTLSValidity = time.Hour * 24 * 365 * 3
+	// DefaultTLSKeySize is the default size, in bits, of the generated RSA keys.
+	DefaultTLSKeySize = 2048
+	// DefaultAPIPort is the default port the API is reachable on.
+	DefaultAPIPort = 6443
 )
 
 // Admin converts admin related config.
 type Admin struct {
-	Email    string `json:"tectonic_admin_email" yaml:"email,omitempty"`
-	Password string `json:"tectonic_admin_password" yaml:"password,omitempty"`
+	AdminCert `json:",inline" yaml:"cert,omitempty"`
+	Email     string `json:"tectonic_admin_email" yaml:"email,omitempty"`
+	Password  string `json:"tectonic_admin_password" yaml:"password,omitempty"`
+}
+
+// AdminCert converts the config of the client certificate of the admin kubeconfig.
+type AdminCert struct {
+	CommonName    string        `json:"tectonic_admin_cert_common_name,omitempty" yaml:"commonName,omitempty"`
+	Organization  string        `json:"tectonic_admin_cert_organization,omitempty" yaml:"organization,omitempty"`
+	Validity      time.Duration `json:"-" yaml:"validity,omitempty"`
+	ValidityHours int           `json:"tectonic_admin_cert_validity_hours,omitempty" yaml:"-"`
 }
 
 // CA related config
 type CA struct {
-	RootCACertPath string `json:"-" yaml:"rootCACertPath,omitempty"`
-	RootCAKeyPath  string `json:"-" yaml:"rootCAKeyPath,omitempty"`
-	RootCAKeyAlg   string `json:"-" yaml:"rootCAKeyAlg,omitempty"`
+	RootCACertPath string `json:"tectonic_ca_cert,omitempty" yaml:"rootCACertPath,omitempty"`
+	RootCAKeyPath  string `json:"tectonic_ca_key,omitempty" yaml:"rootCAKeyPath,omitempty"`
+	RootCAKeyAlg   string `json:"tectonic_ca_key_alg,omitempty" yaml:"rootCAKeyAlg,omitempty"`
+	// RootCASignerCommand, if set, signs with the root CA key instead of
+	// RootCAKeyPath, so that the key can be kept by an external signing
+	// backend, e.g. a KMS or an HSM. See tls.CommandSigner.
+	RootCASignerCommand string `json:"-" yaml:"rootCASignerCommand,omitempty"`
+	// SignedCAsPath is the directory of the certificates the installer signs
+	// with RootCASignerCommand for the tls step.
+	SignedCAsPath string `json:"tectonic_ca_signed_path,omitempty" yaml:"-"`
 }
 
 // ContainerLinux converts container linux related config.
@@ -41,6 +68,20 @@ type Etcd struct {
 	NodePools []string `json:"-" yaml:"nodePools"`
 }
 
+// Hooks are the executables run by the install workflows at the given points.
+// They are run in the cluster directory, and see it and the cluster metadata
+// file through the environment.
+type Hooks struct {
+	// PreManifests is run before the manifests are generated.
+	PreManifests string `json:"-" yaml:"preManifests,omitempty"`
+	// PostManifests is run once the manifests are generated, before they are used.
+	PostManifests string `json:"-" yaml:"postManifests,omitempty"`
+	// PreProvision is run before the infrastructure of the cluster is created.
+	PreProvision string `json:"-" yaml:"preProvision,omitempty"`
+	// PostInstall is run once the machines of the cluster are created.
+	PostInstall string `json:"-" yaml:"postInstall,omitempty"`
+}
+
 // NodePool converts node pool related config.
 type NodePool struct {
 	Count        int    `json:"-" yaml:"count"`
@@ -60,27 +101,91 @@ func (n NodePools) Map() map[string]int {
 	return m
 }
 
+// HostNetwork configures the network interfaces of the nodes of a role, e.g.
+// for datacenter networks requiring bonding or VLANs on the machine network.
+// The bonds and VLANs no VLAN is on get their address with DHCP.
+type HostNetwork struct {
+	Bonds []Bond `json:"-" yaml:"bonds,omitempty"`
+	VLANs []VLAN `json:"-" yaml:"vlans,omitempty"`
+}
+
+// Bond is a bond of network interfaces.
+type Bond struct {
+	Name string `json:"-" yaml:"name"`
+	// Interfaces are the names of the bonded interfaces, or globs, e.g. eno*.
+	Interfaces []string `json:"-" yaml:"interfaces"`
+	// Mode is the bonding mode, 802.3ad if empty.
+	Mode string `json:"-" yaml:"mode,omitempty"`
+}
+
+// VLAN is a VLAN interface, named <link>.<id>.
+type VLAN struct {
+	ID int `json:"-" yaml:"id"`
+	// Link is the interface, e.g. a bond, the VLAN is on.
+	Link string `json:"-" yaml:"link"`
+}
+
+// Name returns the name of the VLAN interface.
+func (v VLAN) Name() string {
+	return fmt.Sprintf("%s.%d", v.Link, v.ID)
+}
+
 // Master converts master related config.
 type Master struct {
-	Count     int      `json:"tectonic_master_count,omitempty" yaml:"-"`
-	NodePools []string `json:"-" yaml:"nodePools"`
+	Count       int         `json:"tectonic_master_count,omitempty" yaml:"-"`
+	HostNetwork HostNetwork `json:"-" yaml:"hostNetwork,omitempty"`
+	NodePools   []string    `json:"-" yaml:"nodePools"`
 }
 
 // Networking converts networking related config.
 type Networking struct {
 	Type        tectonicnetwork.NetworkType `json:"tectonic_networking,omitempty" yaml:"type,omitempty"`
 	MTU         string                      `json:"-" yaml:"mtu,omitempty"`
+	MachineCIDR string                      `json:"-" yaml:"machineCIDR,omitempty"`
 	ServiceCIDR string                      `json:"tectonic_service_cidr,omitempty" yaml:"serviceCIDR,omitempty"`
 	PodCIDR     string                      `json:"tectonic_cluster_cidr,omitempty" yaml:"podCIDR,omitempty"`
+	NTPServers  []string                    `json:"tectonic_ntp_servers,omitempty" yaml:"ntpServers,omitempty"`
+	APIPort     int                         `json:"tectonic_api_port,omitempty" yaml:"apiPort,omitempty"`
+}
+
+// TLS converts TLS related config.
+type TLS struct {
+	AdditionalTrustBundlePath string        `json:"tectonic_tls_additional_trust_bundle,omitempty" yaml:"additionalTrustBundlePath,omitempty"`
+	CAValidity                time.Duration `json:"-" yaml:"caValidity,omitempty"`
+	CAValidityHours           int           `json:"tectonic_tls_ca_validity_hours,omitempty" yaml:"-"`
+	CertValidity              time.Duration `json:"-" yaml:"certValidity,omitempty"`
+	CertValidityHours         int           `json:"tectonic_tls_cert_validity_hours,omitempty" yaml:"-"`
+	ExtraSANs                 []string      `json:"-" yaml:"extraSANs,omitempty"`
+	ExtraDNSNames             []string      `json:"tectonic_tls_extra_dns_names,omitempty" yaml:"-"`
+	ExtraIPAddresses          []string      `json:"tectonic_tls_extra_ip_addresses,omitempty" yaml:"-"`
+	IgnitionCABundlePath      string        `json:"-" yaml:"ignitionCABundlePath,omitempty"`
+	KeySize                   int           `json:"tectonic_tls_rsa_bits,omitempty" yaml:"keySize,omitempty"`
+}
+
+// Update converts update related config.
+type Update struct {
+	Channel string `json:"tectonic_update_channel,omitempty" yaml:"channel,omitempty"`
+	Server  string `json:"tectonic_update_server,omitempty" yaml:"server,omitempty"`
+}
+
+// Stats configures the reporting of anonymous usage stats by the cluster.
+type Stats struct {
+	// Disabled, if set, renders no stats URL, so that the cluster reports no
+	// stats, e.g. in disconnected or privacy-sensitive deployments.
+	Disabled bool   `json:"-" yaml:"disabled,omitempty"`
+	URL      string `json:"-" yaml:"url,omitempty"`
 }
 
 // Worker converts worker related config.
 type Worker struct {
-	Count     int      `json:"tectonic_worker_count,omitempty" yaml:"-"`
-	NodePools []string `json:"-" yaml:"nodePools"`
+	Count       int         `json:"tectonic_worker_count" yaml:"-"`
+	HostNetwork HostNetwork `json:"-" yaml:"hostNetwork,omitempty"`
+	NodePools   []string    `json:"-" yaml:"nodePools"`
 }
 
 // Internal converts internal related config.
 type Internal struct {
-	ClusterID string `json:"tectonic_cluster_id,omitempty" yaml:"clusterId"`
+	// AdminPassword is the generated admin password, used when the config has none.
+	AdminPassword string `json:"-" yaml:"adminPassword,omitempty"`
+	ClusterID     string `json:"tectonic_cluster_id,omitempty" yaml:"clusterId"`
 }
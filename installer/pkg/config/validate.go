@@ -4,10 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/openshift/installer/installer/pkg/config/aws"
+	"github.com/openshift/installer/installer/pkg/config/libvirt"
 	"github.com/openshift/installer/installer/pkg/validate"
 
 	log "github.com/Sirupsen/logrus"
@@ -16,11 +24,14 @@ import (
 )
 
 const (
+	maxAWSTags            = 50
 	maxS3BucketNameLength = 63
 )
 
 var (
 	qcowMagic = []byte{'Q', 'F', 'I', 0xfb}
+	// clusterIDRegexp matches the format of the generated cluster IDs.
+	clusterIDRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
 )
 
 // ErrUnmatchedNodePool is returned when a nodePool was specified but not found in the nodePools list.
@@ -75,29 +86,86 @@ func (e *ErrInvalidIgnConfig) Error() string {
 	return fmt.Sprintf("failed to parse ignition file %s: %s", e.filePath, e.rpt)
 }
 
+// Codes of the classes of cluster config errors. They are stable, so that
+// wrappers can map them to documentation or remediations.
+const (
+	ErrCodeNodePools       = "config.nodePools"
+	ErrCodeIgnitionFiles   = "config.ignitionFiles"
+	ErrCodeNetworking      = "config.networking"
+	ErrCodeAWS             = "config.aws"
+	ErrCodeContainerLinux  = "config.containerLinux"
+	ErrCodeContainerImages = "config.containerImages"
+	ErrCodeFiles           = "config.files"
+	ErrCodeHooks           = "config.hooks"
+	ErrCodeLibvirt         = "config.libvirt"
+	ErrCodeCA              = "config.CA"
+	ErrCodeTLS             = "config.tls"
+	ErrCodeAdmin           = "config.admin"
+	ErrCodeUpdate          = "config.update"
+	ErrCodeStats           = "config.stats"
+	ErrCodeHostNetwork     = "config.hostNetwork"
+	ErrCodeName            = "config.name"
+	ErrCodeDomains         = "config.domains"
+	ErrCodeClusterID       = "config.clusterID"
+)
+
+// ValidationError is an error of the cluster config, with the code of its class.
+type ValidationError struct {
+	Code string
+	Err  error
+}
+
+// ValidationError implements the error interface.
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
 // Validate ensures that the Cluster is semantically correct and returns an error if not.
 func (c *Cluster) Validate() []error {
 	var errs []error
-	errs = append(errs, c.validateNodePools()...)
-	errs = append(errs, c.validateIgnitionFiles()...)
-	errs = append(errs, c.validateNetworking()...)
-	errs = append(errs, c.validateAWS()...)
-	errs = append(errs, c.validateCL()...)
-	errs = append(errs, c.validateTectonicFiles()...)
-	errs = append(errs, c.validateLibvirt()...)
-	errs = append(errs, c.validateCA()...)
-	if err := validate.PrefixError("cluster name", validate.ClusterName(c.Name)); err != nil {
-		errs = append(errs, err)
+	for _, err := range c.ValidationErrors() {
+		errs = append(errs, err.Err)
 	}
-	if err := validate.PrefixError("base domain", validate.DomainName(c.BaseDomain)); err != nil {
-		errs = append(errs, err)
+	return errs
+}
+
+// ValidationErrors returns the errors of Validate with their codes.
+func (c *Cluster) ValidationErrors() []*ValidationError {
+	var errs []*ValidationError
+	add := func(code string, codeErrs ...error) {
+		for _, err := range codeErrs {
+			if err != nil {
+				errs = append(errs, &ValidationError{Code: code, Err: err})
+			}
+		}
 	}
-	if err := validate.PrefixError("admin password", validate.NonEmpty(c.Admin.Password)); err != nil {
-		errs = append(errs, err)
+	add(ErrCodeNodePools, c.validateNodePools()...)
+	add(ErrCodeIgnitionFiles, c.validateIgnitionFiles()...)
+	add(ErrCodeNetworking, c.validateNetworking()...)
+	add(ErrCodeAWS, c.validateAWS()...)
+	add(ErrCodeContainerLinux, c.validateCL()...)
+	add(ErrCodeContainerImages, c.validateContainerImages()...)
+	add(ErrCodeFiles, c.validateTectonicFiles()...)
+	add(ErrCodeHooks, c.validateHooks()...)
+	add(ErrCodeLibvirt, c.validateLibvirt()...)
+	add(ErrCodeCA, c.validateCA()...)
+	add(ErrCodeTLS, c.validateTLS()...)
+	add(ErrCodeAdmin, c.validateAdminCert()...)
+	add(ErrCodeUpdate, c.validateUpdate()...)
+	add(ErrCodeStats, c.validateStats()...)
+	add(ErrCodeHostNetwork, c.validateHostNetwork("master", c.Master.HostNetwork)...)
+	add(ErrCodeHostNetwork, c.validateHostNetwork("worker", c.Worker.HostNetwork)...)
+	add(ErrCodeTLS, c.validateTLSKeySize())
+	add(ErrCodeName, validate.PrefixError("cluster name", validate.ClusterName(c.Name)))
+	add(ErrCodeDomains, validate.PrefixError("base domain", validate.DomainName(c.BaseDomain)))
+	add(ErrCodeClusterID, c.validateClusterID())
+	if c.ClusterDomain != "" {
+		add(ErrCodeDomains, c.validateClusterDomain())
 	}
-	if err := validate.PrefixError("admin email", validate.Email(c.Admin.Email)); err != nil {
-		errs = append(errs, err)
+	if c.IngressDomain != "" {
+		add(ErrCodeDomains, validate.PrefixError("ingress domain", validate.DomainName(c.IngressDomain)))
 	}
+	add(ErrCodeAdmin, validate.PrefixError("admin email", validate.Email(c.Admin.Email)))
 	return errs
 }
 
@@ -110,6 +178,12 @@ func (c *Cluster) validateAWS() []error {
 	if err := c.validateAWSEndpoints(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.validateAWSAPIExternalLoadBalancerType(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, c.validateAWSServiceEndpoints()...)
+	errs = append(errs, c.validateAWSExtraTags()...)
+	errs = append(errs, c.validateAWSCloudConfig()...)
 	if err := c.validateTNCS3Bucket(); err != nil {
 		errs = append(errs, err)
 	}
@@ -120,9 +194,37 @@ func (c *Cluster) validateAWS() []error {
 	if err := validate.PrefixError("aws profile", validate.NonEmpty(c.AWS.Profile)); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.validateAWSProfile(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := validate.PrefixError("aws region", validate.NonEmpty(c.AWS.Region)); err != nil {
 		errs = append(errs, err)
 	}
+	errs = append(errs, c.validateAWSVPCDNSServers()...)
+	if c.AWS.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("aws maxRetries must not be negative, got %d", c.AWS.MaxRetries))
+	}
+	return errs
+}
+
+// validateAWSVPCDNSServers ensures that the DNS servers of the VPC are IPv4 addresses, no more than
+// a DHCP options set holds, and that the VPC is created by the installer.
+func (c *Cluster) validateAWSVPCDNSServers() []error {
+	var errs []error
+	if len(c.AWS.VPCDNSServers) == 0 {
+		return errs
+	}
+	if c.AWS.External.VPCID != "" {
+		errs = append(errs, errors.New("aws vpcDNSServers cannot be set with an external VPC, set them in the DHCP options of the VPC instead"))
+	}
+	if len(c.AWS.VPCDNSServers) > aws.MaxVPCDNSServers {
+		errs = append(errs, fmt.Errorf("aws vpcDNSServers can have at most %d servers, got %d", aws.MaxVPCDNSServers, len(c.AWS.VPCDNSServers)))
+	}
+	for i, server := range c.AWS.VPCDNSServers {
+		if err := validate.PrefixError(fmt.Sprintf("aws vpcDNSServers[%d]", i), validate.IPv4(server)); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	return errs
 }
 
@@ -145,6 +247,17 @@ func (c *Cluster) validateCL() []error {
 	return errs
 }
 
+// validateContainerImages validates the container image overrides.
+func (c *Cluster) validateContainerImages() []error {
+	var errs []error
+	for name, image := range c.ContainerImages {
+		if err := validate.PrefixError(fmt.Sprintf("containerImages %q", name), validate.NonEmpty(image)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // validateOverlapWithPodOrServiceCIDR ensures that the given CIDR does not
 // overlap with the pod or service CIDRs of the cluster config.
 func (c *Cluster) validateOverlapWithPodOrServiceCIDR(cidr, name string) []error {
@@ -158,6 +271,46 @@ func (c *Cluster) validateOverlapWithPodOrServiceCIDR(cidr, name string) []error
 	return errs
 }
 
+// validateAWSProfile checks that a named AWS profile is defined in the shared
+// credentials or config file, where the AWS SDK looks it up. The default
+// profile may be left undefined, e.g. for credentials from the environment.
+func (c *Cluster) validateAWSProfile() error {
+	if c.AWS.Profile == "" || c.AWS.Profile == aws.DefaultProfile {
+		return nil
+	}
+	home := os.Getenv("HOME")
+	files := []struct {
+		path string
+		// the config file prefixes the section of named profiles with "profile "
+		prefix string
+	}{
+		{path: envOrDefault("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, ".aws", "credentials"))},
+		{path: envOrDefault("AWS_CONFIG_FILE", filepath.Join(home, ".aws", "config")), prefix: "profile "},
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.path)
+		data, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == fmt.Sprintf("[%s%s]", f.prefix, c.AWS.Profile) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("aws profile %q is not defined in %s", c.AWS.Profile, strings.Join(paths, " nor "))
+}
+
+// envOrDefault returns the value of the environment variable, or def if it is unset.
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
 // validateLibvirt validates all fields specific to libvirt.
 func (c *Cluster) validateLibvirt() []error {
 	var errs []error
@@ -177,13 +330,13 @@ func (c *Cluster) validateLibvirt() []error {
 			}
 		}
 	}
-	if err := validate.PrefixError("libvirt uri", validate.NonEmpty(c.Libvirt.URI)); err != nil {
+	if err := validate.PrefixError("libvirt uri", validate.LibvirtURI(c.Libvirt.URI)); err != nil {
 		errs = append(errs, err)
 	}
 	if err := validate.PrefixError("libvirt imagePath is not a valid QCOW image", validate.FileHeader(c.Libvirt.QCOWImagePath, qcowMagic)); err != nil {
 		errs = append(errs, err)
 	}
-	if err := validate.PrefixError("libvirt sshKey", validate.NonEmpty(c.Libvirt.SSHKey)); err != nil {
+	if err := validate.PrefixError("libvirt sshKey", validate.OpenSSHPublicKey(c.Libvirt.SSHKey)); err != nil {
 		errs = append(errs, err)
 	}
 	if err := validate.PrefixError("libvirt network name", validate.NonEmpty(c.Libvirt.Network.Name)); err != nil {
@@ -195,10 +348,171 @@ func (c *Cluster) validateLibvirt() []error {
 	if err := validate.PrefixError("libvirt network dnsServer", validate.IPv4(c.Libvirt.Network.DNSServer)); err != nil {
 		errs = append(errs, err)
 	}
+	errs = append(errs, c.validateLibvirtDNSHosts()...)
+	errs = append(errs, c.validateLibvirtSizes()...)
+	if err := c.validateLibvirtFirmware(); err != nil {
+		errs = append(errs, err)
+	}
 	errs = append(errs, c.validateOverlapWithPodOrServiceCIDR(c.Libvirt.Network.IPRange, "libvirt ipRange")...)
 	return errs
 }
 
+// validateLibvirtFirmware checks the firmware type, and that the UEFI paths are only set for UEFI.
+// The paths are those on the libvirt host, so they cannot be checked here.
+func (c *Cluster) validateLibvirtFirmware() error {
+	switch c.Libvirt.Firmware.Type {
+	case "", libvirt.FirmwareBIOS:
+		if c.Libvirt.Firmware.Loader != "" || c.Libvirt.Firmware.NVRAMTemplate != "" {
+			return fmt.Errorf("libvirt firmware loader and nvramTemplate require the %q firmware type", libvirt.FirmwareUEFI)
+		}
+	case libvirt.FirmwareUEFI:
+	default:
+		return fmt.Errorf("invalid libvirt firmware type %q, must be %q or %q", c.Libvirt.Firmware.Type, libvirt.FirmwareBIOS, libvirt.FirmwareUEFI)
+	}
+	return nil
+}
+
+// validateLibvirtSizes checks that the memory, vCPU and disk sizes of the libvirt domains are not negative.
+// Zero keeps the default size.
+func (c *Cluster) validateLibvirtSizes() []error {
+	var errs []error
+	sizes := []struct {
+		field string
+		value int
+	}{
+		{"bootstrapDiskSize", c.Libvirt.BootstrapDiskSize},
+		{"bootstrapMemory", c.Libvirt.BootstrapMemory},
+		{"bootstrapVCPU", c.Libvirt.BootstrapVCPU},
+		{"etcd diskSize", c.Libvirt.Etcd.DiskSize},
+		{"etcd memory", c.Libvirt.Etcd.Memory},
+		{"etcd vcpu", c.Libvirt.Etcd.VCPU},
+		{"master diskSize", c.Libvirt.Master.DiskSize},
+		{"master memory", c.Libvirt.Master.Memory},
+		{"master vcpu", c.Libvirt.Master.VCPU},
+		{"worker diskSize", c.Libvirt.Worker.DiskSize},
+		{"worker memory", c.Libvirt.Worker.Memory},
+		{"worker vcpu", c.Libvirt.Worker.VCPU},
+	}
+	for _, s := range sizes {
+		if s.value < 0 {
+			errs = append(errs, fmt.Errorf("libvirt %s must not be negative, got %d", s.field, s.value))
+		}
+	}
+	return errs
+}
+
+// validateLibvirtDNSHosts validates the extra host records of the libvirt network.
+// libvirt refuses a host record whose IP is already used by another one.
+func (c *Cluster) validateLibvirtDNSHosts() []error {
+	var errs []error
+	hosts := make([]string, 0, len(c.Libvirt.Network.DNSHosts))
+	for host := range c.Libvirt.Network.DNSHosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	seen := map[string]string{}
+	for _, host := range hosts {
+		ip := c.Libvirt.Network.DNSHosts[host]
+		if err := validate.PrefixError(fmt.Sprintf("libvirt network dnsHosts %q", host), validate.DomainName(host)); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validate.PrefixError(fmt.Sprintf("libvirt network dnsHosts %q IP", host), validate.IPv4(ip)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[ip]; ok {
+			errs = append(errs, fmt.Errorf("libvirt network dnsHosts %q and %q both resolve to %s, only one host record per IP is supported", other, host, ip))
+			continue
+		}
+		seen[ip] = host
+	}
+	return errs
+}
+
+// validateMachineCIDR ensures that the machine CIDR, if set, is the network
+// of the nodes of the platform, i.e. that it is not set differently too.
+// Its overlap with the pod and service CIDRs is validated with the platform.
+func (c *Cluster) validateMachineCIDR() error {
+	if c.Networking.MachineCIDR == "" {
+		return nil
+	}
+	if err := validate.PrefixError("machineCIDR", validate.SubnetCIDR(c.Networking.MachineCIDR)); err != nil {
+		return err
+	}
+	if cidr, name := c.platformMachineCIDR(); name != "" && cidr != c.Networking.MachineCIDR {
+		return fmt.Errorf("machineCIDR %s differs from %s %s; set only one of them", c.Networking.MachineCIDR, name, cidr)
+	}
+	return nil
+}
+
+// bondModes are the bonding modes of the Linux bonding driver.
+var bondModes = map[string]bool{
+	"balance-rr":    true,
+	"active-backup": true,
+	"balance-xor":   true,
+	"broadcast":     true,
+	"802.3ad":       true,
+	"balance-tlb":   true,
+	"balance-alb":   true,
+}
+
+// interfaceNameRegexp matches the names Linux accepts for network interfaces,
+// which are at most 15 characters long.
+var interfaceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,14}$`)
+
+// validateHostNetwork validates the bonds and VLANs of the nodes of the given role.
+// The cloud platforms attach their network interfaces themselves, so they
+// can only be configured on the others.
+func (c *Cluster) validateHostNetwork(role string, n HostNetwork) []error {
+	var errs []error
+	if len(n.Bonds) == 0 && len(n.VLANs) == 0 {
+		return errs
+	}
+	if c.Platform == PlatformAWS {
+		return append(errs, fmt.Errorf("%s hostNetwork cannot be set on %s", role, c.Platform))
+	}
+
+	names := map[string]bool{}
+	bonded := map[string]string{}
+	for i, b := range n.Bonds {
+		prefix := fmt.Sprintf("%s hostNetwork bonds[%d]", role, i)
+		if !interfaceNameRegexp.MatchString(b.Name) {
+			errs = append(errs, fmt.Errorf("%s: invalid name %q: must be an interface name of at most 15 characters", prefix, b.Name))
+		} else if names[b.Name] {
+			errs = append(errs, fmt.Errorf("%s: interface %s declared more than once", prefix, b.Name))
+		}
+		names[b.Name] = true
+		if b.Mode != "" && !bondModes[b.Mode] {
+			errs = append(errs, fmt.Errorf("%s: invalid mode %q", prefix, b.Mode))
+		}
+		if len(b.Interfaces) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no interfaces to bond", prefix))
+		}
+		for _, iface := range b.Interfaces {
+			if other, ok := bonded[iface]; ok {
+				errs = append(errs, fmt.Errorf("%s: interface %s is already bonded by %s", prefix, iface, other))
+			}
+			bonded[iface] = b.Name
+		}
+	}
+	for i, v := range n.VLANs {
+		prefix := fmt.Sprintf("%s hostNetwork vlans[%d]", role, i)
+		if v.ID < 1 || v.ID > 4094 {
+			errs = append(errs, fmt.Errorf("%s: invalid id %d: must be between 1 and 4094", prefix, v.ID))
+		}
+		if other, ok := bonded[v.Link]; ok {
+			errs = append(errs, fmt.Errorf("%s: link %s is bonded by %s; use the bond instead", prefix, v.Link, other))
+		}
+		if !interfaceNameRegexp.MatchString(v.Name()) {
+			errs = append(errs, fmt.Errorf("%s: invalid interface name %q: the link must be an interface name, and the VLAN name at most 15 characters", prefix, v.Name()))
+		} else if names[v.Name()] {
+			errs = append(errs, fmt.Errorf("%s: interface %s declared more than once", prefix, v.Name()))
+		}
+		names[v.Name()] = true
+	}
+	return errs
+}
+
 func (c *Cluster) validateNetworking() []error {
 	var errs []error
 	// https://en.wikipedia.org/wiki/Maximum_transmission_unit#MTUs_for_common_media
@@ -214,9 +528,23 @@ func (c *Cluster) validateNetworking() []error {
 	if err := c.validateNetworkType(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.validateAPIPort(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := validate.PrefixError("pod and service CIDRs", validate.CIDRsDontOverlap(c.Networking.PodCIDR, c.Networking.ServiceCIDR)); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.validateMachineCIDR(); err != nil {
+		errs = append(errs, err)
+	}
+	for i, server := range c.Networking.NTPServers {
+		if net.ParseIP(server) != nil {
+			continue
+		}
+		if err := validate.PrefixError(fmt.Sprintf("ntpServers[%d] %q", i, server), validate.DomainName(server)); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	return errs
 }
 
@@ -236,17 +564,17 @@ func (c *Cluster) validateNetworkType() error {
 }
 
 // ValidateAndLog performs cluster configuration validation using `Validate`
-// but rather than return a slice of errors, it logs any errors and returns
-// a single error for convenience.
+// but rather than return a slice of errors, it logs any errors, with their
+// codes, and returns a single error for convenience.
 func (c *Cluster) ValidateAndLog() error {
-	if errs := c.Validate(); len(errs) != 0 {
+	if errs := c.ValidationErrors(); len(errs) != 0 {
 		s := ""
 		if len(errs) != 1 {
 			s = "s"
 		}
 		log.Errorf("Found %d error%s in the cluster definition:", len(errs), s)
 		for i, err := range errs {
-			log.Errorf("error %d: %v", i+1, err)
+			log.WithField("code", err.Code).Errorf("error %d: %v", i+1, err)
 		}
 		return fmt.Errorf("found %d cluster definition error%s", len(errs), s)
 	}
@@ -268,12 +596,101 @@ func (c *Cluster) validateAWSEndpoints() error {
 	}
 }
 
+// validateAWSAPIExternalLoadBalancerType ensures that the type of the external API load balancer
+// is empty, meaning classic, 'classic' or 'network'.
+func (c *Cluster) validateAWSAPIExternalLoadBalancerType() error {
+	switch c.AWS.APIExternalLoadBalancerType {
+	case "", aws.LoadBalancerClassic, aws.LoadBalancerNetwork:
+		return nil
+	}
+	return fmt.Errorf("invalid AWS apiExternalLoadBalancerType %q; must be %q or %q", c.AWS.APIExternalLoadBalancerType, aws.LoadBalancerClassic, aws.LoadBalancerNetwork)
+}
+
+// validateAWSServiceEndpoints ensures that the services of the endpoint overrides are known
+// and that the endpoints are URLs.
+func (c *Cluster) validateAWSServiceEndpoints() []error {
+	var errs []error
+	for service, endpoint := range c.AWS.ServiceEndpoints {
+		switch service {
+		case aws.ServiceEC2, aws.ServiceELB, aws.ServiceIAM, aws.ServiceRoute53, aws.ServiceS3, aws.ServiceSTS:
+		default:
+			errs = append(errs, fmt.Errorf("invalid AWS service %q in serviceEndpoints; must be one of %s", service, []string{aws.ServiceEC2, aws.ServiceELB, aws.ServiceIAM, aws.ServiceRoute53, aws.ServiceS3, aws.ServiceSTS}))
+			continue
+		}
+		if err := validate.PrefixError(fmt.Sprintf("aws serviceEndpoints %q", service), validate.URL(endpoint)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateAWSCloudConfig validates the settings of the AWS cloud provider.
+func (c *Cluster) validateAWSCloudConfig() []error {
+	var errs []error
+	cc := c.AWS.CloudConfig
+	if cc.ELBSecurityGroup != "" && !regexp.MustCompile(`^sg-[0-9a-f]+$`).MatchString(cc.ELBSecurityGroup) {
+		errs = append(errs, fmt.Errorf("invalid aws cloudConfig elbSecurityGroup %q: must be a security group ID, e.g. sg-0123abcd", cc.ELBSecurityGroup))
+	}
+	if cc.RoleARN != "" && !strings.HasPrefix(cc.RoleARN, "arn:") {
+		errs = append(errs, fmt.Errorf("invalid aws cloudConfig roleARN %q: must be an IAM role ARN", cc.RoleARN))
+	}
+	return errs
+}
+
+// validateAWSExtraTags ensures that the extra tags respect the AWS tag restrictions
+// and do not override the tags the installer sets on every resource.
+// See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/Using_Tags.html#tag-restrictions
+func (c *Cluster) validateAWSExtraTags() []error {
+	var errs []error
+	// the installer sets Name, tectonicClusterID and kubernetes.io/cluster/<name> itself
+	if len(c.AWS.ExtraTags) > maxAWSTags-3 {
+		errs = append(errs, fmt.Errorf("aws extraTags has %d tags; at most %d are allowed", len(c.AWS.ExtraTags), maxAWSTags-3))
+	}
+	for k, v := range c.AWS.ExtraTags {
+		switch {
+		case len(k) == 0 || utf8.RuneCountInString(k) > 127:
+			errs = append(errs, fmt.Errorf("aws extraTags key %q must be between 1 and 127 characters long", k))
+		case utf8.RuneCountInString(v) > 255:
+			errs = append(errs, fmt.Errorf("aws extraTags value of %q must be at most 255 characters long", k))
+		case strings.HasPrefix(strings.ToLower(k), "aws:"):
+			errs = append(errs, fmt.Errorf("aws extraTags key %q must not use the reserved aws: prefix", k))
+		case k == "Name" || k == "tectonicClusterID" || strings.HasPrefix(k, "kubernetes.io/cluster/"):
+			errs = append(errs, fmt.Errorf("aws extraTags key %q is set by the installer and cannot be overridden", k))
+		}
+	}
+	return errs
+}
+
+// validateClusterID ensures that at most one of the cluster ID and its seed is
+// set, and that the cluster ID has the format of the generated ones.
+func (c *Cluster) validateClusterID() error {
+	if c.RequestedClusterID != "" && c.ClusterIDSeed != "" {
+		return errors.New("only one of clusterID and clusterIDSeed may be set")
+	}
+	if c.RequestedClusterID != "" && !clusterIDRegexp.MatchString(c.RequestedClusterID) {
+		return fmt.Errorf("clusterID %q must be a lowercase UUID, e.g. 0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d", c.RequestedClusterID)
+	}
+	return nil
+}
+
+// validateClusterDomain ensures that the cluster domain is the base domain or
+// one of its subdomains, since its records are created in the base domain zone.
+func (c *Cluster) validateClusterDomain() error {
+	if err := validate.PrefixError("cluster domain", validate.DomainName(c.ClusterDomain)); err != nil {
+		return err
+	}
+	if c.ClusterDomain != c.BaseDomain && !strings.HasSuffix(c.ClusterDomain, "."+c.BaseDomain) {
+		return fmt.Errorf("cluster domain %q must be the base domain %q or one of its subdomains", c.ClusterDomain, c.BaseDomain)
+	}
+	return nil
+}
+
 // validateTNCS3Bucket does some basic validation to ensure that the TNC bucket
 // matches the S3 bucket naming rules. Not all rules are checked
 // because Tectonic controls the generation of S3 bucket names, creating
 // buckets of the form: <cluster-name>-<tnc>.<domain-name>
 func (c *Cluster) validateTNCS3Bucket() error {
-	bucket := fmt.Sprintf("%s-tnc.%s", c.Name, c.BaseDomain)
+	bucket := fmt.Sprintf("%s-tnc.%s", c.Name, c.DNSDomain())
 	if len(bucket) > maxS3BucketNameLength {
 		return fmt.Errorf("the S3 bucket name %q, generated from the cluster name and base domain, is too long; S3 bucket names must be less than 63 characters; please choose a shorter cluster name or base domain", bucket)
 	}
@@ -288,12 +705,39 @@ func (c *Cluster) validateTectonicFiles() []error {
 	if err := validate.JSONFile(c.PullSecretPath); err != nil {
 		errs = append(errs, err)
 	}
+	if len(c.AdditionalPullSecretPaths) > 0 {
+		if _, err := MergePullSecrets(c.PullSecretPaths()); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if err := validate.License(c.LicensePath); err != nil {
 		errs = append(errs, err)
 	}
 	return errs
 }
 
+func (c *Cluster) validateHooks() []error {
+	var errs []error
+	hooks := []struct {
+		name string
+		path string
+	}{
+		{"preManifests", c.Hooks.PreManifests},
+		{"postManifests", c.Hooks.PostManifests},
+		{"preProvision", c.Hooks.PreProvision},
+		{"postInstall", c.Hooks.PostInstall},
+	}
+	for _, h := range hooks {
+		if h.path == "" {
+			continue
+		}
+		if err := validate.PrefixError("hooks "+h.name, validate.Executable(h.path)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 func (c *Cluster) validateIgnitionFiles() []error {
 	var errs []error
 	for _, n := range c.NodePools {
@@ -361,6 +805,12 @@ func (c *Cluster) validateNodePools() []error {
 
 	errs = append(errs, c.validateNoSharedNodePools()...)
 
+	// without workers, the workloads run on the master, which is only
+	// left schedulable on a single-node cluster
+	if c.NodeCount(c.Worker.NodePools) == 0 && c.NodeCount(c.Master.NodePools) > 1 {
+		errs = append(errs, errors.New("a cluster without workers must have a single master"))
+	}
+
 	return errs
 }
 
@@ -416,6 +866,22 @@ func (c *Cluster) validateCA() []error {
 	var errs []error
 
 	switch {
+	case c.CA.RootCASignerCommand != "":
+		if c.CA.RootCAKeyPath != "" {
+			errs = append(errs, fmt.Errorf("rootCAKeyPath and rootCASignerCommand cannot both be set"))
+		}
+		if c.CA.RootCACertPath == "" {
+			errs = append(errs, fmt.Errorf("rootCACertPath must be set with rootCASignerCommand"))
+		}
+		if _, err := exec.LookPath(c.CA.RootCASignerCommand); err != nil {
+			errs = append(errs, fmt.Errorf("invalid rootCASignerCommand: %v", err))
+		}
+		if len(errs) == 0 {
+			if err := validateCACert(c.CA.RootCACertPath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errs
 	case (c.CA.RootCACertPath == "") != (c.CA.RootCAKeyPath == ""):
 		errs = append(errs, fmt.Errorf("rootCACertPath and rootCAKeyPath must both be set or empty"))
 	case c.CA.RootCAKeyPath != "":
@@ -434,6 +900,141 @@ func (c *Cluster) validateCA() []error {
 			errs = append(errs, err)
 		}
 	}
+	if len(errs) == 0 && c.CA.RootCACertPath != "" {
+		if err := validateCAKeyPair(c.CA.RootCACertPath, c.CA.RootCAKeyPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateTLS validates the validity periods of the generated CAs and certificates.
+func (c *Cluster) validateTLS() []error {
+	var errs []error
+	if c.TLS.CAValidity <= 0 {
+		errs = append(errs, fmt.Errorf("tls caValidity must be a positive duration, got %s", c.TLS.CAValidity))
+	}
+	if c.TLS.CertValidity <= 0 {
+		errs = append(errs, fmt.Errorf("tls certValidity must be a positive duration, got %s", c.TLS.CertValidity))
+	}
+	if c.TLS.CertValidity > c.TLS.CAValidity {
+		errs = append(errs, fmt.Errorf("tls certValidity (%s) cannot be longer than caValidity (%s)", c.TLS.CertValidity, c.TLS.CAValidity))
+	}
+	for i, san := range c.TLS.ExtraSANs {
+		if net.ParseIP(san) != nil {
+			continue
+		}
+		if err := validate.PrefixError(fmt.Sprintf("tls extraSANs[%d] %q", i, san), validate.DomainName(san)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.validateAdditionalTrustBundle()...)
+	errs = append(errs, c.validateIgnitionCABundle()...)
+	return errs
+}
+
+// validateAPIPort checks that the API port is a valid TCP port.
+// Only the AWS load balancers can expose the API on another port than the one
+// the API servers listen on, so other platforms must keep the default.
+func (c *Cluster) validateAPIPort() error {
+	if c.Networking.APIPort < 1 || c.Networking.APIPort > 65535 {
+		return fmt.Errorf("invalid apiPort %d: must be between 1 and 65535", c.Networking.APIPort)
+	}
+	if c.Platform != PlatformAWS && c.Networking.APIPort != DefaultAPIPort {
+		return fmt.Errorf("apiPort can only be changed on %s, the API is served directly on port %d on %s", PlatformAWS, DefaultAPIPort, c.Platform)
+	}
+	return nil
+}
+
+// validateTLSKeySize checks that the generated RSA keys have a size commonly accepted by security policies.
+func (c *Cluster) validateTLSKeySize() error {
+	switch c.TLS.KeySize {
+	case 2048, 3072, 4096:
+		return nil
+	}
+	return fmt.Errorf("tls keySize must be 2048, 3072 or 4096, got %d", c.TLS.KeySize)
+}
+
+// validateUpdate validates the update channel and server overrides.
+func (c *Cluster) validateUpdate() []error {
+	var errs []error
+	if c.Update.Channel != "" {
+		if err := validate.PrefixError("update channel", validate.NonEmpty(c.Update.Channel)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Update.Server != "" {
+		if err := validate.PrefixError("update server", validate.URL(c.Update.Server)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (c *Cluster) validateStats() []error {
+	var errs []error
+	if c.Stats.URL != "" {
+		if err := validate.PrefixError("stats url", validate.URL(c.Stats.URL)); err != nil {
+			errs = append(errs, err)
+		}
+		if c.Stats.Disabled {
+			errs = append(errs, errors.New("stats url cannot be set when stats are disabled"))
+		}
+	}
+	return errs
+}
+
+// validateAdminCert validates the subject and validity period of the client certificate of the admin kubeconfig.
+func (c *Cluster) validateAdminCert() []error {
+	var errs []error
+	if c.Admin.AdminCert.Validity < time.Hour {
+		errs = append(errs, fmt.Errorf("admin cert validity must be at least an hour, got %s", c.Admin.AdminCert.Validity))
+	}
+	if c.Admin.AdminCert.Validity > c.TLS.CAValidity {
+		errs = append(errs, fmt.Errorf("admin cert validity (%s) cannot be longer than tls caValidity (%s)", c.Admin.AdminCert.Validity, c.TLS.CAValidity))
+	}
+	if err := validate.PrefixError("admin cert commonName", validate.NonEmpty(c.Admin.AdminCert.CommonName)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validateAdditionalTrustBundle validates the user supplied CA certificates to be trusted by the nodes.
+func (c *Cluster) validateAdditionalTrustBundle() []error {
+	var errs []error
+	if c.TLS.AdditionalTrustBundlePath == "" {
+		return errs
+	}
+	if err := validate.FileExists(c.TLS.AdditionalTrustBundlePath); err != nil {
+		return append(errs, err)
+	}
+	data, err := ioutil.ReadFile(c.TLS.AdditionalTrustBundlePath)
+	if err != nil {
+		return append(errs, fmt.Errorf("failed to read additional trust bundle file: %v", err))
+	}
+	if err := validate.CertificateBundle(string(data)); err != nil {
+		errs = append(errs, fmt.Errorf("invalid tls additionalTrustBundlePath (%s): %v", c.TLS.AdditionalTrustBundlePath, err))
+	}
+	return errs
+}
+
+// validateIgnitionCABundle validates the user supplied CA certificates trusted by ignition
+// to fetch the remainder of the node configs.
+func (c *Cluster) validateIgnitionCABundle() []error {
+	var errs []error
+	if c.TLS.IgnitionCABundlePath == "" {
+		return errs
+	}
+	if err := validate.FileExists(c.TLS.IgnitionCABundlePath); err != nil {
+		return append(errs, err)
+	}
+	data, err := ioutil.ReadFile(c.TLS.IgnitionCABundlePath)
+	if err != nil {
+		return append(errs, fmt.Errorf("failed to read ignition CA bundle file: %v", err))
+	}
+	if err := validate.CertificateBundle(string(data)); err != nil {
+		errs = append(errs, fmt.Errorf("invalid tls ignitionCABundlePath (%s): %v", c.TLS.IgnitionCABundlePath, err))
+	}
 	return errs
 }
 
@@ -460,9 +1061,26 @@ func validateCACert(path string) error {
 	}
 	pem := string(data)
 
-	// Validate that file content is a valid certificate
-	if err := validate.Certificate(pem); err != nil {
+	// Validate that file content is a valid certificate authority;
+	// intermediate CAs may be followed by the rest of their chain.
+	if err := validate.CertificateAuthority(pem); err != nil {
 		return fmt.Errorf("invalid certificate (%s): %v", path, err)
 	}
 	return nil
 }
+
+// validateCAKeyPair validates that the private key belongs to the certificate
+func validateCAKeyPair(certPath, keyPath string) error {
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return fmt.Errorf("failed to read certificate file: %v", err)
+	}
+	key, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read private key file: %v", err)
+	}
+	if err := validate.KeyPair(string(cert), string(key)); err != nil {
+		return fmt.Errorf("invalid CA key pair (%s, %s): %v", certPath, keyPath, err)
+	}
+	return nil
+}
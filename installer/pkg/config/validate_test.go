@@ -1,12 +1,20 @@
 package config
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/openshift/installer/installer/pkg/config/aws"
 	"github.com/openshift/installer/installer/pkg/config/libvirt"
+	"github.com/openshift/installer/installer/pkg/tls"
 )
 
 func TestMissingNodePool(t *testing.T) {
@@ -335,6 +343,65 @@ func TestSharedNodePool(t *testing.T) {
 	}
 }
 
+func TestSingleNode(t *testing.T) {
+	cases := []struct {
+		masters int
+		workers int
+		err     bool
+	}{
+		{
+			masters: 3,
+			workers: 3,
+			err:     false,
+		},
+		{
+			masters: 1,
+			workers: 0,
+			err:     false,
+		},
+		{
+			masters: 3,
+			workers: 0,
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{
+			Master: Master{
+				NodePools: []string{"master"},
+			},
+			Worker: Worker{
+				NodePools: []string{"worker"},
+			},
+			Etcd: Etcd{
+				NodePools: []string{"etcd"},
+			},
+			NodePools: NodePools{
+				{
+					Name:  "master",
+					Count: c.masters,
+				},
+				{
+					Name:  "worker",
+					Count: c.workers,
+				},
+				{
+					Name:  "etcd",
+					Count: 1,
+				},
+			},
+		}
+		if errs := cluster.validateNodePools(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
 func TestAWSEndpoints(t *testing.T) {
 	cases := []struct {
 		cluster Cluster
@@ -393,6 +460,114 @@ func TestAWSEndpoints(t *testing.T) {
 	}
 }
 
+func TestAWSServiceEndpoints(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: defaultCluster,
+			err:     false,
+		},
+		{
+			cluster: Cluster{
+				AWS: aws.AWS{
+					ServiceEndpoints: map[string]string{
+						aws.ServiceEC2:     "https://vpce-0123.ec2.eu-west-1.vpce.amazonaws.com",
+						aws.ServiceRoute53: "https://route53.amazonaws.com",
+					},
+				},
+			},
+			err: false,
+		},
+		{
+			cluster: Cluster{
+				AWS: aws.AWS{
+					ServiceEndpoints: map[string]string{"lambda": "https://lambda.eu-west-1.amazonaws.com"},
+				},
+			},
+			err: true,
+		},
+		{
+			cluster: Cluster{
+				AWS: aws.AWS{
+					ServiceEndpoints: map[string]string{aws.ServiceS3: "s3.eu-west-1.amazonaws.com"},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		if errs := c.cluster.validateAWSServiceEndpoints(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestAWSExtraTags(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i < 48; i++ {
+		tooMany[fmt.Sprintf("tag%d", i)] = "value"
+	}
+	cases := []struct {
+		tags map[string]string
+		err  bool
+	}{
+		{
+			tags: nil,
+			err:  false,
+		},
+		{
+			tags: map[string]string{"CostCenter": "1234", "owner": ""},
+			err:  false,
+		},
+		{
+			tags: map[string]string{"": "value"},
+			err:  true,
+		},
+		{
+			tags: map[string]string{strings.Repeat("k", 128): "value"},
+			err:  true,
+		},
+		{
+			tags: map[string]string{"key": strings.Repeat("v", 256)},
+			err:  true,
+		},
+		{
+			tags: map[string]string{"aws:createdBy": "me"},
+			err:  true,
+		},
+		{
+			tags: map[string]string{"tectonicClusterID": "abc"},
+			err:  true,
+		},
+		{
+			tags: map[string]string{"kubernetes.io/cluster/test": "shared"},
+			err:  true,
+		},
+		{
+			tags: tooMany,
+			err:  true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{AWS: aws.AWS{ExtraTags: c.tags}}
+		if errs := cluster.validateAWSExtraTags(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
 func TestTNCS3BucketNames(t *testing.T) {
 	cases := []struct {
 		cluster Cluster
@@ -572,6 +747,44 @@ func TestValidateCL(t *testing.T) {
 	}
 }
 
+func TestValidateContainerImages(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{},
+			err:     false,
+		},
+		{
+			cluster: Cluster{
+				ContainerImages: map[string]string{
+					"tnc_operator": "registry.example.com/openshift/tectonic-node-controller-operator:latest",
+				},
+			},
+			err: false,
+		},
+		{
+			cluster: Cluster{
+				ContainerImages: map[string]string{
+					"tnc_operator": "",
+				},
+			},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		if errs := c.cluster.validateContainerImages(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
 func TestValidateLibvirt(t *testing.T) {
 	fValid, err := ioutil.TempFile("", "qcow")
 	if err != nil {
@@ -629,6 +842,40 @@ func TestValidateLibvirt(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			cluster: Cluster{
+				Libvirt: libvirt.Libvirt{
+					Network: libvirt.Network{
+						Name:      "tectonic",
+						IfName:    libvirt.DefaultIfName,
+						DNSServer: libvirt.DefaultDNSServer,
+						IPRange:   "10.0.1.0/24",
+					},
+					QCOWImagePath: fValid.Name(),
+					SSHKey:        "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAACAQDxL",
+					URI:           "qemu:///system",
+				},
+				Networking: defaultCluster.Networking,
+			},
+			err: false,
+		},
+		{
+			cluster: Cluster{
+				Libvirt: libvirt.Libvirt{
+					Network: libvirt.Network{
+						Name:      "tectonic",
+						IfName:    libvirt.DefaultIfName,
+						DNSServer: libvirt.DefaultDNSServer,
+						IPRange:   "10.0.1.0/24",
+					},
+					QCOWImagePath: fValid.Name(),
+					SSHKey:        "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAACAQDxL",
+					URI:           "baz",
+				},
+				Networking: defaultCluster.Networking,
+			},
+			err: true,
+		},
 		{
 			cluster: Cluster{
 				Libvirt: libvirt.Libvirt{
@@ -644,7 +891,7 @@ func TestValidateLibvirt(t *testing.T) {
 				},
 				Networking: defaultCluster.Networking,
 			},
-			err: false,
+			err: true,
 		},
 		{
 			cluster: Cluster{
@@ -711,6 +958,83 @@ func TestValidateLibvirt(t *testing.T) {
 	}
 }
 
+func TestValidateAWSVPCDNSServers(t *testing.T) {
+	cases := []struct {
+		aws aws.AWS
+		err bool
+	}{
+		{
+			aws: aws.AWS{},
+			err: false,
+		},
+		{
+			aws: aws.AWS{VPCDNSServers: []string{"10.1.0.2", "10.1.0.3"}},
+			err: false,
+		},
+		{
+			aws: aws.AWS{VPCDNSServers: []string{"dns.example.com"}},
+			err: true,
+		},
+		{
+			aws: aws.AWS{VPCDNSServers: []string{"10.1.0.2", "10.1.0.3", "10.1.0.4", "10.1.0.5", "10.1.0.6"}},
+			err: true,
+		},
+		{
+			aws: aws.AWS{
+				External:      aws.External{VPCID: "vpc-123456"},
+				VPCDNSServers: []string{"10.1.0.2"},
+			},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{AWS: c.aws}
+		if errs := cluster.validateAWSVPCDNSServers(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateAWSAPIExternalLoadBalancerType(t *testing.T) {
+	cases := []struct {
+		lbType string
+		err    bool
+	}{
+		{
+			lbType: "",
+			err:    false,
+		},
+		{
+			lbType: aws.LoadBalancerClassic,
+			err:    false,
+		},
+		{
+			lbType: aws.LoadBalancerNetwork,
+			err:    false,
+		},
+		{
+			lbType: "application",
+			err:    true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{AWS: aws.AWS{APIExternalLoadBalancerType: c.lbType}}
+		if err := cluster.validateAWSAPIExternalLoadBalancerType(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
 func TestValidateAWS(t *testing.T) {
 	d1 := defaultCluster
 	d1.Platform = PlatformAWS
@@ -820,3 +1144,860 @@ func TestValidateOverlapWithPodOrServiceCIDR(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMachineCIDR(t *testing.T) {
+	cases := []struct {
+		config   string
+		expected string
+		err      bool
+	}{
+		{
+			config:   "platform: aws\n",
+			expected: aws.DefaultVPCCIDRBlock,
+			err:      false,
+		},
+		{
+			config:   "platform: aws\nnetworking:\n  machineCIDR: 192.168.0.0/16\n",
+			expected: "192.168.0.0/16",
+			err:      false,
+		},
+		{
+			config:   "platform: aws\naws:\n  vpcCIDRBlock: 172.16.0.0/16\nnetworking:\n  machineCIDR: 192.168.0.0/16\n",
+			expected: "172.16.0.0/16",
+			err:      true,
+		},
+		{
+			config:   "platform: libvirt\nnetworking:\n  machineCIDR: 192.168.124.0/24\n",
+			expected: "192.168.124.0/24",
+			err:      false,
+		},
+		{
+			config:   "platform: libvirt\nnetworking:\n  machineCIDR: 192.168.124.0\n",
+			expected: "192.168.124.0",
+			err:      true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster, err := ParseConfig([]byte(c.config))
+		if err != nil {
+			t.Fatalf("test case %d: failed to parse config: %v", i, err)
+		}
+		if cidr, _ := cluster.platformMachineCIDR(); cidr != c.expected {
+			t.Errorf("test case %d: expected machine CIDR %s, got %s", i, c.expected, cidr)
+		}
+		if err := cluster.validateMachineCIDR(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
+func TestValidateNTPServers(t *testing.T) {
+	cases := []struct {
+		servers []string
+		err     bool
+	}{
+		{
+			servers: nil,
+			err:     false,
+		},
+		{
+			servers: []string{"ntp1.example.com", "10.0.0.123", "fd00::123"},
+			err:     false,
+		},
+		{
+			servers: []string{"not a host name"},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.Networking.NTPServers = c.servers
+		if errs := cluster.validateNetworking(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateAdminCert(t *testing.T) {
+	cases := []struct {
+		cert AdminCert
+		err  bool
+	}{
+		{
+			cert: defaultCluster.Admin.AdminCert,
+			err:  false,
+		},
+		{
+			cert: AdminCert{CommonName: "jane", Organization: "cluster-admins", Validity: time.Hour * 8},
+			err:  false,
+		},
+		{
+			cert: AdminCert{CommonName: "", Organization: "system:masters", Validity: time.Hour},
+			err:  true,
+		},
+		{
+			cert: AdminCert{CommonName: "system:admin", Organization: "system:masters", Validity: time.Minute},
+			err:  true,
+		},
+		{
+			cert: AdminCert{CommonName: "system:admin", Organization: "system:masters", Validity: DefaultTLSValidity * 2},
+			err:  true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.Admin.AdminCert = c.cert
+		if errs := cluster.validateAdminCert(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateCASigner(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	dir, err := ioutil.TempDir("", "ca")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	key, err := tls.PrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	cert, err := tls.SelfSignedCACert(&tls.CertCfg{
+		Subject:   pkix.Name{CommonName: "corporate-ca", OrganizationalUnit: []string{"pki"}},
+		KeyUsages: x509.KeyUsageCertSign,
+		Validity:  time.Hour,
+		IsCA:      true,
+	}, key)
+	if err != nil {
+		t.Fatalf("failed to generate CA: %v", err)
+	}
+	certPath := filepath.Join(dir, "ca.crt")
+	keyPath := filepath.Join(dir, "ca.key")
+	if err := ioutil.WriteFile(certPath, []byte(tls.CertToPem(cert)), 0600); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+	if err := ioutil.WriteFile(keyPath, []byte(tls.PrivateKeyToPem(key)), 0600); err != nil {
+		t.Fatalf("failed to write CA key: %v", err)
+	}
+
+	cases := []struct {
+		ca  CA
+		err bool
+	}{
+		{
+			ca:  CA{RootCACertPath: certPath, RootCASignerCommand: sh},
+			err: false,
+		},
+		{
+			ca:  CA{RootCACertPath: certPath, RootCAKeyPath: keyPath},
+			err: false,
+		},
+		{
+			ca:  CA{RootCACertPath: certPath, RootCAKeyPath: keyPath, RootCASignerCommand: sh},
+			err: true,
+		},
+		{
+			ca:  CA{RootCASignerCommand: sh},
+			err: true,
+		},
+		{
+			ca:  CA{RootCACertPath: certPath, RootCASignerCommand: filepath.Join(dir, "missing")},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.CA = c.ca
+		if errs := cluster.validateCA(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{},
+			err:     true,
+		},
+		{
+			cluster: defaultCluster,
+			err:     false,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:   time.Hour * 24,
+					CertValidity: time.Hour,
+				},
+			},
+			err: false,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:   time.Hour,
+					CertValidity: time.Hour * 24,
+				},
+			},
+			err: true,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:   time.Hour,
+					CertValidity: -time.Hour,
+				},
+			},
+			err: true,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:   time.Hour,
+					CertValidity: time.Hour,
+					ExtraSANs:    []string{"api.example.com", "192.168.0.10", "fd00::10"},
+				},
+			},
+			err: false,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:   time.Hour,
+					CertValidity: time.Hour,
+					ExtraSANs:    []string{"not a host name"},
+				},
+			},
+			err: true,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					AdditionalTrustBundlePath: "fixtures/ign.ign",
+					CAValidity:                time.Hour,
+					CertValidity:              time.Hour,
+				},
+			},
+			err: true,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:           time.Hour,
+					CertValidity:         time.Hour,
+					IgnitionCABundlePath: "fixtures/ign.ign",
+				},
+			},
+			err: true,
+		},
+		{
+			cluster: Cluster{
+				TLS: TLS{
+					CAValidity:           time.Hour,
+					CertValidity:         time.Hour,
+					IgnitionCABundlePath: "does-not-exist.crt",
+				},
+			},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		if errs := c.cluster.validateTLS(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateLibvirtSizes(t *testing.T) {
+	cases := []struct {
+		libvirt libvirt.Libvirt
+		err     bool
+	}{
+		{
+			libvirt: libvirt.Libvirt{},
+			err:     false,
+		},
+		{
+			libvirt: libvirt.Libvirt{
+				BootstrapMemory: 4096,
+				BootstrapVCPU:   2,
+				Master:          libvirt.Master{DiskSize: 20, Memory: 3072, VCPU: 2},
+				Worker:          libvirt.Worker{Memory: 2048},
+			},
+			err: false,
+		},
+		{
+			libvirt: libvirt.Libvirt{Etcd: libvirt.Etcd{VCPU: -1}},
+			err:     true,
+		},
+		{
+			libvirt: libvirt.Libvirt{BootstrapDiskSize: -10},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{Libvirt: c.libvirt}
+		if errs := cluster.validateLibvirtSizes(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateLibvirtFirmware(t *testing.T) {
+	cases := []struct {
+		firmware libvirt.Firmware
+		err      bool
+	}{
+		{
+			firmware: libvirt.Firmware{},
+			err:      false,
+		},
+		{
+			firmware: libvirt.Firmware{Type: libvirt.FirmwareBIOS},
+			err:      false,
+		},
+		{
+			firmware: libvirt.Firmware{Type: libvirt.FirmwareUEFI},
+			err:      false,
+		},
+		{
+			firmware: libvirt.Firmware{
+				Type:          libvirt.FirmwareUEFI,
+				Loader:        "/usr/share/edk2/ovmf/OVMF_CODE.secboot.fd",
+				NVRAMTemplate: "/usr/share/edk2/ovmf/OVMF_VARS.secboot.fd",
+			},
+			err: false,
+		},
+		{
+			firmware: libvirt.Firmware{Loader: "/usr/share/edk2/ovmf/OVMF_CODE.fd"},
+			err:      true,
+		},
+		{
+			firmware: libvirt.Firmware{Type: "coreboot"},
+			err:      true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{Libvirt: libvirt.Libvirt{Firmware: c.firmware}}
+		if err := cluster.validateLibvirtFirmware(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
+func TestValidateAPIPort(t *testing.T) {
+	cases := []struct {
+		platform Platform
+		port     int
+		err      bool
+	}{
+		{
+			platform: PlatformAWS,
+			port:     DefaultAPIPort,
+			err:      false,
+		},
+		{
+			platform: PlatformAWS,
+			port:     443,
+			err:      false,
+		},
+		{
+			platform: PlatformAWS,
+			port:     0,
+			err:      true,
+		},
+		{
+			platform: PlatformAWS,
+			port:     65536,
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			port:     DefaultAPIPort,
+			err:      false,
+		},
+		{
+			platform: PlatformLibvirt,
+			port:     443,
+			err:      true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.Platform = c.platform
+		cluster.Networking.APIPort = c.port
+		if err := cluster.validateAPIPort(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
+func TestValidateTLSKeySize(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{},
+			err:     true,
+		},
+		{
+			cluster: defaultCluster,
+			err:     false,
+		},
+		{
+			cluster: Cluster{TLS: TLS{KeySize: 4096}},
+			err:     false,
+		},
+		{
+			cluster: Cluster{TLS: TLS{KeySize: 1024}},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		if err := c.cluster.validateTLSKeySize(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{},
+			err:     false,
+		},
+		{
+			cluster: Cluster{Update: Update{Channel: "tectonic-1.9-preproduction", Server: "https://updates.example.com"}},
+			err:     false,
+		},
+		{
+			cluster: Cluster{Update: Update{Channel: " "}},
+			err:     true,
+		},
+		{
+			cluster: Cluster{Update: Update{Server: "updates.example.com"}},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		if errs := c.cluster.validateUpdate(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	cluster := Cluster{
+		Master: Master{
+			NodePools: []string{"master"},
+		},
+		Worker: Worker{
+			HostNetwork: HostNetwork{VLANs: []VLAN{{ID: 0, Link: "eno1"}}},
+		},
+	}
+	errs := cluster.ValidationErrors()
+	if len(errs) != len(cluster.Validate()) {
+		t.Fatalf("expected as many errors as Validate, got %d and %d", len(errs), len(cluster.Validate()))
+	}
+	codes := map[string]bool{}
+	for _, err := range errs {
+		codes[err.Code] = true
+	}
+	for _, code := range []string{ErrCodeNodePools, ErrCodeHostNetwork, ErrCodeName, ErrCodeDomains} {
+		if !codes[code] {
+			t.Errorf("expected a %s error, got %v", code, errs)
+		}
+	}
+}
+
+func TestValidateHostNetwork(t *testing.T) {
+	cases := []struct {
+		platform Platform
+		network  HostNetwork
+		err      bool
+	}{
+		{
+			platform: PlatformAWS,
+			network:  HostNetwork{},
+			err:      false,
+		},
+		{
+			platform: PlatformLibvirt,
+			network: HostNetwork{
+				Bonds: []Bond{{Name: "bond0", Interfaces: []string{"eno1", "eno2"}}},
+				VLANs: []VLAN{{ID: 100, Link: "bond0"}, {ID: 4094, Link: "ens1"}},
+			},
+			err: false,
+		},
+		{
+			platform: PlatformAWS,
+			network:  HostNetwork{Bonds: []Bond{{Name: "bond0", Interfaces: []string{"eth0"}}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{Bonds: []Bond{{Name: "bond0", Interfaces: []string{"eno1"}, Mode: "lacp"}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{Bonds: []Bond{{Name: "bond0"}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{Bonds: []Bond{{Name: "bond0", Interfaces: []string{"eno1"}}, {Name: "bond1", Interfaces: []string{"eno1"}}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{Bonds: []Bond{{Name: "a-very-long-bond-name", Interfaces: []string{"eno1"}}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{VLANs: []VLAN{{ID: 4095, Link: "eno1"}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{VLANs: []VLAN{{ID: 100}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network:  HostNetwork{VLANs: []VLAN{{ID: 100, Link: "eno1"}, {ID: 100, Link: "eno1"}}},
+			err:      true,
+		},
+		{
+			platform: PlatformLibvirt,
+			network: HostNetwork{
+				Bonds: []Bond{{Name: "bond0", Interfaces: []string{"eno1"}}},
+				VLANs: []VLAN{{ID: 100, Link: "eno1"}},
+			},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := Cluster{Platform: c.platform}
+		if errs := cluster.validateHostNetwork("worker", c.network); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateStats(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{},
+			err:     false,
+		},
+		{
+			cluster: Cluster{Stats: Stats{Disabled: true}},
+			err:     false,
+		},
+		{
+			cluster: Cluster{Stats: Stats{URL: "https://stats.example.com"}},
+			err:     false,
+		},
+		{
+			cluster: Cluster{Stats: Stats{URL: "stats.example.com"}},
+			err:     true,
+		},
+		{
+			cluster: Cluster{Stats: Stats{Disabled: true, URL: "https://stats.example.com"}},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		if errs := c.cluster.validateStats(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateClusterID(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{},
+			err:     false,
+		},
+		{
+			cluster: Cluster{RequestedClusterID: "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"},
+			err:     false,
+		},
+		{
+			cluster: Cluster{ClusterIDSeed: "prod-eu"},
+			err:     false,
+		},
+		{
+			cluster: Cluster{RequestedClusterID: "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d", ClusterIDSeed: "prod-eu"},
+			err:     true,
+		},
+		{
+			cluster: Cluster{RequestedClusterID: "prod-eu"},
+			err:     true,
+		},
+		{
+			cluster: Cluster{RequestedClusterID: "0A1B2C3D-4E5F-6A7B-8C9D-0E1F2A3B4C5D"},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		if err := c.cluster.validateClusterID(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
+func TestValidateClusterDomain(t *testing.T) {
+	cases := []struct {
+		cluster Cluster
+		err     bool
+	}{
+		{
+			cluster: Cluster{BaseDomain: "example.com", ClusterDomain: "example.com"},
+			err:     false,
+		},
+		{
+			cluster: Cluster{BaseDomain: "example.com", ClusterDomain: "prod.example.com"},
+			err:     false,
+		},
+		{
+			cluster: Cluster{BaseDomain: "example.com", ClusterDomain: "example.org"},
+			err:     true,
+		},
+		{
+			cluster: Cluster{BaseDomain: "example.com", ClusterDomain: "badexample.com"},
+			err:     true,
+		},
+		{
+			cluster: Cluster{BaseDomain: "example.com", ClusterDomain: "prod..example.com"},
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		if err := c.cluster.validateClusterDomain(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
+
+func TestValidateLibvirtDNSHosts(t *testing.T) {
+	cases := []struct {
+		hosts map[string]string
+		err   bool
+	}{
+		{
+			hosts: nil,
+			err:   false,
+		},
+		{
+			hosts: map[string]string{"test1-api.tt.testing": "192.168.124.11", "registry.tt.testing": "192.168.124.1"},
+			err:   false,
+		},
+		{
+			hosts: map[string]string{"not a host name": "192.168.124.11"},
+			err:   true,
+		},
+		{
+			hosts: map[string]string{"test1-api.tt.testing": "not an ip"},
+			err:   true,
+		},
+		{
+			hosts: map[string]string{"test1-api.tt.testing": "192.168.124.11", "api.tt.testing": "192.168.124.11"},
+			err:   true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.Libvirt.Network.DNSHosts = c.hosts
+		if errs := cluster.validateLibvirtDNSHosts(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateAWSCloudConfig(t *testing.T) {
+	cases := []struct {
+		cfg aws.CloudConfig
+		err bool
+	}{
+		{
+			cfg: aws.CloudConfig{},
+			err: false,
+		},
+		{
+			cfg: aws.CloudConfig{ELBSecurityGroup: "sg-0123abcd", RoleARN: "arn:aws:iam::123456789012:role/k8s"},
+			err: false,
+		},
+		{
+			cfg: aws.CloudConfig{ELBSecurityGroup: "elb"},
+			err: true,
+		},
+		{
+			cfg: aws.CloudConfig{RoleARN: "k8s"},
+			err: true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.AWS.CloudConfig = c.cfg
+		if errs := cluster.validateAWSCloudConfig(); (len(errs) != 0) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, errs)
+		}
+	}
+}
+
+func TestValidateAWSProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	credentials := filepath.Join(dir, "credentials")
+	if err := ioutil.WriteFile(credentials, []byte("[default]\naws_access_key_id = a\n\n[static]\naws_access_key_id = b\n"), 0600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+	cfg := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(cfg, []byte("[profile sso]\nsso_start_url = https://example.awsapps.com/start\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	for key, value := range map[string]string{"AWS_SHARED_CREDENTIALS_FILE": credentials, "AWS_CONFIG_FILE": cfg} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+
+	cases := []struct {
+		profile string
+		err     bool
+	}{
+		{
+			profile: aws.DefaultProfile,
+			err:     false,
+		},
+		{
+			profile: "static",
+			err:     false,
+		},
+		{
+			profile: "sso",
+			err:     false,
+		},
+		{
+			profile: "missing",
+			err:     true,
+		},
+	}
+
+	for i, c := range cases {
+		cluster := defaultCluster
+		cluster.AWS.Profile = c.profile
+		if err := cluster.validateAWSProfile(); (err != nil) != c.err {
+			no := "no"
+			if c.err {
+				no = "an"
+			}
+			t.Errorf("test case %d: expected %s error, got %v", i, no, err)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+#!/bin/sh
+# A root CA signer command for the tests: signs the digest on stdin, hashed
+# with the hash named by $1, with the RSA private key at $TEST_SIGNER_KEY.
+exec openssl pkeyutl -sign -inkey "${TEST_SIGNER_KEY}" -pkeyopt "digest:${1}"
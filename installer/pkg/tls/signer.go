@@ -0,0 +1,87 @@
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+)
+
+// hashNames are the names of the hashes passed to the signer commands.
+var hashNames = map[crypto.Hash]string{
+	crypto.SHA1:   "sha1",
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+// FileSigner returns the signer of the PEM-encoded RSA private key at path.
+func FileSigner(path string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse private key %s", path)
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %v", path, err)
+	}
+	return key, nil
+}
+
+// commandSigner delegates the signatures of the key of pub to an executable.
+type commandSigner struct {
+	command string
+	pub     crypto.PublicKey
+}
+
+// CommandSigner returns the signer of the key of the public key pub, held
+// outside of the installer, e.g. by AWS KMS, Vault or a PKCS#11 HSM.
+// The command is run with the name of the hash, e.g. sha256, as argument and
+// the digest to sign on stdin, and must print the signature on stdout: PKCS #1
+// v1.5 for RSA keys, ASN.1 DER for ECDSA ones.
+func CommandSigner(command string, pub crypto.PublicKey) crypto.Signer {
+	return &commandSigner{command: command, pub: pub}
+}
+
+// Public returns the public key of the signer.
+func (s *commandSigner) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign signs digest with the command of the signer. It ignores rand.
+func (s *commandSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return nil, errors.New("RSA-PSS signatures are not supported")
+	}
+	hash, ok := hashNames[opts.HashFunc()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash %v", opts.HashFunc())
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.command, hash)
+	cmd.Stdin = bytes.NewReader(digest)
+	cmd.Stderr = &stderr
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run signer %s: %v: %s", s.command, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	// catch signers using the wrong key or format early, rather than
+	// issuing certificates nothing can verify
+	if pub, ok := s.pub.(*rsa.PublicKey); ok {
+		if err := rsa.VerifyPKCS1v15(pub, opts.HashFunc(), digest, signature); err != nil {
+			return nil, fmt.Errorf("invalid signature from signer %s: %v", s.command, err)
+		}
+	}
+	return signature, nil
+}
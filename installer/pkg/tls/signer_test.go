@@ -0,0 +1,62 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// signerFixture is a signer command signing with the key at TEST_SIGNER_KEY,
+// shared with the tests of the config generator.
+const signerFixture = "./fixtures/signer.sh"
+
+func TestCommandSigner(t *testing.T) {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl is not available")
+	}
+	dir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := PrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate private key: %v", err)
+	}
+	other, err := PrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate private key: %v", err)
+	}
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(keyPath, []byte(PrivateKeyToPem(key)), 0600); err != nil {
+		t.Fatalf("failed to write private key: %v", err)
+	}
+	defer os.Unsetenv("TEST_SIGNER_KEY")
+	os.Setenv("TEST_SIGNER_KEY", keyPath)
+	command := signerFixture
+	digest := sha256.Sum256([]byte("test"))
+
+	signature, err := CommandSigner(command, key.Public()).Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Test case matching key: expected no error, got: %v", err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("Test case matching key: expected a valid signature, got: %v", err)
+	}
+
+	if _, err := CommandSigner(command, other.Public()).Sign(nil, digest[:], crypto.SHA256); err == nil {
+		t.Error("Test case other key: expected an error, got: <nil>")
+	}
+	if _, err := CommandSigner(command, key.Public()).Sign(nil, digest[:], &rsa.PSSOptions{Hash: crypto.SHA256}); err == nil {
+		t.Error("Test case RSA-PSS: expected an error, got: <nil>")
+	}
+	if _, err := CommandSigner(filepath.Join(dir, "missing"), key.Public()).Sign(nil, digest[:], crypto.SHA256); err == nil {
+		t.Error("Test case missing command: expected an error, got: <nil>")
+	}
+}
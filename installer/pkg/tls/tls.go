@@ -19,7 +19,8 @@ import (
 )
 
 const (
-	keySize = 2048
+	// DefaultKeySize is the default size, in bits, of the generated RSA keys.
+	DefaultKeySize = 2048
 )
 
 // CertCfg contains all needed fields to configure a new certificate
@@ -41,7 +42,12 @@ type rsaPublicKey struct {
 
 // PrivateKey generates an RSA Private key and returns the value
 func PrivateKey() (*rsa.PrivateKey, error) {
-	rsaKey, err := rsa.GenerateKey(rand.Reader, keySize)
+	return PrivateKeyWithSize(DefaultKeySize)
+}
+
+// PrivateKeyWithSize generates an RSA private key of the given size in bits.
+func PrivateKeyWithSize(bits int) (*rsa.PrivateKey, error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, bits)
 	if err != nil {
 		return nil, fmt.Errorf("error generating RSA private key: %v", err)
 	}
@@ -49,8 +55,10 @@ func PrivateKey() (*rsa.PrivateKey, error) {
 	return rsaKey, nil
 }
 
-// SelfSignedCACert Creates a self signed CA certificate
-func SelfSignedCACert(cfg *CertCfg, key *rsa.PrivateKey) (*x509.Certificate, error) {
+// SelfSignedCACert Creates a self signed CA certificate.
+// The key may be any crypto.Signer, so CA keys held by an external
+// signing backend (e.g. a KMS or an HSM) never have to touch the disk.
+func SelfSignedCACert(cfg *CertCfg, key crypto.Signer) (*x509.Certificate, error) {
 	var err error
 
 	cert := x509.Certificate{
@@ -79,24 +87,32 @@ func SelfSignedCACert(cfg *CertCfg, key *rsa.PrivateKey) (*x509.Certificate, err
 }
 
 // SignedCertificate creates a new X.509 certificate based on a template.
+// As with SelfSignedCACert, the CA key may be any crypto.Signer.
 func SignedCertificate(
 	cfg *CertCfg,
 	csr *x509.CertificateRequest,
 	key *rsa.PrivateKey,
 	caCert *x509.Certificate,
-	caKey *rsa.PrivateKey,
+	caKey crypto.Signer,
 ) (*x509.Certificate, error) {
 	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
 	if err != nil {
 		return nil, err
 	}
 
+	// a certificate cannot outlive the CA it is signed by; this matters
+	// when signing with a user supplied (e.g. intermediate) CA.
+	notAfter := time.Now().Add(cfg.Validity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+
 	certTmpl := x509.Certificate{
 		DNSNames:              csr.DNSNames,
 		ExtKeyUsage:           cfg.ExtKeyUsages,
 		IPAddresses:           csr.IPAddresses,
 		KeyUsage:              cfg.KeyUsages,
-		NotAfter:              time.Now().Add(cfg.Validity),
+		NotAfter:              notAfter,
 		NotBefore:             caCert.NotBefore,
 		SerialNumber:          serial,
 		Subject:               csr.Subject,
@@ -104,8 +120,7 @@ func SignedCertificate(
 		Version:               3,
 		BasicConstraintsValid: true,
 	}
-	pub := caCert.PublicKey.(*rsa.PublicKey)
-	certTmpl.SubjectKeyId, err = generateSubjectKeyID(pub)
+	certTmpl.SubjectKeyId, err = generateSubjectKeyID(key.Public())
 	if err != nil {
 		return nil, fmt.Errorf("failed to set subject key identifier: %v", err)
 	}
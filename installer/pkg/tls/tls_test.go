@@ -1,9 +1,15 @@
 package tls
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strings"
 	"testing"
 	"time"
 )
@@ -105,3 +111,94 @@ func TestSignedCertificate(t *testing.T) {
 		}
 	}
 }
+
+func TestSignedCertificateWithSigner(t *testing.T) {
+	// an ECDSA CA key stands in for a key held by an external signer
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	caCfg := &CertCfg{
+		Validity:  time.Hour * 5,
+		KeyUsages: x509.KeyUsageCertSign,
+		Subject: pkix.Name{
+			CommonName:         "signer_ca",
+			OrganizationalUnit: []string{"openshift"},
+		},
+		IsCA: true,
+	}
+	caCert, err := SelfSignedCACert(caCfg, caKey)
+	if err != nil {
+		t.Fatalf("Failed to generate CA certificate: %v", err)
+	}
+
+	key, err := PrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+	csrTmpl := x509.CertificateRequest{Subject: pkix.Name{CommonName: "leaf"}}
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTmpl, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate request: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate request: %v", err)
+	}
+
+	cfg := &CertCfg{
+		Validity:     time.Hour * 10,
+		KeyUsages:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := SignedCertificate(cfg, csr, key, caCert, caKey)
+	if err != nil {
+		t.Fatalf("Failed to sign certificate: %v", err)
+	}
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("expected certificate to be signed by the CA, got %v", err)
+	}
+	if cert.NotAfter.After(caCert.NotAfter) {
+		t.Errorf("expected certificate to expire no later than the CA (%v), got %v", caCert.NotAfter, cert.NotAfter)
+	}
+}
+
+func TestOpenSSHPublicKey(t *testing.T) {
+	key, err := PrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate private key: %v", err)
+	}
+
+	got := OpenSSHPublicKey(&key.PublicKey)
+	fields := strings.Fields(got)
+	if len(fields) != 2 || fields[0] != "ssh-rsa" {
+		t.Fatalf("expected an ssh-rsa key, got %q", got)
+	}
+	data, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		t.Fatalf("failed to decode the key: %v", err)
+	}
+
+	// the key is made of the "ssh-rsa" type, the exponent and the modulus
+	var parts [][]byte
+	for len(data) >= 4 {
+		n := binary.BigEndian.Uint32(data)
+		if uint32(len(data)-4) < n {
+			t.Fatalf("truncated key %q", got)
+		}
+		parts = append(parts, data[4:4+n])
+		data = data[4+n:]
+	}
+	if len(parts) != 3 || len(data) != 0 {
+		t.Fatalf("expected 3 parts in the key, got %d", len(parts))
+	}
+	if string(parts[0]) != "ssh-rsa" {
+		t.Errorf("expected key type ssh-rsa, got %q", parts[0])
+	}
+	if e := new(big.Int).SetBytes(parts[1]); e.Int64() != int64(key.E) {
+		t.Errorf("expected exponent %d, got %v", key.E, e)
+	}
+	if n := new(big.Int).SetBytes(parts[2]); n.Cmp(key.N) != 0 {
+		t.Errorf("expected modulus %v, got %v", key.N, n)
+	}
+}
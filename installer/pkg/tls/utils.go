@@ -1,9 +1,13 @@
 package tls
 
 import (
+	"bytes"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/pem"
+	"math/big"
 )
 
 // PrivateKeyToPem converts an rsa.PrivateKey object to pem string
@@ -39,3 +43,28 @@ func CSRToPem(cert *x509.CertificateRequest) string {
 	)
 	return string(certInPem)
 }
+
+// OpenSSHPublicKey converts an rsa.PublicKey to the authorized_keys format of OpenSSH, e.g. "ssh-rsa AAAA...".
+func OpenSSHPublicKey(key *rsa.PublicKey) string {
+	var buf bytes.Buffer
+	writeSSHString(&buf, []byte("ssh-rsa"))
+	writeSSHString(&buf, sshMPInt(big.NewInt(int64(key.E))))
+	writeSSHString(&buf, sshMPInt(key.N))
+	return "ssh-rsa " + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// writeSSHString writes b as a length-prefixed string of the SSH wire format (RFC 4251).
+func writeSSHString(buf *bytes.Buffer, b []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(b)))
+	buf.Write(b)
+}
+
+// sshMPInt returns the bytes of the positive integer n as an SSH mpint,
+// prefixed with a zero byte when its most significant bit is set.
+func sshMPInt(n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return b
+}
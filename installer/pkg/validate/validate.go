@@ -2,6 +2,7 @@ package validate
 
 import (
 	"bytes"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
@@ -9,7 +10,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -17,6 +20,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/dgrijalva/jwt-go"
+	"gopkg.in/yaml.v2"
 )
 
 func isMatch(re string, v string) bool {
@@ -50,6 +54,22 @@ func FileExists(path string) error {
 	return err
 }
 
+// Executable validates that the file at the given path is a regular file
+// which may be executed.
+func Executable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%q is not a regular file", path)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("%q is not executable", path)
+	}
+	return nil
+}
+
 // License validates that the file at the given path is a valid license.
 func License(path string) error {
 	licenseBytes, err := ioutil.ReadFile(path)
@@ -320,6 +340,80 @@ func HostPort(v string) error {
 	return Port(split[1])
 }
 
+// URL checks if the given string is an absolute http(s) URL with a valid host and returns an error if not.
+func URL(v string) error {
+	if err := NonEmpty(v); err != nil {
+		return err
+	}
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("invalid URL (must be an absolute http or https URL)")
+	}
+	if err := Host(u.Hostname()); err != nil {
+		return err
+	}
+	if p := u.Port(); p != "" {
+		return Port(p)
+	}
+	return nil
+}
+
+// LibvirtURI checks if the given string is a URI the libvirt client can connect to the QEMU driver with,
+// e.g. qemu:///system, qemu+ssh://root@host/system or qemu+tls://host/system, and returns an error if not.
+// The TLS client certificates, SSH key and known hosts file of the pkipath, keyfile and known_hosts parameters must exist.
+func LibvirtURI(v string) error {
+	if err := NonEmpty(v); err != nil {
+		return err
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return errors.New("invalid libvirt URI")
+	}
+	switch u.Scheme {
+	case "qemu":
+		if u.Host != "" {
+			if err := Host(u.Hostname()); err != nil {
+				return err
+			}
+		}
+	case "qemu+unix":
+		if u.Host != "" {
+			return errors.New("invalid libvirt URI (qemu+unix cannot have a host)")
+		}
+	case "qemu+ssh", "qemu+libssh", "qemu+libssh2", "qemu+tls", "qemu+tcp":
+		if err := Host(u.Hostname()); err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid libvirt URI (must be a qemu, qemu+ssh or qemu+tls URI)")
+	}
+	if p := u.Port(); p != "" {
+		if err := Port(p); err != nil {
+			return err
+		}
+	}
+	if u.Path != "/system" && u.Path != "/session" {
+		return errors.New("invalid libvirt URI (path must be /system or /session)")
+	}
+
+	params := u.Query()
+	if dir := params.Get("pkipath"); dir != "" {
+		for _, name := range []string{"cacert.pem", "clientcert.pem", "clientkey.pem"} {
+			if err := FileExists(filepath.Join(dir, name)); err != nil {
+				return fmt.Errorf("invalid libvirt URI pkipath: %v", err)
+			}
+		}
+	}
+	for _, param := range []string{"keyfile", "known_hosts"} {
+		if path := params.Get(param); path != "" {
+			if err := FileExists(path); err != nil {
+				return fmt.Errorf("invalid libvirt URI %s: %v", param, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Email checks if the given string is a valid email address and returns an error if not.
 func Email(v string) error {
 	if err := NonEmpty(v); err != nil {
@@ -372,6 +466,145 @@ func Certificate(v string) error {
 	return nil
 }
 
+// manifest holds the fields of a Kubernetes object manifest that are validated.
+type manifest struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Data map[string]interface{} `yaml:"data"`
+}
+
+var (
+	apiVersionRegExp = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?v[0-9]+((alpha|beta)[0-9]+)?$`)
+	kindRegExp       = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	// https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
+	dns1123LabelRegExp     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	dns1123SubdomainRegExp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Manifest checks if the given string holds one or more YAML documents, separated by `---`,
+// each describing a Kubernetes object, and returns an error if not.
+// Only the object metadata, and the data of ConfigMaps, are checked; the rest of the object is left to the API server.
+func Manifest(v string) error {
+	if err := NonEmpty(v); err != nil {
+		return err
+	}
+	for i, doc := range regexp.MustCompile(`(?m)^---[ \t]*$`).Split(v, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var m manifest
+		if err := yaml.Unmarshal([]byte(doc), &m); err != nil {
+			return fmt.Errorf("invalid manifest (document %d): %v", i, err)
+		}
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("invalid manifest (document %d): %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (m *manifest) validate() error {
+	switch {
+	case m.APIVersion == "":
+		return errors.New("apiVersion is not set")
+	case !apiVersionRegExp.MatchString(m.APIVersion):
+		return fmt.Errorf("apiVersion %q is not of the form [group/]version", m.APIVersion)
+	case m.Kind == "":
+		return errors.New("kind is not set")
+	case !kindRegExp.MatchString(m.Kind):
+		return fmt.Errorf("kind %q must be in CamelCase", m.Kind)
+	case m.Metadata.Name == "":
+		return errors.New("metadata.name is not set")
+	case len(m.Metadata.Name) > 253 || !dns1123SubdomainRegExp.MatchString(m.Metadata.Name):
+		return fmt.Errorf("metadata.name %q must be a lower case DNS-1123 subdomain", m.Metadata.Name)
+	case m.Metadata.Namespace != "" && (len(m.Metadata.Namespace) > 63 || !dns1123LabelRegExp.MatchString(m.Metadata.Namespace)):
+		return fmt.Errorf("metadata.namespace %q must be a lower case DNS-1123 label", m.Metadata.Namespace)
+	}
+	if m.Kind == "ConfigMap" {
+		for key, value := range m.Data {
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("data.%s of a ConfigMap must be a string", key)
+			}
+		}
+	}
+	return nil
+}
+
+// CertificateBundle checks if the given string is a non-empty list of valid certificates in PEM format and returns an error if not.
+// Ignores leading and trailing whitespace.
+func CertificateBundle(v string) error {
+	if err := NonEmpty(v); err != nil {
+		return err
+	}
+	rest := []byte(strings.TrimSpace(v))
+	for len(rest) != 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return errors.New("failed to parse certificate bundle")
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("invalid certificate bundle (unexpected %s)", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return errors.New("invalid certificate")
+		}
+		rest = []byte(strings.TrimSpace(string(rest)))
+	}
+	return nil
+}
+
+// CertificateAuthority checks if the given string is a valid certificate in PEM format
+// that is allowed to sign other certificates and returns an error if not.
+// If the string contains a bundle, only the first certificate is checked.
+func CertificateAuthority(v string) error {
+	if err := Certificate(v); err != nil {
+		return err
+	}
+	block, _ := pem.Decode([]byte(strings.TrimSpace(v)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.New("invalid certificate")
+	}
+	if !cert.IsCA {
+		return errors.New("certificate is not a certificate authority")
+	}
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return errors.New("certificate authority is not allowed to sign certificates")
+	}
+	return nil
+}
+
+// KeyPair checks if the given certificate and private key, both in PEM format, belong together and returns an error if not.
+// If the certificate string contains a bundle, only the first certificate is checked.
+func KeyPair(cert, key string) error {
+	if err := Certificate(cert); err != nil {
+		return err
+	}
+	if err := PrivateKey(key); err != nil {
+		return err
+	}
+	certBlock, _ := pem.Decode([]byte(strings.TrimSpace(cert)))
+	c, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return errors.New("invalid certificate")
+	}
+	keyBlock, _ := pem.Decode([]byte(key))
+	k, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return errors.New("invalid private key")
+	}
+	pub, ok := c.PublicKey.(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(k.N) != 0 || pub.E != k.E {
+		return errors.New("private key does not match the certificate")
+	}
+	return nil
+}
+
 // PrivateKey checks if the given string is a valid private key in PEM format and returns an error if not.
 // Ignores leading and trailing whitespace.
 func PrivateKey(v string) error {
@@ -422,7 +655,11 @@ func OpenSSHPublicKey(v string) error {
 		return invalidError
 	}
 
-	return nil
+	switch keyType {
+	case "ssh-rsa", "ssh-ed25519", "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		return nil
+	}
+	return fmt.Errorf("invalid SSH public key (unsupported key type %q)", keyType)
 }
 
 // CIDRsDontOverlap ensures two given CIDRs don't overlap
@@ -0,0 +1,62 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// These are set at build time via -X linker flags (see installer/cmd/tectonic/BUILD.bazel).
+var (
+	// Raw is the release version of the installer, e.g. "1.2.3-beta".
+	Raw = "was not built correctly"
+	// Commit is the git commit the installer was built from.
+	Commit = "unknown"
+	// BuildTime is the UTC time the installer was built at.
+	BuildTime = "unknown"
+)
+
+// Info describes the installer binary.
+type Info struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit"`
+	BuildTime string   `json:"buildTime"`
+	GoVersion string   `json:"goVersion"`
+	Platforms []string `json:"platforms"`
+}
+
+// Get returns the version information of the running binary.
+func Get() Info {
+	platforms := make([]string, 0, len(config.Platforms))
+	for _, p := range config.Platforms {
+		platforms = append(platforms, string(p))
+	}
+	return Info{
+		Version:   Raw,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platforms: platforms,
+	}
+}
+
+// String returns the human readable form of the version information.
+func (i Info) String() string {
+	return fmt.Sprintf(`tectonic %s
+commit: %s
+built: %s
+go: %s
+platforms: %s`, i.Version, i.Commit, i.BuildTime, i.GoVersion, strings.Join(i.Platforms, ", "))
+}
+
+// JSON returns the machine readable form of the version information.
+func (i Info) JSON() (string, error) {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,38 @@
+package version
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	info := Info{
+		Version:   "1.2.3",
+		Commit:    "abc1234",
+		BuildTime: "2018-06-01T00:00:00+0000",
+		GoVersion: "go1.10",
+		Platforms: []string{"aws", "libvirt"},
+	}
+
+	expected := `tectonic 1.2.3
+commit: abc1234
+built: 2018-06-01T00:00:00+0000
+go: go1.10
+platforms: aws, libvirt`
+	if got := info.String(); got != expected {
+		t.Errorf("expected: %s, got: %s", expected, got)
+	}
+
+	data, err := info.JSON()
+	if err != nil {
+		t.Fatalf("failed to marshal version info: %v", err)
+	}
+	var got Info
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("failed to unmarshal version info: %v", err)
+	}
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("expected: %v, got: %v", info, got)
+	}
+}
@@ -0,0 +1,151 @@
+package workflow
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// analysisLineLength bounds the length of the log lines quoted as evidence.
+const analysisLineLength = 200
+
+// analysisRule recognizes a likely root cause of a failed install in the
+// files of a gather bundle.
+type analysisRule struct {
+	cause   string
+	hint    string
+	pattern *regexp.Regexp
+}
+
+// analysisRules are the known root causes, most specific first.
+var analysisRules = []analysisRule{
+	{
+		cause:   "The pull secret is invalid or lacks access to the images",
+		hint:    "check the pullSecretPath of the config, e.g. by pulling one of the images with it",
+		pattern: regexp.MustCompile(`(?i)unauthorized: authentication required|pull access denied|unauthorized: access to the requested resource is not authorized|401 Unauthorized`),
+	},
+	{
+		cause:   "The nodes cannot fetch their Ignition config from the node controller",
+		hint:    "check that the <cluster>-tnc record resolves to the masters and that port 49500 is open to the nodes",
+		pattern: regexp.MustCompile(`-tnc\..*(connection refused|i/o timeout|no route to host|connection reset by peer)`),
+	},
+	{
+		cause:   "DNS resolution failed",
+		hint:    "check that the baseDomain zone exists and is delegated, and that the DNS servers of the network resolve it",
+		pattern: regexp.MustCompile(`no such host|server misbehaving|NXDOMAIN`),
+	},
+	{
+		cause:   "A cloud or cluster quota is exhausted",
+		hint:    "raise the quota, e.g. the instance or vCPU limits of the AWS region, or lower the number of nodes",
+		pattern: regexp.MustCompile(`InstanceLimitExceeded|VcpuLimitExceeded|AddressLimitExceeded|VpcLimitExceeded|exceeded quota|QuotaExceeded`),
+	},
+}
+
+// finding is a likely root cause recognized in a gather bundle.
+type finding struct {
+	rule analysisRule
+	// file is the first file of the bundle in which it was recognized,
+	// at line.
+	file string
+	line string
+}
+
+// AnalyzeWorkflow creates new instances of the 'analyze' workflow,
+// responsible for printing the likely root causes of a failed install,
+// with how to remediate them, recognized in a bundle written by 'gather'.
+func AnalyzeWorkflow(bundle string) Workflow {
+	return Workflow{
+		metadata: metadata{},
+		steps: []Step{
+			func(m *metadata) error {
+				return analyzeStep(bundle)
+			},
+		},
+	}
+}
+
+func analyzeStep(bundle string) error {
+	files, err := readTarball(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", bundle, err)
+	}
+	findings := analyzeBundle(files)
+	if len(findings) == 0 {
+		fmt.Printf("No known root cause recognized in %s; check the logs it contains.\n", bundle)
+		return nil
+	}
+	fmt.Printf("Likely root causes recognized in %s:\n", bundle)
+	for _, f := range findings {
+		fmt.Printf("\n* %s\n  Seen in %s: %s\n  Hint: %s\n", f.rule.cause, f.file, f.line, f.rule.hint)
+	}
+	return nil
+}
+
+// analyzeBundle returns the findings recognized in the files of a gather
+// bundle, in the order of the rules, at most one per rule.
+func analyzeBundle(files map[string][]byte) []finding {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []finding
+	for _, rule := range analysisRules {
+	next:
+		for _, name := range names {
+			for _, line := range strings.Split(string(files[name]), "\n") {
+				if rule.pattern.MatchString(line) {
+					line = strings.TrimSpace(line)
+					if len(line) > analysisLineLength {
+						line = line[:analysisLineLength] + "..."
+					}
+					findings = append(findings, finding{rule: rule, file: name, line: line})
+					break next
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// readTarball reads the files of a gzipped tarball, as written by writeTarball.
+func readTarball(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[hdr.Name] = data
+	}
+}
@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeBundle(t *testing.T) {
+	testCases := []struct {
+		test     string
+		files    map[string][]byte
+		expected []string
+	}{
+		{
+			test:     "Healthy",
+			files:    map[string][]byte{"10.0.0.1/bootkube.log": []byte("bootkube.service complete\n")},
+			expected: nil,
+		},
+		{
+			test: "Bad pull secret",
+			files: map[string][]byte{
+				"10.0.0.1/kubelet.log": []byte("ok\nFailed to pull image \"quay.io/coreos/tectonic\": unauthorized: authentication required\n"),
+			},
+			expected: []string{"10.0.0.1/kubelet.log: Failed to pull image \"quay.io/coreos/tectonic\": unauthorized: authentication required"},
+		},
+		{
+			test: "Unreachable node controller and DNS failure",
+			files: map[string][]byte{
+				"10.0.0.2/journal.log": []byte("GET https://test-tnc.example.com:49500/config/master: dial tcp 10.0.0.1:49500: connect: connection refused\n"),
+				"10.0.0.1/journal.log": []byte("dial tcp: lookup test-api.example.com on 10.0.0.2:53: no such host\n"),
+			},
+			expected: []string{
+				"10.0.0.2/journal.log: GET https://test-tnc.example.com:49500/config/master: dial tcp 10.0.0.1:49500: connect: connection refused",
+				"10.0.0.1/journal.log: dial tcp: lookup test-api.example.com on 10.0.0.2:53: no such host",
+			},
+		},
+		{
+			test:     "Quota",
+			files:    map[string][]byte{"api/pods.json": []byte(`{"message": "pods \"a\" is forbidden: exceeded quota: compute"}`)},
+			expected: []string{`api/pods.json: {"message": "pods \"a\" is forbidden: exceeded quota: compute"}`},
+		},
+	}
+
+	for _, tc := range testCases {
+		var got []string
+		for _, f := range analyzeBundle(tc.files) {
+			got = append(got, f.file+": "+f.line)
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestReadTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analyze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"10.0.0.1/journal.log": []byte("journal"),
+		"master.ign":           []byte("{}"),
+	}
+	archive := filepath.Join(dir, "gather.tar.gz")
+	if err := writeTarball(archive, files); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readTarball(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, files) {
+		t.Errorf("expected: %v, got: %v", files, got)
+	}
+}
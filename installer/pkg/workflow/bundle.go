@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	// BundleYAML bundles the manifests as a single multi-document YAML file.
+	BundleYAML = "yaml"
+	// BundleTarball bundles the manifests as a gzipped tarball.
+	BundleTarball = "tar.gz"
+
+	manifestBundleName = "manifests-bundle"
+)
+
+// manifestPaths lists the manifest directories in the order they are applied:
+// bootkube creates the manifests of the control plane before the tectonic
+// manifests are created.
+var manifestPaths = []string{
+	kubeSystemPath,
+	tectonicSystemPath,
+}
+
+// bundleManifestsStep writes every generated manifest, in the order they are
+// applied, to a single file in the given format, e.g. for GitOps tools.
+func bundleManifestsStep(m *metadata) error {
+	if m.manifestBundle == "" {
+		return nil
+	}
+	files, err := readManifests(m.clusterDir)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.clusterDir, generatedPath, fmt.Sprintf("%s.%s", manifestBundleName, m.manifestBundle))
+	// the bundle of a previous run is replaced
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	switch m.manifestBundle {
+	case BundleYAML:
+		err = ioutil.WriteFile(path, yamlBundle(files), 0600)
+	case BundleTarball:
+		contents := map[string][]byte{}
+		for _, f := range files {
+			contents[f.name] = f.data
+		}
+		err = writeTarball(path, contents)
+	default:
+		return fmt.Errorf("unknown manifest bundle format %q", m.manifestBundle)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write manifest bundle %s: %v", path, err)
+	}
+	log.Infof("Manifests bundled in %s", path)
+	return nil
+}
+
+type manifestFile struct {
+	// name is the path of the manifest, relative to the generated directory.
+	name string
+	data []byte
+}
+
+// readManifests returns the generated manifests, i.e. the YAML files of the
+// manifest directories and their subdirectories, in the order they are applied.
+func readManifests(clusterDir string) ([]manifestFile, error) {
+	var files []manifestFile
+	for _, dir := range manifestPaths {
+		// the files of a directory are walked in lexical order
+		err := filepath.Walk(filepath.Join(clusterDir, dir), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(path); info.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				return nil
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read manifest: %v", err)
+			}
+			rel, err := filepath.Rel(filepath.Join(clusterDir, generatedPath), path)
+			if err != nil {
+				return err
+			}
+			files = append(files, manifestFile{name: rel, data: data})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list manifests: %v", err)
+		}
+	}
+	return files, nil
+}
+
+// yamlBundle joins the manifests into a multi-document YAML file.
+func yamlBundle(files []manifestFile) []byte {
+	var buf bytes.Buffer
+	for _, f := range files {
+		fmt.Fprintf(&buf, "---\n# Source: %s\n", f.name)
+		buf.Write(bytes.TrimPrefix(bytes.TrimSpace(f.data), []byte("---\n")))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
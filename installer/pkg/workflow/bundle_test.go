@@ -0,0 +1,58 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadManifests(t *testing.T) {
+	clusterDir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatalf("failed to create cluster dir: %v", err)
+	}
+	defer os.RemoveAll(clusterDir)
+
+	manifests := map[string]string{
+		filepath.Join(tectonicSystemPath, "ingress.yaml"):                "kind: Deployment\n",
+		filepath.Join(kubeSystemPath, "kube-system.yaml"):                "---\nkind: ConfigMap\n",
+		filepath.Join(kubeSystemPath, "00-namespace.yaml"):               "kind: Namespace\n",
+		filepath.Join(kubeSystemPath, "etcd", "etcd.yml"):                "kind: Service\n",
+		filepath.Join(kubeSystemPath, "etcd", "README"):                  "not a manifest\n",
+		filepath.Join(tectonicSystemPath, "updater", "app-version.yaml"): "kind: AppVersion\n",
+	}
+	for name, data := range manifests {
+		path := filepath.Join(clusterDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	files, err := readManifests(clusterDir)
+	if err != nil {
+		t.Fatalf("failed to read manifests: %v", err)
+	}
+	expected := `---
+# Source: manifests/00-namespace.yaml
+kind: Namespace
+---
+# Source: manifests/etcd/etcd.yml
+kind: Service
+---
+# Source: manifests/kube-system.yaml
+kind: ConfigMap
+---
+# Source: tectonic/ingress.yaml
+kind: Deployment
+---
+# Source: tectonic/updater/app-version.yaml
+kind: AppVersion
+`
+	if got := string(yamlBundle(files)); got != expected {
+		t.Errorf("Test case YAML bundle: expected: %q, got: %q", expected, got)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 
+	log "github.com/Sirupsen/logrus"
+
 	"github.com/openshift/installer/installer/pkg/config"
 )
 
@@ -20,6 +22,41 @@ func ConvertWorkflow(configFilePath string) Workflow {
 	}
 }
 
+// MigrateWorkflow creates new instances of the 'migrate' workflow,
+// responsible for upgrading a cluster config written for an older installer
+// to the current schema.
+func MigrateWorkflow(configFilePath string) Workflow {
+	return Workflow{
+		metadata: metadata{configFilePath: configFilePath},
+		steps: []Step{
+			readYAMLConfigStep,
+			printYAMLConfigStep,
+		},
+	}
+}
+
+func readYAMLConfigStep(m *metadata) error {
+	data, err := ioutil.ReadFile(m.configFilePath)
+	if err != nil {
+		return err
+	}
+
+	unknown, err := config.UnknownFields(data)
+	if err != nil {
+		return err
+	}
+	for _, field := range unknown {
+		log.Warnf("%s: field is not part of the current schema and was dropped; it needs manual attention", field)
+	}
+
+	cluster, err := config.ParseConfig(data)
+	if err != nil {
+		return err
+	}
+	m.cluster = *cluster
+	return nil
+}
+
 func readTFVarsConfigStep(m *metadata) error {
 	data, err := ioutil.ReadFile(m.configFilePath)
 	if err != nil {
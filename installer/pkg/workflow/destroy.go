@@ -1,13 +1,41 @@
 package workflow
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// destroyOrder lists the terraform steps in the order the 'destroy' workflow removes them.
+var destroyOrder = []string{
+	mastersStep,
+	joinWorkersStep,
+	etcdStep,
+	tncDNSStep,
+	topologyStep,
+	assetsStep,
+	tlsStep,
+}
+
 // DestroyWorkflow creates new instances of the 'destroy' workflow,
 // responsible for running the actions required to remove resources
 // of an existing cluster and clean up any remaining artefacts.
-func DestroyWorkflow(clusterDir string) Workflow {
+// Each step is interrupted after the timeout, unless it is zero.
+// Up to parallelism resources are destroyed concurrently, terraform's
+// default if zero. The resources and modules matching the retained
+// addresses are kept.
+func DestroyWorkflow(clusterDir string, timeout time.Duration, parallelism int, retain []string) Workflow {
 	return Workflow{
-		metadata: metadata{clusterDir: clusterDir},
+		metadata: metadata{clusterDir: clusterDir, destroyTimeout: timeout, destroyParallelism: parallelism, retain: retain},
 		steps: []Step{
-			refreshConfigStep,
+			readDestroyConfigStep,
 			destroyJoinMastersStep,
 			destroyJoinWorkersStep,
 			destroyEtcdStep,
@@ -16,12 +44,82 @@ func DestroyWorkflow(clusterDir string) Workflow {
 			destroyTopologyStep,
 			destroyAssetsStep,
 			destroyTLSAssetsStep,
+			destroyLeftoversStep,
+		},
+	}
+}
+
+// DestroyDryRunWorkflow creates new instances of the 'destroy --dry-run' workflow,
+// responsible for listing the resources the 'destroy' workflow would remove,
+// without removing anything.
+func DestroyDryRunWorkflow(clusterDir string, retain []string) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir, retain: retain},
+		steps: []Step{
+			printDestroyPlanStep,
 		},
 	}
 }
 
+func printDestroyPlanStep(m *metadata) error {
+	var count int
+	for _, step := range destroyOrder {
+		if !hasStateFile(m.clusterDir, step) {
+			continue
+		}
+		resources, err := readStateResources(m.clusterDir, step)
+		if err != nil {
+			return err
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", step)
+		for _, r := range resources {
+			if isRetained(r.Address, m.retain) {
+				fmt.Printf("  %s (retained)\n", r)
+				continue
+			}
+			fmt.Printf("  %s\n", r)
+			count++
+		}
+	}
+	fmt.Printf("%d resources would be destroyed\n", count)
+	return nil
+}
+
+// readDestroyConfigStep refreshes the cluster config, unless none of the
+// terraform states is left, e.g. in a cluster directory copied without them,
+// in which case the metadata of the cluster is read instead: the resources of
+// the cluster are then found by the sweep of the leftovers.
+func readDestroyConfigStep(m *metadata) error {
+	for _, step := range destroyOrder {
+		if hasStateFile(m.clusterDir, step) {
+			return refreshConfigStep(m)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(m.clusterDir, metadataFileName)); err != nil {
+		return refreshConfigStep(m)
+	}
+	md, err := readClusterMetadata(m.clusterDir)
+	if err != nil {
+		return err
+	}
+	if len(m.retain) != 0 {
+		return errors.New("no terraform state is left to retain resources from")
+	}
+	log.Infof("No terraform state found, destroying cluster %s (%s) from %s", md.ClusterName, md.ClusterID, metadataFileName)
+	m.cluster = md.cluster()
+	m.fromMetadata = true
+	return nil
+}
+
 func destroyTLSAssetsStep(m *metadata) error {
-	return runDestroyStep(m, tlsStep)
+	if err := runDestroyStep(m, tlsStep); err != nil {
+		return err
+	}
+	// the CAs signed for the tls step go with it
+	return os.RemoveAll(filepath.Join(m.clusterDir, config.SignedCAsPath))
 }
 
 func destroyAssetsStep(m *metadata) error {
@@ -33,7 +131,13 @@ func destroyEtcdStep(m *metadata) error {
 }
 
 func destroyBootstrapStep(m *metadata) error {
-	return runDestroyStep(m, mastersStep, []string{bootstrapOff}...)
+	if err := runDestroyStep(m, mastersStep, []string{bootstrapOff}...); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(m.clusterDir, bootstrappedFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 func destroyTNCDNSStep(m *metadata) error {
@@ -52,15 +156,112 @@ func destroyJoinMastersStep(m *metadata) error {
 	return runDestroyStep(m, mastersStep, []string{bootstrapOff}...)
 }
 
+func destroyLeftoversStep(m *metadata) error {
+	if err := destroyLibvirtLeftoversStep(m); err != nil {
+		return withExitCode(err, ExitCodeDestroyIncomplete)
+	}
+	return withExitCode(destroyAWSLeftoversStep(m), ExitCodeDestroyIncomplete)
+}
+
 func runDestroyStep(m *metadata, step string, extraArgs ...string) error {
 	if !hasStateFile(m.clusterDir, step) {
 		// there is no statefile, therefore nothing to destroy for this step
 		return nil
 	}
+	stepLogger(m, step).Info("Destroying step")
 	templateDir, err := findStepTemplates(step, m.cluster.Platform)
 	if err != nil {
 		return err
 	}
+	if err := retainResources(m, step); err != nil {
+		return withExitCode(err, ExitCodeDestroyIncomplete)
+	}
+
+	if m.destroyParallelism > 0 {
+		extraArgs = append(extraArgs, fmt.Sprintf("-parallelism=%d", m.destroyParallelism))
+	}
+	if err := tfDestroy(m, step, templateDir, m.destroyTimeout, extraArgs...); err != nil {
+		logRemainingResources(m.clusterDir, step)
+		return withExitCode(err, ExitCodeDestroyIncomplete)
+	}
+	return nil
+}
+
+// retainResources removes the resources matching the retained addresses from
+// the state of the given step, so that destroying the step leaves them in place.
+func retainResources(m *metadata, step string) error {
+	if len(m.retain) == 0 {
+		return nil
+	}
+	resources, err := readStateResources(m.clusterDir, step)
+	if err != nil {
+		return err
+	}
+	addresses, names := retainedResources(resources, m.retain)
+	if len(addresses) == 0 {
+		return nil
+	}
+	// once out of the state, the retained resources look like leftovers
+	m.retainedNames = append(m.retainedNames, names...)
+	for _, address := range addresses {
+		stepLogger(m, step).Infof("Retaining %s", address)
+	}
+	return tfStateRm(m, step, addresses...)
+}
+
+// retainedResources returns the retained addresses matching any of the
+// resources, and the names of the resources they match.
+func retainedResources(resources []stateResource, retain []string) (addresses, names []string) {
+	for _, address := range retain {
+		for _, r := range resources {
+			if !isRetained(r.Address, []string{address}) {
+				continue
+			}
+			if !contains(addresses, address) {
+				addresses = append(addresses, address)
+			}
+			if r.Name != "" {
+				names = append(names, r.Name)
+			}
+		}
+	}
+	return addresses, names
+}
+
+// isRetained returns whether the resource address is, or belongs to, one of the retained addresses.
+func isRetained(address string, retain []string) bool {
+	for _, r := range retain {
+		if address == r || strings.HasPrefix(address, r+".") {
+			return true
+		}
+	}
+	return false
+}
 
-	return tfDestroy(m.clusterDir, step, templateDir, extraArgs...)
+// logRemainingResources reports the resources still in the state of the
+// given step and of the steps destroyed after it.
+func logRemainingResources(clusterDir, from string) {
+	var remaining []stateResource
+	var found bool
+	for _, step := range destroyOrder {
+		if step == from {
+			found = true
+		}
+		if !found || !hasStateFile(clusterDir, step) {
+			continue
+		}
+		resources, err := readStateResources(clusterDir, step)
+		if err != nil {
+			log.Warnf("Failed to list the remaining resources of step %s: %v", step, err)
+			continue
+		}
+		remaining = append(remaining, resources...)
+	}
+	if len(remaining) == 0 {
+		return
+	}
+	log.Errorf("%d resources could not be destroyed yet, run destroy again once the errors are addressed:", len(remaining))
+	for _, r := range remaining {
+		log.Errorf("  %s", r)
+	}
 }
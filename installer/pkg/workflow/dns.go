@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"context"
+	"net"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// dnsCheckTimeout bounds the query to each of the DNS servers of the VPC.
+const dnsCheckTimeout = 5 * time.Second
+
+// checkVPCDNSServersStep warns about the DNS servers of the VPC that cannot
+// resolve the base domain the cluster records are created in. It only warns
+// since the servers may only be reachable from within the VPC.
+func checkVPCDNSServersStep(m *metadata) error {
+	if m.cluster.Platform != config.PlatformAWS {
+		return nil
+	}
+	for _, server := range m.cluster.AWS.VPCDNSServers {
+		if err := lookupNS(server, m.cluster.BaseDomain, dnsCheckTimeout); err != nil {
+			log.Warnf("DNS server %s cannot resolve the base domain %s: %v", server, m.cluster.BaseDomain, err)
+			log.Warnf("The nodes will not resolve the cluster host names unless it forwards %s to the Amazon DNS server of the VPC", m.cluster.DNSDomain())
+		}
+	}
+	return nil
+}
+
+// lookupNS looks up the name servers of domain with the DNS server at the given IP.
+func lookupNS(server, domain string, timeout time.Duration) error {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, net.JoinHostPort(server, "53"))
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, err := resolver.LookupNS(ctx, domain)
+	return err
+}
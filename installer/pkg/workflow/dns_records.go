@@ -0,0 +1,155 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// Formats of the DNS records.
+const (
+	DNSRecordsJSON = "json"
+	DNSRecordsBIND = "bind"
+)
+
+// dnsAliasTTL is the TTL of the CNAME records standing for Route 53 alias
+// records, which have none.
+const dnsAliasTTL = 60
+
+// dnsRecord is a DNS record created for the cluster.
+type dnsRecord struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	TTL    int      `json:"ttl,omitempty"`
+	Values []string `json:"values,omitempty"`
+	// Alias is the host name of the load balancer a Route 53 alias record
+	// resolves to, instead of values.
+	Alias  string `json:"alias,omitempty"`
+	ZoneID string `json:"zoneID,omitempty"`
+}
+
+// DNSRecordsWorkflow creates new instances of the 'dns-records' workflow,
+// responsible for printing the DNS records of an AWS cluster, as created by
+// its TerraForm steps, in the given format, e.g. for DNS teams to create them
+// in the DNS servers of the organization.
+func DNSRecordsWorkflow(clusterDir, format string) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			readClusterConfigStep,
+			func(m *metadata) error {
+				return printDNSRecordsStep(m, format)
+			},
+		},
+	}
+}
+
+func printDNSRecordsStep(m *metadata, format string) error {
+	if m.cluster.Platform != config.PlatformAWS {
+		return fmt.Errorf("%s clusters have no DNS records to export: their host names are resolved by the dnsmasq of their network", m.cluster.Platform)
+	}
+	var records []dnsRecord
+	for _, step := range destroyOrder {
+		if !hasStateFile(m.clusterDir, step) {
+			continue
+		}
+		stepRecords, err := readDNSRecords(m.clusterDir, step)
+		if err != nil {
+			return err
+		}
+		records = append(records, stepRecords...)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no DNS records found in %s: is the cluster installed?", m.clusterDir)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].ZoneID != records[j].ZoneID {
+			return records[i].ZoneID < records[j].ZoneID
+		}
+		return records[i].Name < records[j].Name
+	})
+
+	if format == DNSRecordsBIND {
+		fmt.Print(bindRecords(records))
+		return nil
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// readDNSRecords returns the Route 53 records held by the state file of the given step.
+func readDNSRecords(stateDir, step string) ([]dnsRecord, error) {
+	data, err := ioutil.ReadFile(filepath.Join(stateDir, fmt.Sprintf("%s.tfstate", step)))
+	if err != nil {
+		return nil, err
+	}
+	var state tfState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s state: %v", step, err)
+	}
+
+	var records []dnsRecord
+	for _, module := range state.Modules {
+		for _, res := range module.Resources {
+			if res.Type != "aws_route53_record" {
+				continue
+			}
+			attrs := res.Primary.Attributes
+			r := dnsRecord{
+				Name:   strings.TrimSuffix(attrs["fqdn"], "."),
+				Type:   attrs["type"],
+				ZoneID: attrs["zone_id"],
+			}
+			if r.Name == "" {
+				r.Name = strings.TrimSuffix(attrs["name"], ".")
+			}
+			r.TTL, _ = strconv.Atoi(attrs["ttl"])
+			// records and alias are sets, flattened with the hashes of their elements
+			for k, v := range attrs {
+				switch {
+				case strings.HasPrefix(k, "records.") && k != "records.#":
+					r.Values = append(r.Values, v)
+				case strings.HasPrefix(k, "alias.") && strings.HasSuffix(k, ".name"):
+					r.Alias = strings.TrimSuffix(v, ".")
+				}
+			}
+			sort.Strings(r.Values)
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// bindRecords returns the records, sorted by zone, as lines of a BIND zone
+// file. The Route 53 alias records, which BIND has no equivalent for, are
+// CNAME records to the load balancer instead.
+func bindRecords(records []dnsRecord) string {
+	var buf bytes.Buffer
+	for i, r := range records {
+		if i == 0 || r.ZoneID != records[i-1].ZoneID {
+			fmt.Fprintf(&buf, "; zone %s\n", r.ZoneID)
+		}
+		if r.Alias != "" {
+			fmt.Fprintf(&buf, "%s.\t%d\tIN\tCNAME\t%s.\n", r.Name, dnsAliasTTL, r.Alias)
+			continue
+		}
+		for _, v := range r.Values {
+			if r.Type == "CNAME" {
+				v = strings.TrimSuffix(v, ".") + "."
+			}
+			fmt.Fprintf(&buf, "%s.\t%d\tIN\t%s\t%s\n", r.Name, r.TTL, r.Type, v)
+		}
+	}
+	return buf.String()
+}
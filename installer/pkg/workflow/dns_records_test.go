@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const dnsRecordsState = `{
+    "version": 3,
+    "modules": [
+        {
+            "path": ["root", "dns"],
+            "resources": {
+                "aws_route53_record.tectonic_api_internal": {
+                    "type": "aws_route53_record",
+                    "primary": {
+                        "id": "Z2_test-api.example.com_A",
+                        "attributes": {
+                            "alias.#": "1",
+                            "alias.123.name": "internal-test-int-1.eu-west-1.elb.amazonaws.com",
+                            "fqdn": "test-api.example.com",
+                            "name": "test-api.example.com",
+                            "type": "A",
+                            "zone_id": "Z2"
+                        }
+                    }
+                },
+                "aws_route53_record.tectonic-console": {
+                    "type": "aws_route53_record",
+                    "primary": {
+                        "id": "Z1_test_A",
+                        "attributes": {
+                            "fqdn": "test.example.com",
+                            "name": "test",
+                            "records.#": "2",
+                            "records.111": "10.0.0.6",
+                            "records.222": "10.0.0.5",
+                            "ttl": "60",
+                            "type": "A",
+                            "zone_id": "Z1"
+                        }
+                    }
+                },
+                "aws_route53_zone.tectonic_int": {
+                    "type": "aws_route53_zone",
+                    "primary": {
+                        "id": "Z2",
+                        "attributes": {}
+                    }
+                }
+            }
+        }
+    ]
+}`
+
+func TestDNSRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dns_records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, topologyStep+".tfstate"), []byte(dnsRecordsState), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := readDNSRecords(dir, topologyStep)
+	if err != nil {
+		t.Fatalf("failed to read the records: %v", err)
+	}
+	expected := map[string]dnsRecord{
+		"test.example.com":     {Name: "test.example.com", Type: "A", TTL: 60, Values: []string{"10.0.0.5", "10.0.0.6"}, ZoneID: "Z1"},
+		"test-api.example.com": {Name: "test-api.example.com", Type: "A", Alias: "internal-test-int-1.eu-west-1.elb.amazonaws.com", ZoneID: "Z2"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Test case records: expected: %v, got: %v", expected, records)
+	}
+	for _, r := range records {
+		if !reflect.DeepEqual(r, expected[r.Name]) {
+			t.Errorf("Test case %s: expected: %v, got: %v", r.Name, expected[r.Name], r)
+		}
+	}
+
+	bind := bindRecords([]dnsRecord{expected["test.example.com"], expected["test-api.example.com"]})
+	expectedBIND := "; zone Z1\n" +
+		"test.example.com.\t60\tIN\tA\t10.0.0.5\n" +
+		"test.example.com.\t60\tIN\tA\t10.0.0.6\n" +
+		"; zone Z2\n" +
+		"test-api.example.com.\t60\tIN\tCNAME\tinternal-test-int-1.eu-west-1.elb.amazonaws.com.\n"
+	if bind != expectedBIND {
+		t.Errorf("Test case BIND: expected: %q, got: %q", expectedBIND, bind)
+	}
+}
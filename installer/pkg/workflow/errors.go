@@ -0,0 +1,101 @@
+package workflow
+
+import "regexp"
+
+// Exit codes of the installer, by class of failure, so automation can branch
+// on them. Command line usage errors exit with 1, like any unclassified error.
+const (
+	// ExitCodeGeneric is returned for errors which are not classified.
+	ExitCodeGeneric = 1
+	// ExitCodeValidation is returned when the cluster config is invalid.
+	ExitCodeValidation = 2
+	// ExitCodeProvisioning is returned when creating the resources of a step failed.
+	ExitCodeProvisioning = 3
+	// ExitCodeBootstrapTimeout is returned when the API did not come up in time.
+	ExitCodeBootstrapTimeout = 4
+	// ExitCodeInstallTimeout is returned when the console did not come up in time.
+	ExitCodeInstallTimeout = 5
+	// ExitCodeDestroyIncomplete is returned when removing the resources of a step failed;
+	// running destroy again resumes where it stopped.
+	ExitCodeDestroyIncomplete = 6
+	// ExitCodeQuota is returned when TerraForm failed on a limit or quota of
+	// the cloud account, e.g. LimitExceeded, QuotaExceeded or VcpuLimitExceeded.
+	ExitCodeQuota = 7
+	// ExitCodeInterrupted is returned when the workflow was interrupted, like
+	// shells report processes ended by SIGINT.
+	ExitCodeInterrupted = 130
+)
+
+// errorCodes are the stable codes of the classes of failure, by exit code,
+// e.g. for wrappers to map failures to documentation or remediations.
+var errorCodes = map[int]string{
+	ExitCodeGeneric:           "generic",
+	ExitCodeValidation:        "validation",
+	ExitCodeProvisioning:      "provisioning",
+	ExitCodeBootstrapTimeout:  "bootstrap-timeout",
+	ExitCodeInstallTimeout:    "install-timeout",
+	ExitCodeDestroyIncomplete: "destroy-incomplete",
+	ExitCodeQuota:             "quota",
+	ExitCodeInterrupted:       "interrupted",
+}
+
+// quotaErrorRegexp matches the cloud errors of the requests exceeding a limit or
+// a quota of the account, e.g. VpcLimitExceeded.
+var quotaErrorRegexp = regexp.MustCompile(`\w*(LimitExceeded|QuotaExceeded)`)
+
+// isQuotaExceeded returns whether the TerraForm call failed on a limit or a
+// quota of the cloud account. RequestLimitExceeded is throttling, not a quota.
+func isQuotaExceeded(err error) bool {
+	e, ok := err.(*errExecution)
+	if !ok {
+		return false
+	}
+	for _, code := range quotaErrorRegexp.FindAllString(e.stderr, -1) {
+		if code != "RequestLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrWithExitCode is returned by workflow steps whose failure has a distinct exit code.
+type ErrWithExitCode struct {
+	err  error
+	code int
+}
+
+// ErrWithExitCode implements the error interface.
+func (e *ErrWithExitCode) Error() string {
+	return e.err.Error()
+}
+
+func withExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*ErrWithExitCode); ok {
+		// keep the most specific classification
+		return err
+	}
+	return &ErrWithExitCode{err: err, code: code}
+}
+
+// ExitCode returns the code the installer should exit with for the given error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if e, ok := err.(*ErrWithExitCode); ok {
+		return e.code
+	}
+	return ExitCodeGeneric
+}
+
+// ErrorCode returns the code of the class of failure of the given error, or
+// an empty string if there is no error.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	return errorCodes[ExitCode(err)]
+}
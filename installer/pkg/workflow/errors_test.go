@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		test     string
+		err      error
+		expected int
+	}{
+		{
+			test:     "No error",
+			err:      nil,
+			expected: 0,
+		},
+		{
+			test:     "Unclassified error",
+			err:      errors.New("failed"),
+			expected: ExitCodeGeneric,
+		},
+		{
+			test:     "Classified error",
+			err:      withExitCode(errors.New("invalid"), ExitCodeValidation),
+			expected: ExitCodeValidation,
+		},
+		{
+			test:     "Reclassified error",
+			err:      withExitCode(withExitCode(errors.New("invalid"), ExitCodeValidation), ExitCodeProvisioning),
+			expected: ExitCodeValidation,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := ExitCode(tc.err); got != tc.expected {
+			t.Errorf("Test case %s: expected exit code: %d, got: %d", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestIsQuotaExceeded(t *testing.T) {
+	testCases := []struct {
+		test     string
+		err      error
+		expected bool
+	}{
+		{
+			test:     "Not a TerraForm error",
+			err:      errors.New("LimitExceeded"),
+			expected: false,
+		},
+		{
+			test:     "Other failure",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "* aws_instance.master: InvalidAMIID.NotFound"},
+			expected: false,
+		},
+		{
+			test:     "Throttling",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "* aws_instance.master: RequestLimitExceeded: Request limit exceeded."},
+			expected: false,
+		},
+		{
+			test:     "Limit exceeded",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "* aws_vpc.new_vpc: VpcLimitExceeded: The maximum number of VPCs has been reached."},
+			expected: true,
+		},
+		{
+			test:     "vCPU limit exceeded",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "* aws_instance.worker: VcpuLimitExceeded: You have requested more vCPU capacity than your current vCPU limit"},
+			expected: true,
+		},
+		{
+			test:     "Quota exceeded",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "* aws_s3_bucket.tnc: QuotaExceeded"},
+			expected: true,
+		},
+		{
+			test:     "Limit exceeded after throttling",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "RequestLimitExceeded\n* aws_eip.nat: AddressLimitExceeded"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := isQuotaExceeded(tc.err); got != tc.expected {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		test     string
+		err      error
+		expected string
+	}{
+		{
+			test:     "No error",
+			err:      nil,
+			expected: "",
+		},
+		{
+			test:     "Unclassified error",
+			err:      errors.New("failed"),
+			expected: "generic",
+		},
+		{
+			test:     "Classified error",
+			err:      withExitCode(errors.New("timed out"), ExitCodeBootstrapTimeout),
+			expected: "bootstrap-timeout",
+		},
+		{
+			test:     "Quota error",
+			err:      withExitCode(withExitCode(errors.New("VcpuLimitExceeded"), ExitCodeQuota), ExitCodeProvisioning),
+			expected: "quota",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := ErrorCode(tc.err); got != tc.expected {
+			t.Errorf("Test case %s: expected error code: %q, got: %q", tc.test, tc.expected, got)
+		}
+	}
+}
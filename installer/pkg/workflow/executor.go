@@ -1,12 +1,20 @@
 package workflow
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
 )
 
 // executor enables calling TerraForm from Go, across platforms, with any
@@ -17,6 +25,12 @@ import (
 // the current working directory or in the PATH.
 type executor struct {
 	binaryPath string
+	// stdout and stderr, if set, receive the output of TerraForm instead of
+	// the standard output and error.
+	stdout io.Writer
+	stderr io.Writer
+	// ctx, if set, interrupts TerraForm once it is canceled.
+	ctx context.Context
 }
 
 // Set the binary names for different platforms
@@ -25,6 +39,29 @@ const (
 	tfBinWindows = "terraform.exe"
 )
 
+var (
+	// progressInterval is how often the progress of a running TerraForm call is logged.
+	progressInterval = time.Minute
+	// killGracePeriod is how long an interrupted TerraForm call has to stop before being killed.
+	killGracePeriod = time.Minute
+)
+
+var (
+	runningMu sync.Mutex
+	// running are the TerraForm calls in progress.
+	running = map[*exec.Cmd]bool{}
+)
+
+// KillTerraform kills the TerraForm calls in progress, along with their
+// provider plugins, e.g. before the installer exits without waiting for them.
+func KillTerraform() {
+	runningMu.Lock()
+	defer runningMu.Unlock()
+	for cmd := range running {
+		killGroup(cmd)
+	}
+}
+
 // errBinaryNotFound denotes the fact that the TerraForm binary could not be
 // found on disk.
 var errBinaryNotFound = errors.New(
@@ -51,20 +88,147 @@ func newExecutor() (*executor, error) {
 // TerraForm call itself failed, in which case, details can be found in the
 // output.
 func (ex *executor) execute(clusterDir string, args ...string) error {
+	return ex.executeWithTimeout(clusterDir, 0, args...)
+}
+
+// executeWithTimeout is like execute, but interrupts TerraForm once the
+// timeout elapses, unless it is zero. The progress of long running calls
+// is logged periodically.
+func (ex *executor) executeWithTimeout(clusterDir string, timeout time.Duration, args ...string) error {
 	// Prepare TerraForm command by setting up the command, configuration,
 	// and the working directory
 	if clusterDir == "" {
 		return fmt.Errorf("clusterDir is unset. Quitting")
 	}
 
+	// Keep a copy of the errors, to tell transient failures apart.
+	var stderr bytes.Buffer
+	stdout, errOut := ex.stdout, ex.stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if errOut == nil {
+		errOut = os.Stderr
+	}
+	stdoutWriter := &redactWriter{w: stdout}
+	stderrWriter := &redactWriter{w: io.MultiWriter(errOut, &stderr)}
+	defer stdoutWriter.Flush()
 	cmd := exec.Command(ex.binaryPath, args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// TerraForm would otherwise be interrupted twice by Ctrl-C, which makes
+	// it exit without saving its state. In the background it cannot read
+	// the terminal, so it gets no input; none of its calls prompt anyway.
+	detach(cmd)
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
 	cmd.Dir = clusterDir
+	if _, ok := os.LookupEnv("AWS_SDK_LOAD_CONFIG"); !ok {
+		// let the AWS provider resolve the profiles of the shared config
+		// file too, such as those assuming a role or set up for SSO or a
+		// credential_process
+		cmd.Env = append(os.Environ(), "AWS_SDK_LOAD_CONFIG=1")
+	}
 
 	// Start TerraForm.
-	return cmd.Run()
+	runningMu.Lock()
+	if err := cmd.Start(); err != nil {
+		runningMu.Unlock()
+		return &errExecution{err: err, stderr: stderr.String()}
+	}
+	running[cmd] = true
+	runningMu.Unlock()
+	defer func() {
+		runningMu.Lock()
+		delete(running, cmd)
+		runningMu.Unlock()
+	}()
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	start := time.Now()
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	var deadline, kill <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var canceled <-chan struct{}
+	if ex.ctx != nil {
+		canceled = ex.ctx.Done()
+	}
+	var timedOut, interrupted bool
+	for {
+		select {
+		case err := <-done:
+			stderrWriter.Flush()
+			if timedOut {
+				err = fmt.Errorf("timed out after %s", timeout)
+			}
+			if interrupted {
+				err = errInterrupted
+			}
+			if err != nil {
+				return &errExecution{err: err, stderr: stderr.String()}
+			}
+			return nil
+		case <-ticker.C:
+			log.Infof("TerraForm %s still running after %s", args[0], time.Since(start).Round(time.Second))
+		case <-deadline:
+			log.Warnf("TerraForm %s did not complete within %s, interrupting it", args[0], timeout)
+			timedOut = true
+			deadline = nil
+			// Let TerraForm stop gracefully and save its state.
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				cmd.Process.Kill()
+			}
+			kill = time.After(killGracePeriod)
+		case <-canceled:
+			log.Warnf("Interrupting TerraForm %s, waiting for it to save its state...", args[0])
+			interrupted = true
+			canceled = nil
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				cmd.Process.Kill()
+			}
+			kill = time.After(killGracePeriod)
+		case <-kill:
+			killGroup(cmd)
+		}
+	}
+}
+
+// errExecution is returned when the TerraForm call itself failed.
+type errExecution struct {
+	err    error
+	stderr string
+}
+
+// errExecution implements the error interface.
+func (e *errExecution) Error() string {
+	reasons := tfErrorReasons(e.stderr)
+	if len(reasons) == 0 {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.err, strings.Join(reasons, "; "))
+}
+
+// tfErrorReasons returns the individual errors reported by TerraForm, which
+// lists them as "* <resource>: <error>", skipping the summaries of nested errors.
+func tfErrorReasons(stderr string) []string {
+	var reasons []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "* ") || strings.HasSuffix(line, "error(s) occurred:") {
+			continue
+		}
+		reason := strings.TrimPrefix(line, "* ")
+		if !seen[reason] {
+			seen[reason] = true
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
 }
 
 // tfBinatyPath searches for a TerraForm binary on disk:
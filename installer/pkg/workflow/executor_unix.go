@@ -0,0 +1,22 @@
+// +build !windows
+
+package workflow
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs the command in its own process group, so that an interrupt from
+// the terminal only reaches the installer, which interrupts the command once.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killGroup kills the process group of the detached command, i.e. TerraForm
+// and the provider plugins it started.
+func killGroup(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,12 @@
+package workflow
+
+import "os/exec"
+
+// detach is a no-op on Windows, where console interrupts are not sent to
+// process groups the same way.
+func detach(cmd *exec.Cmd) {}
+
+// killGroup kills the command.
+func killGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
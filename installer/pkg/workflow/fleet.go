@@ -0,0 +1,187 @@
+package workflow
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// fleetLogFileName is the file of each cluster directory receiving the
+// TerraForm output of the fleet workflows, which would interleave otherwise.
+const fleetLogFileName = "fleet.log"
+
+// fleetCluster is a cluster of a fleet, configured by configFilePath.
+type fleetCluster struct {
+	configFilePath string
+	name           string
+	clusterDir     string
+}
+
+// FleetInstallWorkflow creates new instances of the 'fleet install' workflow,
+// responsible for creating a cluster for every config file of configDir, up to
+// parallel at once, or all at once if parallel is 0.
+// Each cluster is initialized in the current directory, like with 'init', unless
+// its directory already exists, in which case its install is resumed.
+// The clusters share the cloud credentials of the environment.
+func FleetInstallWorkflow(configDir string, parallel int) Workflow {
+	return Workflow{
+		metadata: metadata{configFilePath: configDir},
+		steps: []Step{
+			func(m *metadata) error {
+				return fleetStep(m, parallel, "install", initFleetCluster, func(c fleetCluster) Workflow {
+					return InstallFullWorkflow(c.clusterDir, false)
+				})
+			},
+		},
+	}
+}
+
+// FleetDestroyWorkflow creates new instances of the 'fleet destroy' workflow,
+// responsible for destroying the clusters of the config files of configDir,
+// initialized in the current directory by 'fleet install', up to parallel at
+// once, or all at once if parallel is 0.
+func FleetDestroyWorkflow(configDir string, parallel int, timeout time.Duration) Workflow {
+	return Workflow{
+		metadata: metadata{configFilePath: configDir},
+		steps: []Step{
+			func(m *metadata) error {
+				return fleetStep(m, parallel, "destroy", nil, func(c fleetCluster) Workflow {
+					return DestroyWorkflow(c.clusterDir, timeout, 0, nil)
+				})
+			},
+		},
+	}
+}
+
+// fleetStep runs the workflow returned by run for every cluster of the fleet,
+// once prepared by prepare, if set. It keeps going when some clusters fail,
+// and then returns an error listing them.
+func fleetStep(m *metadata, parallel int, action string, prepare func(*metadata, fleetCluster) error, run func(fleetCluster) Workflow) error {
+	clusters, err := readFleet(m.configFilePath)
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no config file (*.yaml or *.yml) found in %s", m.configFilePath)
+	}
+	if parallel <= 0 || parallel > len(clusters) {
+		parallel = len(clusters)
+	}
+
+	errs := make([]error, len(clusters))
+	// the clusters are prepared serially, in the order of their config files,
+	// so that, e.g., conflicting cluster IDs are reported deterministically
+	if prepare != nil {
+		for i, c := range clusters {
+			errs[i] = prepare(m, c)
+		}
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallel)
+	for i, c := range clusters {
+		if errs[i] != nil {
+			continue
+		}
+		if _, err := os.Stat(c.clusterDir); err != nil {
+			errs[i] = fmt.Errorf("no cluster directory: %v", err)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c fleetCluster) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			errs[i] = runFleetCluster(m, c, action, run(c))
+		}(i, c)
+	}
+	wg.Wait()
+
+	var failed []string
+	var first error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		log.Errorf("Failed to %s cluster %s: %v", action, clusters[i].name, err)
+		failed = append(failed, clusters[i].name)
+		if first == nil {
+			first = err
+		}
+	}
+	if first != nil {
+		return withExitCode(fmt.Errorf("failed to %s %d of %d clusters: %s", action, len(failed), len(clusters), strings.Join(failed, ", ")), ExitCode(first))
+	}
+	log.Infof("All %d clusters done (%s)", len(clusters), action)
+	return nil
+}
+
+// runFleetCluster runs the workflow w for the cluster c of the fleet, sending
+// its TerraForm output to the fleet log of the cluster directory.
+func runFleetCluster(m *metadata, c fleetCluster, action string, w Workflow) error {
+	logFile := filepath.Join(c.clusterDir, fleetLogFileName)
+	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	log.Infof("Running %s for cluster %s (output in %s)...", action, c.name, logFile)
+	w.SetOutput(f, f)
+	if err := w.ExecuteContext(m.context()); err != nil {
+		return err
+	}
+	log.Infof("Cluster %s done (%s)", c.name, action)
+	return nil
+}
+
+// initFleetCluster initializes the cluster directory of c, unless it exists.
+func initFleetCluster(m *metadata, c fleetCluster) error {
+	if _, err := os.Stat(c.clusterDir); err == nil {
+		log.Infof("Cluster directory %s already exists, resuming its install", c.clusterDir)
+		return nil
+	}
+	return InitWorkflow(c.configFilePath, false).ExecuteContext(m.context())
+}
+
+// readFleet returns the clusters of the config files of configDir, sorted by
+// file name, with their directories in the current directory.
+func readFleet(configDir string) ([]fleetCluster, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+	entries, err := ioutil.ReadDir(configDir)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []fleetCluster
+	names := map[string]string{}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(configDir, e.Name())
+		cluster, err := readClusterConfig(path, "")
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := names[cluster.Name]; ok {
+			return nil, withExitCode(fmt.Errorf("%s and %s both configure cluster %s", other, path, cluster.Name), ExitCodeValidation)
+		}
+		names[cluster.Name] = path
+		clusters = append(clusters, fleetCluster{
+			configFilePath: path,
+			name:           cluster.Name,
+			clusterDir:     filepath.Join(dir, cluster.Name),
+		})
+	}
+	return clusters, nil
+}
@@ -0,0 +1,101 @@
+package workflow
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeFleet writes to configDir the given config files, configuring the
+// given clusters, based on the basic AWS fixture.
+func writeFleet(t *testing.T, configDir string, files map[string]string) {
+	fixture, err := ioutil.ReadFile("fixtures/aws.basic.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, name := range files {
+		data := strings.Replace(string(fixture), "name: aws-basic", "name: "+name, 1)
+		if err := ioutil.WriteFile(filepath.Join(configDir, file), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFleetStep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	configDir := filepath.Join(dir, "configs")
+	writeFleet(t, configDir, map[string]string{"a.yaml": "fleet-a", "b.yml": "fleet-b", "c.yaml": "fleet-c"})
+	if err := ioutil.WriteFile(filepath.Join(configDir, "README"), []byte("not a config"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	// fleet-c was never initialized
+	for _, name := range []string{"fleet-a", "fleet-b"} {
+		if err := os.Mkdir(name, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	ran := map[string]bool{}
+	m := &metadata{configFilePath: configDir}
+	err = fleetStep(m, 1, "install", nil, func(c fleetCluster) Workflow {
+		return Workflow{steps: []Step{func(m *metadata) error {
+			mu.Lock()
+			ran[c.name] = true
+			mu.Unlock()
+			fmt.Fprintf(m.stdout, "output of %s\n", c.name)
+			if c.name == "fleet-b" {
+				return withExitCode(fmt.Errorf("failed"), ExitCodeProvisioning)
+			}
+			return nil
+		}}}
+	})
+
+	expected := "failed to install 2 of 3 clusters: fleet-b, fleet-c"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Test case fleet: expected: %v, got: %v", expected, err)
+	}
+	if code := ExitCode(err); code != ExitCodeProvisioning {
+		t.Errorf("Test case fleet: expected exit code: %d, got: %d", ExitCodeProvisioning, code)
+	}
+	if !ran["fleet-a"] || !ran["fleet-b"] || ran["fleet-c"] {
+		t.Errorf("Test case fleet: expected fleet-a and fleet-b to run, got: %v", ran)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "fleet-a", fleetLogFileName))
+	if err != nil || string(data) != "output of fleet-a\n" {
+		t.Errorf("Test case fleet: expected the output of fleet-a in its log, got: %q, %v", data, err)
+	}
+}
+
+func TestReadFleetDuplicate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFleet(t, dir, map[string]string{"a.yaml": "fleet-a", "b.yaml": "fleet-a"})
+
+	_, err = readFleet(dir)
+	if ExitCode(err) != ExitCodeValidation || !strings.Contains(err.Error(), "both configure cluster fleet-a") {
+		t.Errorf("Test case duplicate: expected: duplicate cluster error, got: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package workflow
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+const sshUser = "core"
+
+// remoteLogs maps the name of each gathered file to the command producing it on a cluster node.
+var remoteLogs = map[string]string{
+	"journal.log":    "journalctl --boot --no-pager",
+	"bootkube.log":   "journalctl --boot --no-pager --unit=bootkube.service",
+	"kubelet.log":    "journalctl --boot --no-pager --unit=kubelet.service",
+	"tectonic.log":   "journalctl --boot --no-pager --unit=tectonic.service",
+	"containers.log": "sudo sh -c 'for c in $(docker ps --all --quiet); do echo \"### ${c}\"; docker inspect --format \"{{.Name}} {{.Config.Image}} {{.State.Status}}\" ${c}; docker logs --tail=1000 ${c} 2>&1; done'",
+}
+
+// GatherBootstrapWorkflow creates new instances of the 'gather bootstrap' workflow,
+// responsible for collecting, over SSH, the logs of the bootstrap node and optionally
+// of the other masters into a redacted tarball, for debugging hung installs.
+// Once the API is up, the nodes, the pods and the logs of the failing
+// containers are read from it into the tarball too.
+func GatherBootstrapWorkflow(clusterDir, bootstrap string, masters []string) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			readClusterConfigStep,
+			func(m *metadata) error {
+				return gatherStep(m, append([]string{bootstrap}, masters...))
+			},
+		},
+	}
+}
+
+func gatherStep(m *metadata, hosts []string) error {
+	files := map[string][]byte{}
+	for _, host := range hosts {
+		log.Infof("Gathering logs from %s...", host)
+		for name, command := range remoteLogs {
+			out, err := sshOutput(host, command)
+			if err != nil {
+				// keep going: partial logs are better than none when a node is unhealthy
+				log.Warnf("Failed to gather %s from %s: %v", name, host, err)
+			}
+			files[filepath.Join(host, name)] = out
+		}
+	}
+	gatherFromAPI(m, files)
+
+	ignFile := filepath.Join(m.clusterDir, config.IgnitionMaster)
+	if ign, err := ioutil.ReadFile(ignFile); err == nil {
+		files[config.IgnitionMaster] = ign
+	} else {
+		log.Warnf("Failed to read the rendered ignition config %s: %v", ignFile, err)
+	}
+
+	for name, data := range files {
+		files[name] = redactSecrets(data)
+	}
+
+	archive := filepath.Join(m.clusterDir, fmt.Sprintf("gather-bootstrap-%s.tar.gz", time.Now().UTC().Format("20060102150405")))
+	if err := writeTarball(archive, files); err != nil {
+		return fmt.Errorf("failed to write %s: %v", archive, err)
+	}
+	log.Infof("Logs gathered in %s", archive)
+	return nil
+}
+
+func sshOutput(host, command string) ([]byte, error) {
+	cmd := exec.Command("ssh", "-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=no", fmt.Sprintf("%s@%s", sshUser, host), command)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
+
+// writeTarball writes the files to a new gzipped tarball, sorted by name.
+func writeTarball(path string, files map[string][]byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data := files[name]
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
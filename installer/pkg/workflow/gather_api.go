@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// gatherLogLines bounds the lines gathered from the log of each container.
+const gatherLogLines = 1000
+
+// podList holds the fields of the pods used to find the failing ones.
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type pod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers []struct {
+			Name string `json:"name"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string `json:"phase"`
+		ContainerStatuses []struct {
+			Name         string `json:"name"`
+			Ready        bool   `json:"ready"`
+			RestartCount int    `json:"restartCount"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// failingContainers returns the containers of the pod which are not ready,
+// or restarted, with whether they did restart, unless the pod completed.
+func (p pod) failingContainers() map[string]bool {
+	failing := map[string]bool{}
+	if p.Status.Phase == "Succeeded" {
+		return failing
+	}
+	statuses := map[string]bool{}
+	for _, s := range p.Status.ContainerStatuses {
+		statuses[s.Name] = true
+		if !s.Ready || s.RestartCount > 0 {
+			failing[s.Name] = s.RestartCount > 0
+		}
+	}
+	for _, c := range p.Spec.Containers {
+		if !statuses[c.Name] {
+			// not even started, e.g. pending on its image
+			failing[c.Name] = false
+		}
+	}
+	return failing
+}
+
+// gatherFromAPI adds to files the nodes and pods of the cluster, and the logs
+// of the failing containers, read from its API, when it is up.
+func gatherFromAPI(m *metadata, files map[string][]byte) {
+	client, err := apiClient(m.clusterDir)
+	if err != nil {
+		log.Warnf("Not gathering from the API: %v", err)
+		return
+	}
+	base := fmt.Sprintf("https://%s:%d", m.cluster.APIHost(), m.cluster.Networking.APIPort)
+	if err := checkURL(client, base+"/healthz", "ok"); err != nil {
+		log.Infof("The API is not up, not gathering from it: %v", err)
+		return
+	}
+	log.Info("Gathering from the API...")
+
+	for name, p := range map[string]string{"nodes.json": "/api/v1/nodes", "pods.json": "/api/v1/pods"} {
+		data, err := apiGet(client, base+p)
+		if err != nil {
+			log.Warnf("Failed to gather %s from the API: %v", name, err)
+			continue
+		}
+		files[path.Join("api", name)] = data
+	}
+
+	data, ok := files[path.Join("api", "pods.json")]
+	if !ok {
+		return
+	}
+	var pods podList
+	if err := json.Unmarshal(data, &pods); err != nil {
+		log.Warnf("Failed to parse the pods: %v", err)
+		return
+	}
+	for _, p := range pods.Items {
+		for container, restarted := range p.failingContainers() {
+			name := path.Join("api", "pods", p.Metadata.Namespace, p.Metadata.Name, container+".log")
+			files[name] = containerLog(client, base, p, container, false)
+			if restarted {
+				name = path.Join("api", "pods", p.Metadata.Namespace, p.Metadata.Name, container+".previous.log")
+				files[name] = containerLog(client, base, p, container, true)
+			}
+		}
+	}
+}
+
+// containerLog returns the end of the log of the container, or why it could not be read.
+func containerLog(client *http.Client, base string, p pod, container string, previous bool) []byte {
+	query := url.Values{
+		"container": {container},
+		"tailLines": {fmt.Sprint(gatherLogLines)},
+	}
+	if previous {
+		query.Set("previous", "true")
+	}
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/log?%s", base, p.Metadata.Namespace, p.Metadata.Name, query.Encode())
+	data, err := apiGet(client, u)
+	if err != nil {
+		// keep going: the error is useful in the bundle too
+		return []byte(err.Error())
+	}
+	return data
+}
+
+func apiGet(client *http.Client, u string) ([]byte, error) {
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+	return body, nil
+}
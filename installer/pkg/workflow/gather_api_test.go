@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFailingContainers(t *testing.T) {
+	testCases := []struct {
+		test     string
+		pod      string
+		expected map[string]bool
+	}{
+		{
+			test:     "Running",
+			pod:      `{"spec": {"containers": [{"name": "a"}]}, "status": {"phase": "Running", "containerStatuses": [{"name": "a", "ready": true}]}}`,
+			expected: map[string]bool{},
+		},
+		{
+			test:     "Succeeded",
+			pod:      `{"spec": {"containers": [{"name": "a"}]}, "status": {"phase": "Succeeded", "containerStatuses": [{"name": "a", "ready": false}]}}`,
+			expected: map[string]bool{},
+		},
+		{
+			test:     "Crash looping",
+			pod:      `{"spec": {"containers": [{"name": "a"}, {"name": "b"}]}, "status": {"phase": "Running", "containerStatuses": [{"name": "a", "ready": true}, {"name": "b", "ready": false, "restartCount": 3}]}}`,
+			expected: map[string]bool{"b": true},
+		},
+		{
+			test:     "Pending",
+			pod:      `{"spec": {"containers": [{"name": "a"}]}, "status": {"phase": "Pending"}}`,
+			expected: map[string]bool{"a": false},
+		},
+	}
+
+	for _, tc := range testCases {
+		var p pod
+		if err := json.Unmarshal([]byte(tc.pod), &p); err != nil {
+			t.Fatalf("Test case %s: failed to parse pod: %v", tc.test, err)
+		}
+		if got := p.failingContainers(); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
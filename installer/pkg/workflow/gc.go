@@ -0,0 +1,707 @@
+package workflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// clusterIDTagKey is the tag of the AWS resources of a cluster set to its ID.
+const clusterIDTagKey = "tectonicClusterID"
+
+// clusterNameTagPrefix prefixes the tag of the AWS resources of a cluster
+// naming it, e.g. kubernetes.io/cluster/test.
+const clusterNameTagPrefix = "kubernetes.io/cluster/"
+
+// awsCLI runs the given AWS CLI command in region and returns its output.
+var awsCLI = func(region string, args ...string) ([]byte, error) {
+	out, err := exec.Command("aws", append([]string{"--region", region, "--output", "json"}, args...)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to run aws %s: %v: %s", strings.Join(args, " "), err, exitErr.Stderr)
+		}
+		return nil, fmt.Errorf("failed to run aws %s: %v", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// iamNameRegexp matches the names of the IAM roles and instance profiles of
+// the masters, workers and etcd nodes of a cluster, which are not tagged.
+var iamNameRegexp = regexp.MustCompile(`^(.+)-(master|worker|etcd)-(role|profile)$`)
+
+// launchConfigurationNameRegexp matches the names of the launch configurations
+// of the masters and workers of a cluster, which cannot be tagged.
+var launchConfigurationNameRegexp = regexp.MustCompile(`^(.+)-(master|worker)-[0-9]+$`)
+
+// orphanedCluster is a cluster with resources in the AWS account but no
+// cluster directory.
+type orphanedCluster struct {
+	id      string
+	name    string
+	arns    []string
+	records []route53Record
+}
+
+// route53Record is a record of a cluster in a hosted zone it does not own,
+// e.g. the public zone of the base domain.
+type route53Record struct {
+	zoneID string
+	name   string
+	typ    string
+	set    json.RawMessage
+}
+
+// taggedResource is an AWS resource with the ID and name of its cluster.
+type taggedResource struct {
+	arn  string
+	id   string
+	name string
+}
+
+// awsTag is the tag of an AWS resource, in the output of the AWS CLI.
+type awsTag struct {
+	Key   string
+	Value string
+}
+
+// clusterTags returns the ID and name of the cluster the given tags are of.
+func clusterTags(tags []awsTag) (id, name string) {
+	for _, tag := range tags {
+		switch {
+		case tag.Key == clusterIDTagKey:
+			id = tag.Value
+		case strings.HasPrefix(tag.Key, clusterNameTagPrefix):
+			name = strings.TrimPrefix(tag.Key, clusterNameTagPrefix)
+		}
+	}
+	return id, name
+}
+
+// GCWorkflow creates new instances of the 'gc' workflow, responsible for
+// listing the AWS resources of region tagged for clusters which are not
+// initialized in dir, e.g. left over by failed CI runs, and, with delete set,
+// deleting them once confirmed, cluster by cluster.
+func GCWorkflow(dir, region string, delete bool) Workflow {
+	return Workflow{
+		metadata: metadata{},
+		steps: []Step{
+			func(m *metadata) error {
+				return gcStep(dir, region, delete)
+			},
+		},
+	}
+}
+
+func gcStep(dir, region string, delete bool) error {
+	used, err := clusterIDs(dir)
+	if err != nil {
+		return err
+	}
+	if len(used) == 0 {
+		log.Warnf("No clusters are initialized in %s, the resources of every cluster of %s are listed", dir, region)
+	}
+	orphans, err := findOrphanedClusters(region, used)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		log.Infof("No resources of clusters missing from %s found in %s", dir, region)
+		return nil
+	}
+
+	for _, c := range orphans {
+		fmt.Printf("Cluster %s (%s), not initialized in %s:\n", c.name, c.id, dir)
+		for _, arn := range c.arns {
+			fmt.Printf("  %s\n", arn)
+		}
+		for _, r := range c.records {
+			fmt.Printf("  %s record %s of hosted zone %s\n", r.typ, r.name, r.zoneID)
+		}
+	}
+	if !delete {
+		log.Info("Run gc again with --delete to delete them")
+		return nil
+	}
+
+	// one reader for every answer, so that none is lost to the buffer of another
+	in := bufio.NewReader(os.Stdin)
+	var failed int
+	for _, c := range orphans {
+		if confirm(in, fmt.Sprintf("Delete the %d resources of cluster %s (%s)?", len(c.arns)+len(c.records), c.name, c.id)) != nil {
+			continue
+		}
+		failed += deleteRoute53Records(region, c.records)
+		failed += deleteAWSResources(region, c.arns)
+	}
+	if failed != 0 {
+		return fmt.Errorf("failed to delete %d resources; run gc again once the resources they depend on are deleted, or delete them manually", failed)
+	}
+	return nil
+}
+
+// destroyAWSLeftoversStep deletes the AWS resources of a cluster destroyed from
+// its metadata, without any terraform state: those gc would find for it.
+func destroyAWSLeftoversStep(m *metadata) error {
+	if !m.fromMetadata || m.cluster.Platform != config.PlatformAWS {
+		return nil
+	}
+	region := m.cluster.AWS.Region
+	clusters, err := findOrphanedClusters(region, nil)
+	if err != nil {
+		return err
+	}
+	for _, c := range clusters {
+		if c.id != m.cluster.ClusterID {
+			continue
+		}
+		log.Infof("Deleting the %d resources of cluster %s (%s)", len(c.arns)+len(c.records), m.cluster.Name, c.id)
+		if failed := deleteRoute53Records(region, c.records) + deleteAWSResources(region, c.arns); failed != 0 {
+			return fmt.Errorf("failed to delete %d resources; run destroy again once the resources they depend on are deleted, or delete them manually", failed)
+		}
+		return nil
+	}
+	log.Infof("No resources of cluster %s (%s) found in %s", m.cluster.Name, m.cluster.ClusterID, region)
+	return nil
+}
+
+// findOrphanedClusters returns the clusters, sorted by ID, with resources in
+// region but whose ID is not in used. Besides the tagged resources, those
+// named after the clusters are included, unless a cluster in used has the
+// same name: IAM roles and instance profiles, launch configurations and the
+// records of the clusters in hosted zones they do not own.
+func findOrphanedClusters(region string, used map[string]string) ([]orphanedCluster, error) {
+	resources, err := taggedResources(region)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := autoScalingResources(region)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, groups...)
+
+	clusters := map[string]*orphanedCluster{}
+	arns := map[string]bool{}
+	live := map[string]bool{}
+	for _, r := range resources {
+		if _, ok := used[r.id]; ok {
+			live[r.name] = true
+			continue
+		}
+		c, ok := clusters[r.id]
+		if !ok {
+			c = &orphanedCluster{id: r.id, name: "unnamed"}
+			clusters[r.id] = c
+		}
+		if r.name != "" {
+			c.name = r.name
+		}
+		if !arns[r.arn] {
+			arns[r.arn] = true
+			c.arns = append(c.arns, r.arn)
+		}
+	}
+
+	var orphans []*orphanedCluster
+	for _, c := range clusters {
+		orphans = append(orphans, c)
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].id < orphans[j].id })
+
+	// the resources named after a cluster go to the first orphan of that name
+	named := map[string]*orphanedCluster{}
+	for _, c := range orphans {
+		if _, ok := named[c.name]; !ok && c.name != "unnamed" && !live[c.name] {
+			named[c.name] = c
+		}
+	}
+	if len(named) != 0 {
+		if err := addNamedResources(region, named, arns); err != nil {
+			return nil, err
+		}
+		if err := addRoute53Records(region, named, arns); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []orphanedCluster
+	for _, c := range orphans {
+		sort.Strings(c.arns)
+		result = append(result, *c)
+	}
+	return result, nil
+}
+
+// taggedResources returns the resources of region tagged with a cluster ID.
+func taggedResources(region string) ([]taggedResource, error) {
+	out, err := awsCLI(region, "resourcegroupstaggingapi", "get-resources", "--tag-filters", "Key="+clusterIDTagKey)
+	if err != nil {
+		return nil, err
+	}
+	var resources struct {
+		ResourceTagMappingList []struct {
+			ResourceARN string
+			Tags        []awsTag
+		}
+	}
+	if err := json.Unmarshal(out, &resources); err != nil {
+		return nil, fmt.Errorf("failed to parse tagged resources: %v", err)
+	}
+
+	var tagged []taggedResource
+	for _, r := range resources.ResourceTagMappingList {
+		id, name := clusterTags(r.Tags)
+		tagged = append(tagged, taggedResource{arn: r.ResourceARN, id: id, name: name})
+	}
+	return tagged, nil
+}
+
+// autoScalingResources returns the Auto Scaling groups of region tagged with
+// a cluster ID, which the tagging API does not list, and their launch
+// configurations.
+func autoScalingResources(region string) ([]taggedResource, error) {
+	out, err := awsCLI(region, "autoscaling", "describe-auto-scaling-groups")
+	if err != nil {
+		return nil, err
+	}
+	var groups struct {
+		AutoScalingGroups []struct {
+			AutoScalingGroupARN     string
+			LaunchConfigurationName string
+			Tags                    []awsTag
+		}
+	}
+	if err := json.Unmarshal(out, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse Auto Scaling groups: %v", err)
+	}
+	configurations, err := launchConfigurations(region)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []taggedResource
+	for _, g := range groups.AutoScalingGroups {
+		id, name := clusterTags(g.Tags)
+		if id == "" {
+			continue
+		}
+		resources = append(resources, taggedResource{arn: g.AutoScalingGroupARN, id: id, name: name})
+		if arn, ok := configurations[g.LaunchConfigurationName]; ok {
+			resources = append(resources, taggedResource{arn: arn, id: id, name: name})
+		}
+	}
+	return resources, nil
+}
+
+// launchConfigurations returns the ARNs of the launch configurations of region by name.
+func launchConfigurations(region string) (map[string]string, error) {
+	out, err := awsCLI(region, "autoscaling", "describe-launch-configurations")
+	if err != nil {
+		return nil, err
+	}
+	var configurations struct {
+		LaunchConfigurations []struct {
+			LaunchConfigurationName string
+			LaunchConfigurationARN  string
+		}
+	}
+	if err := json.Unmarshal(out, &configurations); err != nil {
+		return nil, fmt.Errorf("failed to parse launch configurations: %v", err)
+	}
+	arns := map[string]string{}
+	for _, c := range configurations.LaunchConfigurations {
+		arns[c.LaunchConfigurationName] = c.LaunchConfigurationARN
+	}
+	return arns, nil
+}
+
+// addNamedResources adds the IAM roles and instance profiles, and the launch
+// configurations left by deleted Auto Scaling groups, of the named clusters.
+func addNamedResources(region string, named map[string]*orphanedCluster, arns map[string]bool) error {
+	add := func(re *regexp.Regexp, name, arn string) {
+		m := re.FindStringSubmatch(name)
+		if m == nil {
+			return
+		}
+		if c, ok := named[m[1]]; ok && !arns[arn] {
+			arns[arn] = true
+			c.arns = append(c.arns, arn)
+		}
+	}
+
+	out, err := awsCLI(region, "iam", "list-roles")
+	if err != nil {
+		return err
+	}
+	var roles struct {
+		Roles []struct {
+			RoleName string
+			Arn      string
+		}
+	}
+	if err := json.Unmarshal(out, &roles); err != nil {
+		return fmt.Errorf("failed to parse IAM roles: %v", err)
+	}
+	for _, r := range roles.Roles {
+		add(iamNameRegexp, r.RoleName, r.Arn)
+	}
+
+	out, err = awsCLI(region, "iam", "list-instance-profiles")
+	if err != nil {
+		return err
+	}
+	var profiles struct {
+		InstanceProfiles []struct {
+			InstanceProfileName string
+			Arn                 string
+		}
+	}
+	if err := json.Unmarshal(out, &profiles); err != nil {
+		return fmt.Errorf("failed to parse IAM instance profiles: %v", err)
+	}
+	for _, p := range profiles.InstanceProfiles {
+		add(iamNameRegexp, p.InstanceProfileName, p.Arn)
+	}
+
+	configurations, err := launchConfigurations(region)
+	if err != nil {
+		return err
+	}
+	for name, arn := range configurations {
+		add(launchConfigurationNameRegexp, name, arn)
+	}
+	return nil
+}
+
+// recordNameRegexp returns the regular expression matching the names of the
+// records of the cluster of the given name, e.g. test-api.example.com.
+func recordNameRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile(`^(\\052\.)?` + regexp.QuoteMeta(name) + `(-api|-tnc|-k8s|-master-[0-9]+|-worker-[0-9]+(-public)?)?\.`)
+}
+
+// addRoute53Records adds the records of the named clusters in the hosted
+// zones not deleted with the clusters, e.g. the public zone of the base domain.
+func addRoute53Records(region string, named map[string]*orphanedCluster, arns map[string]bool) error {
+	out, err := awsCLI(region, "route53", "list-hosted-zones")
+	if err != nil {
+		return err
+	}
+	var zones struct {
+		HostedZones []struct {
+			Id string
+		}
+	}
+	if err := json.Unmarshal(out, &zones); err != nil {
+		return fmt.Errorf("failed to parse hosted zones: %v", err)
+	}
+
+	var names []string
+	regexps := map[string]*regexp.Regexp{}
+	for name := range named {
+		names = append(names, name)
+		regexps[name] = recordNameRegexp(name)
+	}
+	sort.Strings(names)
+
+	for _, z := range zones.HostedZones {
+		id := strings.TrimPrefix(z.Id, "/hostedzone/")
+		if arns["arn:aws:route53:::hostedzone/"+id] {
+			// deleted with all of its records
+			continue
+		}
+		sets, err := recordSets(region, id)
+		if err != nil {
+			return err
+		}
+		for _, set := range sets {
+			for _, name := range names {
+				if regexps[name].MatchString(set.name) {
+					c := named[name]
+					c.records = append(c.records, set)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// recordSets returns the records of the hosted zone of the given ID.
+func recordSets(region, zoneID string) ([]route53Record, error) {
+	out, err := awsCLI(region, "route53", "list-resource-record-sets", "--hosted-zone-id", zoneID)
+	if err != nil {
+		return nil, err
+	}
+	var sets struct {
+		ResourceRecordSets []json.RawMessage
+	}
+	if err := json.Unmarshal(out, &sets); err != nil {
+		return nil, fmt.Errorf("failed to parse the records of hosted zone %s: %v", zoneID, err)
+	}
+	var records []route53Record
+	for _, raw := range sets.ResourceRecordSets {
+		var set struct {
+			Name string
+			Type string
+		}
+		if err := json.Unmarshal(raw, &set); err != nil {
+			return nil, fmt.Errorf("failed to parse the records of hosted zone %s: %v", zoneID, err)
+		}
+		records = append(records, route53Record{zoneID: zoneID, name: set.Name, typ: set.Type, set: raw})
+	}
+	return records, nil
+}
+
+// deleteRecords deletes the given records of the hosted zone of the given ID at once.
+func deleteRecords(region, zoneID string, records []route53Record) error {
+	type change struct {
+		Action            string
+		ResourceRecordSet json.RawMessage
+	}
+	var batch struct {
+		Changes []change
+	}
+	for _, r := range records {
+		batch.Changes = append(batch.Changes, change{Action: "DELETE", ResourceRecordSet: r.set})
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	_, err = awsCLI(region, "route53", "change-resource-record-sets", "--hosted-zone-id", zoneID, "--change-batch", string(data))
+	return err
+}
+
+// deleteRoute53Records deletes the given records, zone by zone, and returns
+// the number of those which could not be deleted.
+func deleteRoute53Records(region string, records []route53Record) int {
+	var zones []string
+	byZone := map[string][]route53Record{}
+	for _, r := range records {
+		if _, ok := byZone[r.zoneID]; !ok {
+			zones = append(zones, r.zoneID)
+		}
+		byZone[r.zoneID] = append(byZone[r.zoneID], r)
+	}
+
+	var failed int
+	for _, zone := range zones {
+		log.Infof("Deleting %d records of hosted zone %s", len(byZone[zone]), zone)
+		if err := deleteRecords(region, zone, byZone[zone]); err != nil {
+			log.Errorf("Failed to delete the records of hosted zone %s: %v", zone, err)
+			failed += len(byZone[zone])
+		}
+	}
+	return failed
+}
+
+// deleteHostedZone deletes the hosted zone of the given ID with its records,
+// but for the NS and SOA ones of its apex, which go with it.
+func deleteHostedZone(region, arn, id string) error {
+	sets, err := recordSets(region, id)
+	if err != nil {
+		return err
+	}
+	var apex string
+	for _, set := range sets {
+		if set.typ == "SOA" {
+			apex = set.name
+		}
+	}
+	var records []route53Record
+	for _, set := range sets {
+		if set.name == apex && (set.typ == "SOA" || set.typ == "NS") {
+			continue
+		}
+		records = append(records, set)
+	}
+	if len(records) != 0 {
+		if err := deleteRecords(region, id, records); err != nil {
+			return err
+		}
+	}
+	_, err = awsCLI(region, "route53", "delete-hosted-zone", "--id", id)
+	return err
+}
+
+// deleteIAMRole deletes the IAM role of the given name with its policies.
+func deleteIAMRole(region, arn, name string) error {
+	out, err := awsCLI(region, "iam", "list-role-policies", "--role-name", name)
+	if err != nil {
+		return err
+	}
+	var inline struct {
+		PolicyNames []string
+	}
+	if err := json.Unmarshal(out, &inline); err != nil {
+		return fmt.Errorf("failed to parse the policies of IAM role %s: %v", name, err)
+	}
+	for _, policy := range inline.PolicyNames {
+		if _, err := awsCLI(region, "iam", "delete-role-policy", "--role-name", name, "--policy-name", policy); err != nil {
+			return err
+		}
+	}
+
+	out, err = awsCLI(region, "iam", "list-attached-role-policies", "--role-name", name)
+	if err != nil {
+		return err
+	}
+	var attached struct {
+		AttachedPolicies []struct {
+			PolicyArn string
+		}
+	}
+	if err := json.Unmarshal(out, &attached); err != nil {
+		return fmt.Errorf("failed to parse the attached policies of IAM role %s: %v", name, err)
+	}
+	for _, policy := range attached.AttachedPolicies {
+		if _, err := awsCLI(region, "iam", "detach-role-policy", "--role-name", name, "--policy-arn", policy.PolicyArn); err != nil {
+			return err
+		}
+	}
+
+	_, err = awsCLI(region, "iam", "delete-role", "--role-name", name)
+	return err
+}
+
+// deleteInstanceProfile deletes the IAM instance profile of the given name,
+// once its roles are removed from it.
+func deleteInstanceProfile(region, arn, name string) error {
+	out, err := awsCLI(region, "iam", "get-instance-profile", "--instance-profile-name", name)
+	if err != nil {
+		return err
+	}
+	var profile struct {
+		InstanceProfile struct {
+			Roles []struct {
+				RoleName string
+			}
+		}
+	}
+	if err := json.Unmarshal(out, &profile); err != nil {
+		return fmt.Errorf("failed to parse IAM instance profile %s: %v", name, err)
+	}
+	for _, role := range profile.InstanceProfile.Roles {
+		if _, err := awsCLI(region, "iam", "remove-role-from-instance-profile", "--instance-profile-name", name, "--role-name", role.RoleName); err != nil {
+			return err
+		}
+	}
+	_, err = awsCLI(region, "iam", "delete-instance-profile", "--instance-profile-name", name)
+	return err
+}
+
+// awsCommand returns the deletion running the AWS CLI command built by args
+// from the ARN and ID of the resource.
+func awsCommand(args func(arn, id string) []string) func(region, arn, id string) error {
+	return func(region, arn, id string) error {
+		_, err := awsCLI(region, args(arn, id)...)
+		return err
+	}
+}
+
+// autoScalingName returns the name of the Auto Scaling resource of the given
+// ID, e.g. 0123:autoScalingGroupName/test-masters.
+func autoScalingName(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+// awsDeletions are the resource types gc deletes, by ARN prefix, in the order
+// they are deleted, dependents first. Each deletes the resource of the given
+// ARN and ID.
+var awsDeletions = []struct {
+	prefix string
+	delete func(region, arn, id string) error
+}{
+	// force deleting the groups terminates their instances rather than
+	// having them replaced as they are terminated
+	{"autoscaling:autoScalingGroup:", awsCommand(func(arn, id string) []string {
+		return []string{"autoscaling", "delete-auto-scaling-group", "--auto-scaling-group-name", autoScalingName(id), "--force-delete"}
+	})},
+	{"ec2:instance/", awsCommand(func(arn, id string) []string { return []string{"ec2", "terminate-instances", "--instance-ids", id} })},
+	{"elasticloadbalancing:loadbalancer/net/", awsCommand(func(arn, id string) []string {
+		return []string{"elbv2", "delete-load-balancer", "--load-balancer-arn", arn}
+	})},
+	{"elasticloadbalancing:loadbalancer/app/", awsCommand(func(arn, id string) []string {
+		return []string{"elbv2", "delete-load-balancer", "--load-balancer-arn", arn}
+	})},
+	{"elasticloadbalancing:loadbalancer/", awsCommand(func(arn, id string) []string {
+		return []string{"elb", "delete-load-balancer", "--load-balancer-name", id}
+	})},
+	{"elasticloadbalancing:targetgroup/", awsCommand(func(arn, id string) []string {
+		return []string{"elbv2", "delete-target-group", "--target-group-arn", arn}
+	})},
+	{"autoscaling:launchConfiguration:", awsCommand(func(arn, id string) []string {
+		return []string{"autoscaling", "delete-launch-configuration", "--launch-configuration-name", autoScalingName(id)}
+	})},
+	{"iam:instance-profile/", deleteInstanceProfile},
+	{"iam:role/", deleteIAMRole},
+	{"ec2:natgateway/", awsCommand(func(arn, id string) []string { return []string{"ec2", "delete-nat-gateway", "--nat-gateway-id", id} })},
+	{"s3:", awsCommand(func(arn, id string) []string { return []string{"s3", "rb", "s3://" + id, "--force"} })},
+	{"ec2:volume/", awsCommand(func(arn, id string) []string { return []string{"ec2", "delete-volume", "--volume-id", id} })},
+	{"ec2:elastic-ip/", awsCommand(func(arn, id string) []string { return []string{"ec2", "release-address", "--allocation-id", id} })},
+	{"route53:hostedzone/", deleteHostedZone},
+	{"ec2:security-group/", awsCommand(func(arn, id string) []string { return []string{"ec2", "delete-security-group", "--group-id", id} })},
+	{"ec2:subnet/", awsCommand(func(arn, id string) []string { return []string{"ec2", "delete-subnet", "--subnet-id", id} })},
+	{"ec2:route-table/", awsCommand(func(arn, id string) []string { return []string{"ec2", "delete-route-table", "--route-table-id", id} })},
+	{"ec2:vpc/", awsCommand(func(arn, id string) []string { return []string{"ec2", "delete-vpc", "--vpc-id", id} })},
+}
+
+// deletion returns the rank, in awsDeletions, of the resource of the given
+// ARN, e.g. arn:aws:ec2:us-east-1:123456789012:instance/i-0123, and the
+// function deleting it in a region, or -1 if gc cannot delete such resources.
+func deletion(arn string) (int, func(region string) error) {
+	// arn:partition:service:region:account:resource
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 {
+		return -1, nil
+	}
+	resource := parts[2] + ":" + parts[5]
+	for i, d := range awsDeletions {
+		if strings.HasPrefix(resource, d.prefix) {
+			id := strings.TrimPrefix(resource, d.prefix)
+			del := d.delete
+			return i, func(region string) error { return del(region, arn, id) }
+		}
+	}
+	return -1, nil
+}
+
+// deleteAWSResources deletes the resources of the given ARNs, dependents
+// first, and returns the number of those which could not be deleted.
+func deleteAWSResources(region string, arns []string) int {
+	type pending struct {
+		arn    string
+		rank   int
+		delete func(region string) error
+	}
+	var deletions []pending
+	var failed int
+	for _, arn := range arns {
+		rank, del := deletion(arn)
+		if rank < 0 {
+			log.Warnf("Cannot delete %s, delete it manually", arn)
+			failed++
+			continue
+		}
+		deletions = append(deletions, pending{arn: arn, rank: rank, delete: del})
+	}
+	sort.SliceStable(deletions, func(i, j int) bool { return deletions[i].rank < deletions[j].rank })
+
+	for _, d := range deletions {
+		log.Infof("Deleting %s", d.arn)
+		if err := d.delete(region); err != nil {
+			log.Errorf("Failed to delete %s: %v", d.arn, err)
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,261 @@
+package workflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+func TestFindOrphanedClusters(t *testing.T) {
+	defer func(f func(string, ...string) ([]byte, error)) { awsCLI = f }(awsCLI)
+	awsCLI = func(region string, args ...string) ([]byte, error) {
+		switch strings.Join(args[:2], " ") {
+		case "resourcegroupstaggingapi get-resources":
+			return []byte(`{"ResourceTagMappingList": [
+				{"ResourceARN": "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1", "Tags": [{"Key": "tectonicClusterID", "Value": "id-1"}, {"Key": "kubernetes.io/cluster/orphan", "Value": "owned"}]},
+				{"ResourceARN": "arn:aws:ec2:us-east-1:123456789012:instance/i-1", "Tags": [{"Key": "tectonicClusterID", "Value": "id-1"}]},
+				{"ResourceARN": "arn:aws:route53:::hostedzone/Z1", "Tags": [{"Key": "tectonicClusterID", "Value": "id-1"}]},
+				{"ResourceARN": "arn:aws:s3:::live-tnc.example.com", "Tags": [{"Key": "tectonicClusterID", "Value": "id-2"}, {"Key": "kubernetes.io/cluster/live", "Value": "owned"}]}
+			]}`), nil
+		case "autoscaling describe-auto-scaling-groups":
+			return []byte(`{"AutoScalingGroups": [
+				{"AutoScalingGroupARN": "arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:0123:autoScalingGroupName/orphan-masters", "LaunchConfigurationName": "orphan-master-1", "Tags": [{"Key": "tectonicClusterID", "Value": "id-1"}]},
+				{"AutoScalingGroupARN": "arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:4567:autoScalingGroupName/untagged", "LaunchConfigurationName": "untagged-1", "Tags": []}
+			]}`), nil
+		case "autoscaling describe-launch-configurations":
+			return []byte(`{"LaunchConfigurations": [
+				{"LaunchConfigurationName": "orphan-master-1", "LaunchConfigurationARN": "arn:aws:autoscaling:us-east-1:123456789012:launchConfiguration:0123:launchConfigurationName/orphan-master-1"},
+				{"LaunchConfigurationName": "orphan-worker-2", "LaunchConfigurationARN": "arn:aws:autoscaling:us-east-1:123456789012:launchConfiguration:4567:launchConfigurationName/orphan-worker-2"},
+				{"LaunchConfigurationName": "live-worker-3", "LaunchConfigurationARN": "arn:aws:autoscaling:us-east-1:123456789012:launchConfiguration:8901:launchConfigurationName/live-worker-3"}
+			]}`), nil
+		case "iam list-roles":
+			return []byte(`{"Roles": [
+				{"RoleName": "orphan-master-role", "Arn": "arn:aws:iam::123456789012:role/orphan-master-role"},
+				{"RoleName": "live-master-role", "Arn": "arn:aws:iam::123456789012:role/live-master-role"},
+				{"RoleName": "ci-runner", "Arn": "arn:aws:iam::123456789012:role/ci-runner"}
+			]}`), nil
+		case "iam list-instance-profiles":
+			return []byte(`{"InstanceProfiles": [
+				{"InstanceProfileName": "orphan-worker-profile", "Arn": "arn:aws:iam::123456789012:instance-profile/orphan-worker-profile"}
+			]}`), nil
+		case "route53 list-hosted-zones":
+			return []byte(`{"HostedZones": [{"Id": "/hostedzone/Z1"}, {"Id": "/hostedzone/Z2"}]}`), nil
+		case "route53 list-resource-record-sets":
+			if args[3] != "Z2" {
+				t.Errorf("Test case TestFindOrphanedClusters: expected the records of the owned zone not to be listed, got: %v", args)
+			}
+			return []byte(`{"ResourceRecordSets": [
+				{"Name": "example.com.", "Type": "SOA"},
+				{"Name": "orphan-api.example.com.", "Type": "A"},
+				{"Name": "\\052.orphan.example.com.", "Type": "A"},
+				{"Name": "live-api.example.com.", "Type": "A"},
+				{"Name": "orphanage.example.com.", "Type": "A"}
+			]}`), nil
+		}
+		t.Fatalf("Test case TestFindOrphanedClusters: unexpected command: %v", args)
+		return nil, nil
+	}
+
+	got, err := findOrphanedClusters("us-east-1", map[string]string{"id-2": "/clusters/live"})
+	if err != nil {
+		t.Fatalf("failed to find orphaned clusters: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Test case TestFindOrphanedClusters: expected 1 orphaned cluster, got: %+v", got)
+	}
+	if got[0].id != "id-1" || got[0].name != "orphan" {
+		t.Errorf("Test case TestFindOrphanedClusters: expected cluster orphan (id-1), got: %s (%s)", got[0].name, got[0].id)
+	}
+	expectedARNs := []string{
+		"arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:0123:autoScalingGroupName/orphan-masters",
+		"arn:aws:autoscaling:us-east-1:123456789012:launchConfiguration:0123:launchConfigurationName/orphan-master-1",
+		"arn:aws:autoscaling:us-east-1:123456789012:launchConfiguration:4567:launchConfigurationName/orphan-worker-2",
+		"arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+		"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1",
+		"arn:aws:iam::123456789012:instance-profile/orphan-worker-profile",
+		"arn:aws:iam::123456789012:role/orphan-master-role",
+		"arn:aws:route53:::hostedzone/Z1",
+	}
+	if !reflect.DeepEqual(got[0].arns, expectedARNs) {
+		t.Errorf("Test case TestFindOrphanedClusters: expected: %v, got: %v", expectedARNs, got[0].arns)
+	}
+	var records []string
+	for _, r := range got[0].records {
+		records = append(records, r.zoneID+" "+r.name)
+	}
+	if expected := []string{"Z2 orphan-api.example.com.", `Z2 \052.orphan.example.com.`}; !reflect.DeepEqual(records, expected) {
+		t.Errorf("Test case TestFindOrphanedClusters: expected records: %v, got: %v", expected, records)
+	}
+}
+
+func TestDeletion(t *testing.T) {
+	defer func(f func(string, ...string) ([]byte, error)) { awsCLI = f }(awsCLI)
+	var commands []string
+	awsCLI = func(region string, args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return []byte(`{}`), nil
+	}
+
+	testCases := []struct {
+		arn      string
+		expected string
+	}{
+		{
+			arn:      "arn:aws:ec2:us-east-1:123456789012:instance/i-0123",
+			expected: "ec2 terminate-instances --instance-ids i-0123",
+		},
+		{
+			arn:      "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/net/test-ext/0123",
+			expected: "elbv2 delete-load-balancer --load-balancer-arn arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/net/test-ext/0123",
+		},
+		{
+			arn:      "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/test-con",
+			expected: "elb delete-load-balancer --load-balancer-name test-con",
+		},
+		{
+			arn:      "arn:aws:s3:::test-tnc.example.com",
+			expected: "s3 rb s3://test-tnc.example.com --force",
+		},
+		{
+			arn:      "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123",
+			expected: "ec2 delete-vpc --vpc-id vpc-0123",
+		},
+		{
+			arn:      "arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:0123:autoScalingGroupName/test-masters",
+			expected: "autoscaling delete-auto-scaling-group --auto-scaling-group-name test-masters --force-delete",
+		},
+		{
+			arn:      "arn:aws:autoscaling:us-east-1:123456789012:launchConfiguration:0123:launchConfigurationName/test-master-1",
+			expected: "autoscaling delete-launch-configuration --launch-configuration-name test-master-1",
+		},
+		{
+			arn:      "arn:aws:iam::123456789012:role/test-master-role",
+			expected: "iam list-role-policies --role-name test-master-role; iam list-attached-role-policies --role-name test-master-role; iam delete-role --role-name test-master-role",
+		},
+		{
+			arn:      "arn:aws:iam::123456789012:instance-profile/test-master-profile",
+			expected: "iam get-instance-profile --instance-profile-name test-master-profile; iam delete-instance-profile --instance-profile-name test-master-profile",
+		},
+		{
+			arn:      "arn:aws:route53:::hostedzone/Z0123",
+			expected: "route53 list-resource-record-sets --hosted-zone-id Z0123; route53 delete-hosted-zone --id Z0123",
+		},
+		{
+			arn:      "arn:aws:sns:us-east-1:123456789012:test-events",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		commands = nil
+		if _, del := deletion(tc.arn); del != nil {
+			if err := del("us-east-1"); err != nil {
+				t.Errorf("Test case %s: expected no error, got: %v", tc.arn, err)
+			}
+		}
+		if got := strings.Join(commands, "; "); got != tc.expected {
+			t.Errorf("Test case %s: expected: %q, got: %q", tc.arn, tc.expected, got)
+		}
+	}
+}
+
+func TestDeleteHostedZone(t *testing.T) {
+	defer func(f func(string, ...string) ([]byte, error)) { awsCLI = f }(awsCLI)
+	var commands []string
+	awsCLI = func(region string, args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		if args[1] == "list-resource-record-sets" {
+			return []byte(`{"ResourceRecordSets": [
+				{"Name": "example.com.", "Type": "SOA", "TTL": 900},
+				{"Name": "example.com.", "Type": "NS", "TTL": 172800},
+				{"Name": "test-api.example.com.", "Type": "A", "TTL": 60}
+			]}`), nil
+		}
+		return nil, nil
+	}
+
+	if err := deleteHostedZone("us-east-1", "arn:aws:route53:::hostedzone/Z0123", "Z0123"); err != nil {
+		t.Fatalf("Test case TestDeleteHostedZone: expected no error, got: %v", err)
+	}
+	expected := []string{
+		"route53 list-resource-record-sets --hosted-zone-id Z0123",
+		`route53 change-resource-record-sets --hosted-zone-id Z0123 --change-batch {"Changes":[{"Action":"DELETE","ResourceRecordSet":{"Name":"test-api.example.com.","Type":"A","TTL":60}}]}`,
+		"route53 delete-hosted-zone --id Z0123",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Test case TestDeleteHostedZone: expected: %v, got: %v", expected, commands)
+	}
+}
+
+func TestDeleteAWSResources(t *testing.T) {
+	defer func(f func(string, ...string) ([]byte, error)) { awsCLI = f }(awsCLI)
+	var commands []string
+	awsCLI = func(region string, args ...string) ([]byte, error) {
+		commands = append(commands, args[1])
+		return nil, nil
+	}
+
+	failed := deleteAWSResources("us-east-1", []string{
+		"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1",
+		"arn:aws:sns:us-east-1:123456789012:test-events",
+		"arn:aws:ec2:us-east-1:123456789012:subnet/subnet-1",
+		"arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+		"arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:0123:autoScalingGroupName/test-workers",
+	})
+	if failed != 1 {
+		t.Errorf("Test case TestDeleteAWSResources: expected: 1 undeletable resource, got: %d", failed)
+	}
+	if expected := []string{"delete-auto-scaling-group", "terminate-instances", "delete-subnet", "delete-vpc"}; !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Test case TestDeleteAWSResources: expected: %v, got: %v", expected, commands)
+	}
+}
+
+func TestDestroyAWSLeftovers(t *testing.T) {
+	defer func(f func(string, ...string) ([]byte, error)) { awsCLI = f }(awsCLI)
+	var deleted []string
+	awsCLI = func(region string, args ...string) ([]byte, error) {
+		switch strings.Join(args[:2], " ") {
+		case "resourcegroupstaggingapi get-resources":
+			return []byte(`{"ResourceTagMappingList": [
+				{"ResourceARN": "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1", "Tags": [{"Key": "tectonicClusterID", "Value": "id-1"}, {"Key": "kubernetes.io/cluster/test", "Value": "owned"}]},
+				{"ResourceARN": "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-2", "Tags": [{"Key": "tectonicClusterID", "Value": "id-2"}, {"Key": "kubernetes.io/cluster/other", "Value": "owned"}]}
+			]}`), nil
+		case "autoscaling describe-auto-scaling-groups":
+			return []byte(`{"AutoScalingGroups": []}`), nil
+		case "autoscaling describe-launch-configurations":
+			return []byte(`{"LaunchConfigurations": []}`), nil
+		case "iam list-roles":
+			return []byte(`{"Roles": [{"RoleName": "test-master-role", "Arn": "arn:aws:iam::123456789012:role/test-master-role"}]}`), nil
+		case "iam list-instance-profiles":
+			return []byte(`{"InstanceProfiles": []}`), nil
+		case "route53 list-hosted-zones":
+			return []byte(`{"HostedZones": []}`), nil
+		case "iam list-role-policies", "iam list-attached-role-policies":
+			return []byte(`{}`), nil
+		}
+		deleted = append(deleted, strings.Join(args, " "))
+		return []byte(`{}`), nil
+	}
+
+	m := &metadata{fromMetadata: true}
+	m.cluster.Name = "test"
+	m.cluster.ClusterID = "id-1"
+	m.cluster.Platform = config.PlatformAWS
+	m.cluster.AWS.Region = "us-east-1"
+	if err := destroyAWSLeftoversStep(m); err != nil {
+		t.Fatalf("Test case TestDestroyAWSLeftovers: expected no error, got: %v", err)
+	}
+	expected := []string{"iam delete-role --role-name test-master-role", "ec2 delete-vpc --vpc-id vpc-1"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("Test case TestDestroyAWSLeftovers: expected: %v, got: %v", expected, deleted)
+	}
+
+	// with the terraform states, terraform destroys the resources
+	deleted = nil
+	m.fromMetadata = false
+	if err := destroyAWSLeftoversStep(m); err != nil || len(deleted) != 0 {
+		t.Errorf("Test case with states: expected nothing deleted, got: %v, %v", deleted, err)
+	}
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Hooks run by the install workflows, named like in the cluster config.
+const (
+	hookPreManifests  = "preManifests"
+	hookPostManifests = "postManifests"
+	hookPreProvision  = "preProvision"
+	hookPostInstall   = "postInstall"
+)
+
+// hookStep returns a step running the executable configured for the given hook, if any.
+func hookStep(hook string) Step {
+	return func(m *metadata) error {
+		path := hookPath(m, hook)
+		if path == "" {
+			return nil
+		}
+		clusterDir, err := filepath.Abs(m.clusterDir)
+		if err != nil {
+			return err
+		}
+		log.Infof("Running the %s hook %s...", hook, path)
+		cmd := exec.CommandContext(m.context(), path)
+		cmd.Dir = clusterDir
+		cmd.Env = append(os.Environ(), hookEnv(m, hook, clusterDir)...)
+		cmd.Stdout, cmd.Stderr = m.stdout, m.stderr
+		if cmd.Stdout == nil {
+			cmd.Stdout = os.Stdout
+		}
+		if cmd.Stderr == nil {
+			cmd.Stderr = os.Stderr
+		}
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %s failed: %v", hook, path, err)
+		}
+		return nil
+	}
+}
+
+func hookPath(m *metadata, hook string) string {
+	switch hook {
+	case hookPreManifests:
+		return m.cluster.Hooks.PreManifests
+	case hookPostManifests:
+		return m.cluster.Hooks.PostManifests
+	case hookPreProvision:
+		return m.cluster.Hooks.PreProvision
+	case hookPostInstall:
+		return m.cluster.Hooks.PostInstall
+	}
+	return ""
+}
+
+// hookEnv returns the variables exposing the cluster to the hooks.
+func hookEnv(m *metadata, hook, clusterDir string) []string {
+	return []string{
+		"TECTONIC_HOOK=" + hook,
+		"TECTONIC_CLUSTER_DIR=" + clusterDir,
+		"TECTONIC_METADATA_FILE=" + filepath.Join(clusterDir, metadataFileName),
+		"TECTONIC_CLUSTER_NAME=" + m.cluster.Name,
+		"TECTONIC_CLUSTER_ID=" + m.cluster.ClusterID,
+		"TECTONIC_PLATFORM=" + string(m.cluster.Platform),
+	}
+}
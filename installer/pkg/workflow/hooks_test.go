@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+func TestHookStep(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	hook := filepath.Join(dir, "hook.sh")
+	script := "#!/bin/sh\necho \"$TECTONIC_HOOK $TECTONIC_CLUSTER_NAME $TECTONIC_PLATFORM\" > hook.out\n"
+	if err := ioutil.WriteFile(hook, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	m := &metadata{
+		clusterDir: dir,
+		cluster: config.Cluster{
+			Name:     "test",
+			Platform: config.PlatformLibvirt,
+			Hooks:    config.Hooks{PreProvision: hook},
+		},
+	}
+	if err := hookStep(hookPostInstall)(m); err != nil {
+		t.Errorf("Test case unset hook: expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hook.out")); !os.IsNotExist(err) {
+		t.Errorf("Test case unset hook: expected the hook not to run, got: %v", err)
+	}
+
+	if err := hookStep(hookPreProvision)(m); err != nil {
+		t.Fatalf("Test case set hook: expected no error, got: %v", err)
+	}
+	out, err := ioutil.ReadFile(filepath.Join(dir, "hook.out"))
+	if err != nil {
+		t.Fatalf("Test case set hook: expected the hook to run in the cluster directory, got: %v", err)
+	}
+	if expected := "preProvision test libvirt\n"; string(out) != expected {
+		t.Errorf("Test case set hook: expected: %q, got: %q", expected, out)
+	}
+
+	if err := ioutil.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+	if err := hookStep(hookPreProvision)(m); err == nil {
+		t.Error("Test case failing hook: expected an error, got: <nil>")
+	}
+}
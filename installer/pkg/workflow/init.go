@@ -3,9 +3,13 @@ package workflow
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
+	log "github.com/Sirupsen/logrus"
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/openshift/installer/installer/pkg/config"
@@ -13,41 +17,93 @@ import (
 )
 
 const (
-	generatedPath              = "generated"
-	kcoConfigFileName          = "kco-config.yaml"
-	tncoConfigFileName         = "tnco-config.yaml"
-	kubeSystemPath             = "generated/manifests"
-	kubeSystemFileName         = "cluster-config.yaml"
-	tectonicSystemPath         = "generated/tectonic"
-	newTLSPath                 = "generated/newTLS"
-	tectonicSystemFileName     = "cluster-config.yaml"
-	terraformVariablesFileName = "terraform.tfvars"
+	adminPasswordFileName       = "admin-password"
+	cloudProviderConfigFileName = "cloud-provider-config"
+	authPath                    = "auth"
+	extraManifestsPath          = "manifests-extra"
+	generatedPath               = "generated"
+	kcoConfigFileName           = "kco-config.yaml"
+	tncoConfigFileName          = "tnco-config.yaml"
+	kubeSystemPath              = "generated/manifests"
+	kubeSystemFileName          = "cluster-config.yaml"
+	tectonicSystemPath          = "generated/tectonic"
+	newTLSPath                  = "generated/newTLS"
+	pullSecretFileName          = "pull-secret.json"
+	tectonicSystemFileName      = "cluster-config.yaml"
+	terraformVariablesFileName  = "terraform.tfvars"
+	userCABundleFileName        = "user-ca-bundle.yaml"
 )
 
 // InitWorkflow creates new instances of the 'init' workflow,
 // responsible for initializing a new cluster.
-func InitWorkflow(configFilePath string) Workflow {
+// With edit set, the config copied into the cluster directory is opened in
+// the user's editor until it is saved valid.
+func InitWorkflow(configFilePath string, edit bool) Workflow {
 	return Workflow{
-		metadata: metadata{configFilePath: configFilePath},
+		metadata: metadata{configFilePath: configFilePath, editConfig: edit},
 		steps: []Step{
 			prepareWorspaceStep,
+			editConfigStep,
 			refreshConfigStep,
 		},
 	}
 }
 
-func buildInternalConfig(clusterDir string) error {
+// editConfigStep opens the config of the cluster in the user's editor and
+// validates it once saved, re-opening it as long as the user wants to fix it.
+func editConfigStep(m *metadata) error {
+	if !m.editConfig {
+		return nil
+	}
+	configFilePath := filepath.Join(m.clusterDir, configFileName)
+	for {
+		args := editorCommand()
+		cmd := exec.Command(args[0], append(args[1:], configFilePath)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run editor %s: %v", strings.Join(args, " "), err)
+		}
+
+		cluster, err := readClusterConfig(configFilePath, "")
+		if err == nil {
+			err = cluster.ValidateAndLog()
+		}
+		// the cluster directory is named after the cluster
+		if err == nil && cluster.Name != filepath.Base(m.clusterDir) {
+			err = fmt.Errorf("the cluster name cannot be changed from %q once initialized", filepath.Base(m.clusterDir))
+		}
+		if err == nil {
+			return nil
+		}
+		log.Error(err)
+		if confirm(os.Stdin, "Edit the config again?") != nil {
+			return withExitCode(err, ExitCodeValidation)
+		}
+	}
+}
+
+// buildInternalConfig writes the internal config of the cluster, with the
+// given cluster ID or, if empty, a random one.
+func buildInternalConfig(clusterDir, clusterID string) error {
 	if clusterDir == "" {
 		return errors.New("no cluster dir given for building internal config")
 	}
 
 	// fill the internal struct
-	clusterID, err := configgenerator.GenerateClusterID(16)
+	if clusterID == "" {
+		var err error
+		clusterID, err = configgenerator.GenerateClusterID(16)
+		if err != nil {
+			return err
+		}
+	}
+	adminPassword, err := configgenerator.GeneratePassword(18)
 	if err != nil {
 		return err
 	}
 	internalCfg := config.Internal{
-		ClusterID: clusterID,
+		AdminPassword: adminPassword,
+		ClusterID:     clusterID,
 	}
 
 	// store the content
@@ -61,6 +117,30 @@ func buildInternalConfig(clusterDir string) error {
 }
 
 func generateTerraformVariablesStep(m *metadata) error {
+	extraManifests, err := listExtraManifests(m.clusterDir)
+	if err != nil {
+		return err
+	}
+	if m.cluster.TLS.AdditionalTrustBundlePath != "" {
+		extraManifests = append(extraManifests, userCABundleFileName)
+	}
+	m.cluster.ExtraManifests = extraManifests
+	// the manifests are rendered with the pull secrets merged into one
+	if len(m.cluster.AdditionalPullSecretPaths) > 0 {
+		pullSecret, err := config.MergePullSecrets(m.cluster.PullSecretPaths())
+		if err != nil {
+			return withExitCode(err, ExitCodeValidation)
+		}
+		pullSecretPath := filepath.Join(m.clusterDir, pullSecretFileName)
+		if err := ioutil.WriteFile(pullSecretPath, pullSecret, 0600); err != nil {
+			return fmt.Errorf("failed to write merged pull secret: %v", err)
+		}
+		m.cluster.PullSecretPath = pullSecretPath
+	}
+	if policy := retryPolicyFromEnv(); m.cluster.Platform == config.PlatformAWS && policy.awsMaxRetries > 0 {
+		m.cluster.AWS.MaxRetries = policy.awsMaxRetries
+	}
+
 	vars, err := m.cluster.TFVars()
 	if err != nil {
 		return err
@@ -87,7 +167,7 @@ func prepareWorspaceStep(m *metadata) error {
 	}
 
 	if err := cluster.ValidateAndLog(); err != nil {
-		return err
+		return withExitCode(err, ExitCodeValidation)
 	}
 
 	// generate clusterDir folder
@@ -97,6 +177,15 @@ func prepareWorspaceStep(m *metadata) error {
 		return fmt.Errorf("cluster directory already exists at %q", clusterDir)
 	}
 
+	clusterID := requestedClusterID(*cluster)
+	if clusterID != "" {
+		if other, err := clusterIDUser(dir, clusterID); err != nil {
+			return err
+		} else if other != "" {
+			return withExitCode(fmt.Errorf("cluster ID %s is already used by the cluster in %q", clusterID, other), ExitCodeValidation)
+		}
+	}
+
 	if err := os.MkdirAll(clusterDir, os.ModeDir|0755); err != nil {
 		return fmt.Errorf("failed to create cluster directory at %q", clusterDir)
 	}
@@ -108,5 +197,68 @@ func prepareWorspaceStep(m *metadata) error {
 	}
 
 	// generate the internal config file under the clusterDir folder
-	return buildInternalConfig(clusterDir)
+	if err := buildInternalConfig(clusterDir, clusterID); err != nil {
+		return err
+	}
+
+	if cluster.Admin.Password == "" {
+		return writeAdminPassword(clusterDir)
+	}
+	return nil
+}
+
+// requestedClusterID returns the cluster ID set in, or derived from the seed
+// set in, the cluster config; it is empty when a random one should be generated.
+func requestedClusterID(cluster config.Cluster) string {
+	if cluster.ClusterIDSeed != "" {
+		return configgenerator.ClusterIDFromSeed(cluster.ClusterIDSeed)
+	}
+	return cluster.RequestedClusterID
+}
+
+// clusterIDUser returns the directory of the cluster, among those initialized
+// in dir, which already has the given cluster ID, if any.
+func clusterIDUser(dir, clusterID string) (string, error) {
+	ids, err := clusterIDs(dir)
+	if err != nil {
+		return "", err
+	}
+	return ids[clusterID], nil
+}
+
+// clusterIDs returns the directories of the clusters initialized in dir, by cluster ID.
+func clusterIDs(dir string) (map[string]string, error) {
+	internalFiles, err := filepath.Glob(filepath.Join(dir, "*", internalFileName))
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for _, f := range internalFiles {
+		internal, err := config.ParseInternalFile(f)
+		if err != nil {
+			// not every directory is a cluster
+			continue
+		}
+		ids[internal.ClusterID] = filepath.Dir(f)
+	}
+	return ids, nil
+}
+
+// writeAdminPassword writes the generated admin password to auth/admin-password
+// under the clusterDir folder, readable by the owner only.
+func writeAdminPassword(clusterDir string) error {
+	internal, err := config.ParseInternalFile(filepath.Join(clusterDir, internalFileName))
+	if err != nil {
+		return err
+	}
+	authDir := filepath.Join(clusterDir, authPath)
+	if err := os.MkdirAll(authDir, 0700); err != nil {
+		return fmt.Errorf("failed to create auth directory at %q: %v", authDir, err)
+	}
+	path := filepath.Join(authDir, adminPasswordFileName)
+	if err := ioutil.WriteFile(path, []byte(internal.AdminPassword+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write admin password to %q: %v", path, err)
+	}
+	log.Infof("Generated the admin password, written to %s", path)
+	return nil
 }
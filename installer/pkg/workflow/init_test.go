@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -130,6 +131,64 @@ func TestGenerateTerraformVariablesStep(t *testing.T) {
 	}
 }
 
+func TestListExtraManifests(t *testing.T) {
+	clusterDir, err := ioutil.TempDir("", "extra_manifests")
+	if err != nil {
+		t.Fatalf("failed to create cluster dir: %v", err)
+	}
+	defer os.RemoveAll(clusterDir)
+
+	if got, err := listExtraManifests(clusterDir); err != nil || len(got) != 0 {
+		t.Errorf("test case no extra manifests directory: expected no manifests, got: %v, %v", got, err)
+	}
+
+	dir := filepath.Join(clusterDir, extraManifestsPath)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("failed to create extra manifests dir: %v", err)
+	}
+	files := map[string]string{
+		"ldap.yaml":  "apiVersion: v1\nkind: Secret\nmetadata:\n  name: ldap\n",
+		"policy.yml": "apiVersion: networking.k8s.io/v1\nkind: NetworkPolicy\nmetadata:\n  name: deny\n",
+		"README.md":  "not a manifest",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	got, err := listExtraManifests(clusterDir)
+	if err != nil {
+		t.Errorf("test case valid extra manifests: expected no error, got: %v", err)
+	}
+	if expected := []string{"ldap.yaml", "policy.yml"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("test case valid extra manifests: expected: %v, got: %v", expected, got)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("kind: Secret\n"), 0644); err != nil {
+		t.Fatalf("failed to write broken.yaml: %v", err)
+	}
+	if _, err := listExtraManifests(clusterDir); err == nil {
+		t.Errorf("test case invalid extra manifest: expected an error, got none")
+	}
+	if err := os.Remove(filepath.Join(dir, "broken.yaml")); err != nil {
+		t.Fatalf("failed to remove broken.yaml: %v", err)
+	}
+
+	for _, name := range []string{kubeSystemFileName, "kube-apiserver-secret.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(files["ldap.yaml"]), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := listExtraManifests(clusterDir); err == nil {
+			t.Errorf("test case extra manifest %s: expected a conflict error, got none", name)
+		}
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("failed to remove %s: %v", name, err)
+		}
+	}
+}
+
 func TestBuildInternalConfig(t *testing.T) {
 	testClusterDir := "."
 	internalFilePath := filepath.Join(testClusterDir, internalFileName)
@@ -148,7 +207,7 @@ func TestBuildInternalConfig(t *testing.T) {
 	}{
 		{
 			test:     "no clusterDir exists",
-			got:      buildInternalConfig("").Error(),
+			got:      buildInternalConfig("", "").Error(),
 			expected: "no cluster dir given for building internal config",
 		},
 	}
@@ -159,7 +218,7 @@ func TestBuildInternalConfig(t *testing.T) {
 		}
 	}
 
-	if err := buildInternalConfig(testClusterDir); err != nil {
+	if err := buildInternalConfig(testClusterDir, ""); err != nil {
 		t.Errorf("failed to run buildInternalStep, %v", err)
 	}
 
@@ -190,3 +249,47 @@ func TestBuildInternalConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestClusterIDUser(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cluster_id")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	clusterDir := filepath.Join(dir, "test")
+	if err := os.Mkdir(clusterDir, 0755); err != nil {
+		t.Fatalf("failed to create cluster directory: %v", err)
+	}
+	const clusterID = "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"
+	if err := buildInternalConfig(clusterDir, clusterID); err != nil {
+		t.Fatalf("failed to build internal config: %v", err)
+	}
+
+	testCases := []struct {
+		test      string
+		clusterID string
+		expected  string
+	}{
+		{
+			test:      "Used",
+			clusterID: clusterID,
+			expected:  clusterDir,
+		},
+		{
+			test:      "Unused",
+			clusterID: "ffffffff-4e5f-6a7b-8c9d-0e1f2a3b4c5d",
+			expected:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := clusterIDUser(dir, tc.clusterID)
+		if err != nil {
+			t.Errorf("Test case %s: expected no error, got: %v", tc.test, err)
+		}
+		if got != tc.expected {
+			t.Errorf("Test case %s: expected: %q, got: %q", tc.test, tc.expected, got)
+		}
+	}
+}
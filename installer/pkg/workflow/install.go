@@ -4,30 +4,47 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
 	"github.com/openshift/installer/installer/pkg/config-generator"
 )
 
 // InstallFullWorkflow creates new instances of the 'install' workflow,
 // responsible for running the actions necessary to install a new cluster.
-func InstallFullWorkflow(clusterDir string) Workflow {
+// With plan set, the infrastructure plan is shown and confirmation is
+// asked for once the assets are generated, before creating any resource.
+func InstallFullWorkflow(clusterDir string, plan bool) Workflow {
 	return Workflow{
-		metadata: metadata{clusterDir: clusterDir},
+		metadata: metadata{clusterDir: clusterDir, plan: plan},
 		steps: []Step{
+			phaseStep(phaseAssets),
 			refreshConfigStep,
 			generateClusterConfigMaps,
 			readClusterConfigStep,
+			checkVPCDNSServersStep,
 			installTLSAssetsStep,
 			generateClusterConfigMaps,
+			hookStep(hookPreManifests),
 			installAssetsStep,
+			hookStep(hookPostManifests),
+			lintManifestsStep,
 			generateIgnConfigStep,
+			planTopologyStep,
+			hookStep(hookPreProvision),
+			phaseStep(phaseInfrastructure),
 			installTopologyStep,
 			installTNCCNAMEStep,
+			phaseStep(phaseBootstrap),
 			installBootstrapStep,
 			installTNCARecordStep,
 			installEtcdStep,
+			phaseStep(phaseRollout),
 			installJoinMastersStep,
 			installJoinWorkersStep,
+			hookStep(hookPostInstall),
 		},
 	}
 }
@@ -58,14 +75,19 @@ func InstallTLSWorkflow(clusterDir string) Workflow {
 
 // InstallAssetsWorkflow creates new instances of the 'assets' workflow,
 // responsible for running the actions necessary to generate cluster assets.
-func InstallAssetsWorkflow(clusterDir string) Workflow {
+// Unless bundle is empty, the manifests are also bundled in that format.
+func InstallAssetsWorkflow(clusterDir, bundle string) Workflow {
 	return Workflow{
-		metadata: metadata{clusterDir: clusterDir},
+		metadata: metadata{clusterDir: clusterDir, manifestBundle: bundle},
 		steps: []Step{
 			refreshConfigStep,
 			generateClusterConfigMaps,
+			hookStep(hookPreManifests),
 			installAssetsStep,
+			hookStep(hookPostManifests),
+			lintManifestsStep,
 			generateIgnConfigStep,
+			bundleManifestsStep,
 		},
 	}
 }
@@ -77,8 +99,11 @@ func InstallBootstrapWorkflow(clusterDir string) Workflow {
 		metadata: metadata{clusterDir: clusterDir},
 		steps: []Step{
 			refreshConfigStep,
+			hookStep(hookPreProvision),
+			phaseStep(phaseInfrastructure),
 			installTopologyStep,
 			installTNCCNAMEStep,
+			phaseStep(phaseBootstrap),
 			installBootstrapStep,
 			installTNCARecordStep,
 			installEtcdStep,
@@ -93,8 +118,10 @@ func InstallJoinWorkflow(clusterDir string) Workflow {
 		metadata: metadata{clusterDir: clusterDir},
 		steps: []Step{
 			refreshConfigStep,
+			phaseStep(phaseRollout),
 			installJoinMastersStep,
 			installJoinWorkersStep,
+			hookStep(hookPostInstall),
 		},
 	}
 }
@@ -103,27 +130,73 @@ func refreshConfigStep(m *metadata) error {
 	if err := readClusterConfigStep(m); err != nil {
 		return err
 	}
-	return generateTerraformVariablesStep(m)
+	if err := generateTerraformVariablesStep(m); err != nil {
+		return err
+	}
+	// the assets step reads it whenever it is applied or destroyed
+	if err := generateBootstrapOverridesStep(m); err != nil {
+		return err
+	}
+	return generateMetadataStep(m)
 }
 
 func installTLSAssetsStep(m *metadata) error {
+	if err := signCAsStep(m); err != nil {
+		return err
+	}
 	return runInstallStep(m, tlsStep)
+}
 
+// signCAsStep signs the CAs of the tls step with the root CA signer command,
+// if any. They are signed once, like the tls step creates its certificates
+// once, so that resuming an install does not re-issue them: the signed CAs are
+// moved into place together, so their directory exists once all are signed.
+func signCAsStep(m *metadata) error {
+	if m.cluster.CA.RootCASignerCommand == "" {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(m.clusterDir, config.SignedCAsPath)); err == nil {
+		return nil
+	}
+	c := configgenerator.New(m.cluster)
+	return c.GenerateSignedCAs(m.clusterDir)
 }
 
 func installAssetsStep(m *metadata) error {
 	return runInstallStep(m, assetsStep)
 }
 
+func planTopologyStep(m *metadata) error {
+	if !m.plan {
+		return nil
+	}
+	templateDir, err := findStepTemplates(topologyStep, m.cluster.Platform)
+	if err != nil {
+		return err
+	}
+	if err := tfInit(m, templateDir); err != nil {
+		return err
+	}
+	// the later steps read the outputs of the topology one,
+	// so only the topology can be planned before creating anything
+	if err := tfPlan(m, topologyStep, templateDir); err != nil {
+		return err
+	}
+	return confirm(os.Stdin, "Create the infrastructure above and continue the install?")
+}
+
 func installTopologyStep(m *metadata) error {
 	return runInstallStep(m, topologyStep)
 }
 
 func installBootstrapStep(m *metadata) error {
-	if !clusterIsBootstrapped(m.clusterDir) {
-		return runInstallStep(m, mastersStep, []string{bootstrapOn}...)
+	if clusterIsBootstrapped(m.clusterDir) {
+		return nil
 	}
-	return nil
+	if err := runInstallStep(m, mastersStep, []string{bootstrapOn}...); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(m.clusterDir, bootstrappedFileName), "")
 }
 
 func installTNCCNAMEStep(m *metadata) error {
@@ -150,21 +223,34 @@ func installJoinWorkersStep(m *metadata) error {
 }
 
 func runInstallStep(m *metadata, step string, extraArgs ...string) error {
+	defer m.timings.timeStep(step, time.Now())
+	if hasStateFile(m.clusterDir, step) {
+		// terraform picks up from the existing state, e.g. after a failed install
+		stepLogger(m, step).Info("Resuming step")
+	} else {
+		stepLogger(m, step).Info("Installing step")
+	}
 	templateDir, err := findStepTemplates(step, m.cluster.Platform)
 	if err != nil {
 		return err
 	}
-	if err := tfInit(m.clusterDir, templateDir); err != nil {
-		return err
+	if err := tfInit(m, templateDir); err != nil {
+		return withExitCode(err, ExitCodeProvisioning)
 	}
-	return tfApply(m.clusterDir, step, templateDir, extraArgs...)
+	return withExitCode(tfApply(m, step, templateDir, extraArgs...), ExitCodeProvisioning)
 }
 
 func generateIgnConfigStep(m *metadata) error {
+	defer m.timings.timeStep("ignition", time.Now())
 	c := configgenerator.New(m.cluster)
 	return c.GenerateIgnConfig(m.clusterDir)
 }
 
+func generateBootstrapOverridesStep(m *metadata) error {
+	c := configgenerator.New(m.cluster)
+	return c.GenerateBootstrapOverrides(m.clusterDir)
+}
+
 func generateTLSConfigStep(m *metadata) error {
 	if err := os.MkdirAll(filepath.Join(m.clusterDir, newTLSPath), os.ModeDir|0755); err != nil {
 		return fmt.Errorf("failed to create TLS directory at %s", newTLSPath)
@@ -173,3 +259,11 @@ func generateTLSConfigStep(m *metadata) error {
 	c := configgenerator.New(m.cluster)
 	return c.GenerateTLSConfig(m.clusterDir)
 }
+
+// stepLogger returns a logger annotated with the terraform step and the platform of the cluster.
+func stepLogger(m *metadata, step string) *log.Entry {
+	return log.WithFields(log.Fields{
+		"platform": m.cluster.Platform,
+		"step":     step,
+	})
+}
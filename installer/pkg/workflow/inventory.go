@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+const inventoryFileName = "inventory.ini"
+
+// inventoryGroups lists the Ansible groups of the inventory, with the step
+// creating their machines and the ignition config the machines boot from.
+var inventoryGroups = []struct {
+	name     string
+	step     string
+	ignition string
+}{
+	{name: "etcd", step: etcdStep, ignition: config.IgnitionEtcd},
+	{name: "masters", step: mastersStep, ignition: config.IgnitionMaster},
+	{name: "workers", step: joinWorkersStep, ignition: config.IgnitionWorker},
+}
+
+// inventoryHost is a machine of the cluster, as listed in its terraform state.
+type inventoryHost struct {
+	name    string
+	address string
+}
+
+// InventoryWorkflow creates new instances of the 'inventory' workflow,
+// responsible for writing an Ansible inventory of the machines of an
+// existing cluster, for configuration management pipelines.
+func InventoryWorkflow(clusterDir string) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			readClusterConfigStep,
+			writeInventoryStep,
+		},
+	}
+}
+
+func writeInventoryStep(m *metadata) error {
+	if m.cluster.Platform == config.PlatformAWS {
+		// their instances are only known to the autoscaling groups
+		log.Warn("The AWS masters and workers are managed by autoscaling groups and are not listed in the inventory")
+	}
+
+	hosts := map[string][]inventoryHost{}
+	for _, g := range inventoryGroups {
+		if !hasStateFile(m.clusterDir, g.step) {
+			continue
+		}
+		h, err := readStateHosts(m.clusterDir, g.step)
+		if err != nil {
+			return err
+		}
+		hosts[g.name] = h
+	}
+
+	clusterDir, err := filepath.Abs(m.clusterDir)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.clusterDir, inventoryFileName)
+	if err := ioutil.WriteFile(path, inventory(m.cluster, clusterDir, hosts), 0644); err != nil {
+		return fmt.Errorf("failed to write inventory: %v", err)
+	}
+	log.Infof("Inventory written to %s", path)
+	return nil
+}
+
+// inventory renders the Ansible inventory, in INI format, of the given hosts by group.
+func inventory(c config.Cluster, clusterDir string, hosts map[string][]inventoryHost) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "[all:vars]\n")
+	fmt.Fprintf(&buf, "ansible_user=%s\n", sshUser)
+	fmt.Fprintf(&buf, "cluster_name=%s\n", c.Name)
+	fmt.Fprintf(&buf, "cluster_id=%s\n", c.ClusterID)
+	fmt.Fprintf(&buf, "base_domain=%s\n", c.BaseDomain)
+	fmt.Fprintf(&buf, "platform=%s\n", c.Platform)
+	fmt.Fprintf(&buf, "pod_cidr=%s\n", c.Networking.PodCIDR)
+	fmt.Fprintf(&buf, "service_cidr=%s\n", c.Networking.ServiceCIDR)
+	fmt.Fprintf(&buf, "api_url=https://%s:%d\n", c.APIHost(), c.Networking.APIPort)
+
+	for _, g := range inventoryGroups {
+		fmt.Fprintf(&buf, "\n[%s]\n", g.name)
+		for _, h := range hosts[g.name] {
+			fmt.Fprintf(&buf, "%s ansible_host=%s\n", h.name, h.address)
+		}
+		fmt.Fprintf(&buf, "\n[%s:vars]\n", g.name)
+		fmt.Fprintf(&buf, "ignition_config=%s\n", filepath.Join(clusterDir, g.ignition))
+	}
+	return buf.Bytes()
+}
+
+// readStateHosts returns the machines, with their address, created by the
+// given step, sorted by name.
+func readStateHosts(stateDir, step string) ([]inventoryHost, error) {
+	data, err := ioutil.ReadFile(filepath.Join(stateDir, fmt.Sprintf("%s.tfstate", step)))
+	if err != nil {
+		return nil, err
+	}
+	var state tfState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s state: %v", step, err)
+	}
+
+	var hosts []inventoryHost
+	for _, module := range state.Modules {
+		for _, res := range module.Resources {
+			attrs := res.Primary.Attributes
+			var h inventoryHost
+			switch res.Type {
+			case "aws_instance":
+				h = inventoryHost{name: attrs["tags.Name"], address: attrs["private_ip"]}
+			case "libvirt_domain":
+				h = inventoryHost{name: attrs["name"], address: attrs["network_interface.0.addresses.0"]}
+			default:
+				continue
+			}
+			if h.name == "" {
+				h.name = res.Primary.ID
+			}
+			if h.address == "" {
+				h.address = h.name
+			}
+			hosts = append(hosts, h)
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].name < hosts[j].name })
+	return hosts, nil
+}
@@ -0,0 +1,52 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadStateHosts(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "inventory")
+	if err != nil {
+		t.Fatalf("failed to create state dir: %v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	state := `{
+  "modules": [
+    {
+      "path": ["root"],
+      "resources": {
+        "libvirt_domain.master.1": {"type": "libvirt_domain", "primary": {"id": "b", "attributes": {"name": "test-master1", "network_interface.0.addresses.0": "192.168.124.11"}}},
+        "libvirt_domain.master.0": {"type": "libvirt_domain", "primary": {"id": "a", "attributes": {"name": "test-master0", "network_interface.0.addresses.0": "192.168.124.10"}}},
+        "libvirt_volume.master.0": {"type": "libvirt_volume", "primary": {"id": "c", "attributes": {"name": "test-master0"}}}
+      }
+    },
+    {
+      "path": ["root", "etcd"],
+      "resources": {
+        "aws_instance.etcd_node": {"type": "aws_instance", "primary": {"id": "i-123", "attributes": {"private_ip": "10.0.0.5"}}}
+      }
+    }
+  ]
+}`
+	if err := ioutil.WriteFile(filepath.Join(stateDir, "masters.tfstate"), []byte(state), 0644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	got, err := readStateHosts(stateDir, mastersStep)
+	if err != nil {
+		t.Fatalf("failed to read hosts: %v", err)
+	}
+	expected := []inventoryHost{
+		{name: "i-123", address: "10.0.0.5"},
+		{name: "test-master0", address: "192.168.124.10"},
+		{name: "test-master1", address: "192.168.124.11"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test case read hosts: expected: %v, got: %v", expected, got)
+	}
+}
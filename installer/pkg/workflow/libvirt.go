@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// libvirtPool is the storage pool the libvirt provider creates the volumes in.
+const libvirtPool = "default"
+
+// destroyLibvirtLeftoversStep removes the libvirt domains, volumes and network
+// of the cluster that are not tracked by any terraform state, e.g. after a
+// partial install, since they would otherwise conflict with a re-install.
+// The retained resources are left in place.
+func destroyLibvirtLeftoversStep(m *metadata) error {
+	if m.cluster.Platform != config.PlatformLibvirt {
+		return nil
+	}
+	uri := m.cluster.Libvirt.URI
+
+	out, err := virsh(uri, "list", "--all", "--name")
+	if err != nil {
+		return err
+	}
+	for _, domain := range clusterResources(strings.Fields(out), m.cluster.Name) {
+		if contains(m.retainedNames, domain) {
+			continue
+		}
+		log.Infof("Removing leftover libvirt domain %s", domain)
+		// the domain may not be running
+		virsh(uri, "destroy", domain)
+		// --nvram removes the uefi variables store of the domain, if any
+		if _, err := virsh(uri, "undefine", "--nvram", domain); err != nil {
+			return err
+		}
+	}
+
+	out, err = virsh(uri, "vol-list", "--pool", libvirtPool)
+	if err != nil {
+		return err
+	}
+	for _, volume := range clusterResources(parseVolumeNames(out), m.cluster.Name) {
+		if contains(m.retainedNames, volume) {
+			continue
+		}
+		log.Infof("Removing leftover libvirt volume %s", volume)
+		if _, err := virsh(uri, "vol-delete", "--pool", libvirtPool, volume); err != nil {
+			return err
+		}
+	}
+
+	out, err = virsh(uri, "net-list", "--all", "--name")
+	if err != nil {
+		return err
+	}
+	network := m.cluster.Libvirt.Network.Name
+	for _, n := range strings.Fields(out) {
+		if n != network || contains(m.retainedNames, network) {
+			continue
+		}
+		log.Infof("Removing leftover libvirt network %s", network)
+		// the network may not be active
+		virsh(uri, "net-destroy", network)
+		if _, err := virsh(uri, "net-undefine", network); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// virsh runs the given virsh command against the libvirt daemon at uri and returns its output.
+var virsh = func(uri string, args ...string) (string, error) {
+	out, err := exec.Command("virsh", append([]string{"-c", uri}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run virsh %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return string(out), nil
+}
+
+// parseVolumeNames returns the names of the volumes listed by virsh vol-list.
+func parseVolumeNames(out string) []string {
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// skip the header, its separator and blank lines
+		if len(fields) == 0 || fields[0] == "Name" || strings.HasPrefix(fields[0], "---") {
+			continue
+		}
+		names = append(names, fields[0])
+	}
+	return names
+}
+
+// clusterResources returns the names of the domains and volumes the libvirt
+// steps create for the cluster: the master, etcd and worker domains and their
+// disks, their ignition configs and the base volume. The names are matched
+// exactly, so the resources of a cluster named e.g. <name>-2 are left alone.
+func clusterResources(names []string, clusterName string) []string {
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(clusterName) + `-((master|etcd|worker)[0-9]+|etcd[0-9]+\.ign|master(-bootstrap)?\.ign|worker\.ign|coreos_base)$`)
+	var matches []string
+	for _, name := range names {
+		if re.MatchString(name) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+func TestParseVolumeNames(t *testing.T) {
+	out := ` Name                     Path
+------------------------------------------------------------------------------
+ test1-coreos_base        /var/lib/libvirt/images/test1-coreos_base
+ test1-master0            /var/lib/libvirt/images/test1-master0
+ test10-master0           /var/lib/libvirt/images/test10-master0
+ test1-2-master0          /var/lib/libvirt/images/test1-2-master0
+ test1-master.ign         /var/lib/libvirt/images/test1-master.ign
+ test1-etcd0.ign          /var/lib/libvirt/images/test1-etcd0.ign
+ other.qcow2              /var/lib/libvirt/images/other.qcow2
+
+`
+	got := parseVolumeNames(out)
+	expected := []string{"test1-coreos_base", "test1-master0", "test10-master0", "test1-2-master0", "test1-master.ign", "test1-etcd0.ign", "other.qcow2"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test case parse volumes: expected: %v, got: %v", expected, got)
+	}
+
+	got = clusterResources(got, "test1")
+	expected = []string{"test1-coreos_base", "test1-master0", "test1-master.ign", "test1-etcd0.ign"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test case cluster volumes: expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestClusterResources(t *testing.T) {
+	domains := []string{"test-master0", "test-master12", "test-etcd0", "test-worker1", "test-2-master0", "test-2-worker0", "test-bastion", "testing-master0"}
+	got := clusterResources(domains, "test")
+	expected := []string{"test-master0", "test-master12", "test-etcd0", "test-worker1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test case cluster domains: expected: %v, got: %v", expected, got)
+	}
+
+	got = clusterResources(domains, "test-2")
+	expected = []string{"test-2-master0", "test-2-worker0"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test case cluster with a dash: expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestDestroyLibvirtLeftoversRetained(t *testing.T) {
+	defer func(f func(string, ...string) (string, error)) { virsh = f }(virsh)
+	var commands []string
+	virsh = func(uri string, args ...string) (string, error) {
+		switch strings.Join(args, " ") {
+		case "list --all --name":
+			return "test-master0\ntest-master1\ntest-2-master0\n", nil
+		case "vol-list --pool default":
+			return " Name Path\n----\n test-coreos_base /images/test-coreos_base\n test-master0 /images/test-master0\n test-master1 /images/test-master1\n", nil
+		case "net-list --all --name":
+			return "default\ntest-net\n", nil
+		}
+		commands = append(commands, strings.Join(args, " "))
+		return "", nil
+	}
+
+	m := &metadata{retainedNames: []string{"test-master1", "test-net"}}
+	m.cluster.Platform = config.PlatformLibvirt
+	m.cluster.Name = "test"
+	m.cluster.Libvirt.Network.Name = "test-net"
+	if err := destroyLibvirtLeftoversStep(m); err != nil {
+		t.Fatalf("failed to destroy the leftovers: %v", err)
+	}
+	expected := []string{
+		"destroy test-master0",
+		"undefine --nvram test-master0",
+		"vol-delete --pool default test-coreos_base",
+		"vol-delete --pool default test-master0",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Test case retained resources: expected: %v, got: %v", expected, commands)
+	}
+}
@@ -0,0 +1,215 @@
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	documentSeparatorRegexp = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+	// deprecatedAPIVersions maps the deprecated API versions, and the kinds
+	// they are deprecated for (all of them if none are listed), to their
+	// replacement. apps/v1beta2 is left out while the generated operators use it.
+	deprecatedAPIVersions = map[string]struct {
+		kinds       []string
+		replacement string
+	}{
+		"extensions/v1beta1": {[]string{"DaemonSet", "Deployment", "ReplicaSet"}, "apps/v1"},
+		"apps/v1beta1":       {nil, "apps/v1"},
+	}
+
+	// knownFields lists the top-level fields of the objects of the Kubernetes
+	// API groups; custom resources may have any.
+	knownFields = map[string]bool{
+		"aggregationRule":              true,
+		"allowVolumeExpansion":         true,
+		"allowedTopologies":            true,
+		"apiVersion":                   true,
+		"automountServiceAccountToken": true,
+		"binaryData":                   true,
+		"data":                         true,
+		"description":                  true,
+		"globalDefault":                true,
+		"imagePullSecrets":             true,
+		"items":                        true,
+		"kind":                         true,
+		"metadata":                     true,
+		"mountOptions":                 true,
+		"parameters":                   true,
+		"provisioner":                  true,
+		"reclaimPolicy":                true,
+		"roleRef":                      true,
+		"rules":                        true,
+		"secrets":                      true,
+		"spec":                         true,
+		"status":                       true,
+		"stringData":                   true,
+		"subjects":                     true,
+		"subsets":                      true,
+		"type":                         true,
+		"value":                        true,
+		"volumeBindingMode":            true,
+		"webhooks":                     true,
+	}
+
+	// knownMetadataFields lists the fields of the metadata of any object.
+	knownMetadataFields = map[string]bool{
+		"annotations":                true,
+		"clusterName":                true,
+		"creationTimestamp":          true,
+		"deletionGracePeriodSeconds": true,
+		"deletionTimestamp":          true,
+		"finalizers":                 true,
+		"generateName":               true,
+		"generation":                 true,
+		"initializers":               true,
+		"labels":                     true,
+		"name":                       true,
+		"namespace":                  true,
+		"ownerReferences":            true,
+		"resourceVersion":            true,
+		"selfLink":                   true,
+		"uid":                        true,
+	}
+
+	// builtinNamespaces exist in every cluster.
+	builtinNamespaces = []string{"default", "kube-public", "kube-system"}
+)
+
+// lintManifestsStep warns about the generated and extra manifests using
+// deprecated API versions or unknown fields, or targeting namespaces that
+// neither exist nor are created by the manifests, before any of them is used.
+func lintManifestsStep(m *metadata) error {
+	files, err := readManifests(m.clusterDir)
+	if err != nil {
+		return err
+	}
+	for _, warning := range lintManifests(files) {
+		log.Warn(warning)
+	}
+	return nil
+}
+
+type lintedObject struct {
+	source     string
+	apiVersion string
+	kind       string
+	name       string
+	namespace  string
+	fields     yaml.MapSlice
+	metadata   yaml.MapSlice
+}
+
+// lintManifests returns the problems found in the given manifests.
+func lintManifests(files []manifestFile) []string {
+	var objects []lintedObject
+	namespaces := map[string]bool{}
+	for _, ns := range builtinNamespaces {
+		namespaces[ns] = true
+	}
+	for _, f := range files {
+		if ext := filepath.Ext(f.name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		for i, doc := range documentSeparatorRegexp.Split(string(f.data), -1) {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			o, err := parseLintedObject(doc)
+			if err != nil {
+				// writing the manifests already validated them
+				continue
+			}
+			o.source = fmt.Sprintf("%s (document %d)", f.name, i)
+			if o.kind == "Namespace" && o.apiVersion == "v1" {
+				namespaces[o.name] = true
+			}
+			objects = append(objects, o)
+		}
+	}
+
+	var warnings []string
+	for _, o := range objects {
+		if d, ok := deprecatedAPIVersions[o.apiVersion]; ok && (d.kinds == nil || contains(d.kinds, o.kind)) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s %s uses the deprecated API version %s, use %s instead", o.source, o.kind, o.name, o.apiVersion, d.replacement))
+		}
+		if isKubernetesAPIVersion(o.apiVersion) {
+			for _, field := range unknownFields(o.fields, knownFields) {
+				warnings = append(warnings, fmt.Sprintf("%s: %s %s has the unknown field %s", o.source, o.kind, o.name, field))
+			}
+		}
+		for _, field := range unknownFields(o.metadata, knownMetadataFields) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s %s has the unknown field metadata.%s", o.source, o.kind, o.name, field))
+		}
+		if o.namespace != "" && !namespaces[o.namespace] {
+			warnings = append(warnings, fmt.Sprintf("%s: %s %s targets the namespace %s, which no manifest creates", o.source, o.kind, o.name, o.namespace))
+		}
+	}
+	return warnings
+}
+
+func parseLintedObject(doc string) (lintedObject, error) {
+	var o lintedObject
+	if err := yaml.Unmarshal([]byte(doc), &o.fields); err != nil {
+		return o, err
+	}
+	for _, item := range o.fields {
+		key, _ := item.Key.(string)
+		switch key {
+		case "apiVersion":
+			o.apiVersion, _ = item.Value.(string)
+		case "kind":
+			o.kind, _ = item.Value.(string)
+		case "metadata":
+			o.metadata, _ = item.Value.(yaml.MapSlice)
+		}
+	}
+	for _, item := range o.metadata {
+		key, _ := item.Key.(string)
+		switch key {
+		case "name":
+			o.name, _ = item.Value.(string)
+		case "namespace":
+			o.namespace, _ = item.Value.(string)
+		}
+	}
+	return o, nil
+}
+
+// isKubernetesAPIVersion returns whether the API version is that of the core
+// group or of another group of the Kubernetes API, i.e. not a custom resource.
+func isKubernetesAPIVersion(apiVersion string) bool {
+	i := strings.LastIndex(apiVersion, "/")
+	if i < 0 {
+		return true
+	}
+	group := apiVersion[:i]
+	return !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
+
+func unknownFields(fields yaml.MapSlice, known map[string]bool) []string {
+	var unknown []string
+	for _, item := range fields {
+		if key, _ := item.Key.(string); !known[key] {
+			unknown = append(unknown, fmt.Sprint(item.Key))
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
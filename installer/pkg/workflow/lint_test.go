@@ -0,0 +1,54 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintManifests(t *testing.T) {
+	testCases := []struct {
+		test     string
+		files    []manifestFile
+		expected []string
+	}{
+		{
+			test: "Valid",
+			files: []manifestFile{
+				{name: "manifests/00-namespace.yaml", data: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n")},
+				{name: "manifests/config.yaml", data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: test\ndata:\n  foo: bar\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: kube-system\n")},
+				{name: "tectonic/app-version.yaml", data: []byte("apiVersion: tco.coreos.com/v1\nkind: AppVersion\nmetadata:\n  name: test\nupgradereq: 1\n")},
+				{name: "manifests/kubeconfig.json", data: []byte("{}")},
+			},
+		},
+		{
+			test: "Deprecated API version",
+			files: []manifestFile{
+				{name: "manifests/deployment.yaml", data: []byte("apiVersion: extensions/v1beta1\nkind: Deployment\nmetadata:\n  name: test\n---\napiVersion: extensions/v1beta1\nkind: Ingress\nmetadata:\n  name: test\n")},
+			},
+			expected: []string{"manifests/deployment.yaml (document 0): Deployment test uses the deprecated API version extensions/v1beta1, use apps/v1 instead"},
+		},
+		{
+			test: "Unknown fields",
+			files: []manifestFile{
+				{name: "manifests/config.yaml", data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  label: {}\ndaat: {}\n")},
+			},
+			expected: []string{
+				"manifests/config.yaml (document 0): ConfigMap config has the unknown field daat",
+				"manifests/config.yaml (document 0): ConfigMap config has the unknown field metadata.label",
+			},
+		},
+		{
+			test: "Missing namespace",
+			files: []manifestFile{
+				{name: "manifests/config.yaml", data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: test\n")},
+			},
+			expected: []string{"manifests/config.yaml (document 0): ConfigMap config targets the namespace test, which no manifest creates"},
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := lintManifests(tc.files); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
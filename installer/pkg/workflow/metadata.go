@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+const metadataFileName = "metadata.json"
+
+// clusterMetadata identifies a cluster and the cloud resources created for
+// it, independently of the terraform state, e.g. to find them from another
+// machine.
+type clusterMetadata struct {
+	ClusterName string            `json:"clusterName"`
+	ClusterID   string            `json:"clusterID"`
+	Platform    config.Platform   `json:"platform"`
+	BaseDomain  string            `json:"baseDomain"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	AWS         *awsMetadata      `json:"aws,omitempty"`
+	Libvirt     *libvirtMetadata  `json:"libvirt,omitempty"`
+}
+
+// awsMetadata locates the AWS resources of a cluster.
+type awsMetadata struct {
+	Region        string `json:"region"`
+	Profile       string `json:"profile,omitempty"`
+	InstallerRole string `json:"installerRole,omitempty"`
+}
+
+// libvirtMetadata locates the libvirt resources of a cluster.
+type libvirtMetadata struct {
+	URI         string `json:"uri"`
+	NetworkName string `json:"networkName"`
+	// ResourcePrefix prefixes the name of the domains and volumes of the cluster.
+	ResourcePrefix string `json:"resourcePrefix"`
+}
+
+// newClusterMetadata returns the metadata of the given cluster.
+func newClusterMetadata(c config.Cluster) clusterMetadata {
+	md := clusterMetadata{
+		ClusterName: c.Name,
+		ClusterID:   c.ClusterID,
+		Platform:    c.Platform,
+		BaseDomain:  c.BaseDomain,
+	}
+	switch c.Platform {
+	case config.PlatformAWS:
+		// the tags set on every AWS resource created by the installer
+		md.Tags = map[string]string{
+			"tectonicClusterID":                             c.ClusterID,
+			fmt.Sprintf("kubernetes.io/cluster/%s", c.Name): "owned",
+		}
+		md.AWS = &awsMetadata{
+			Region:        c.AWS.Region,
+			Profile:       c.AWS.Profile,
+			InstallerRole: c.AWS.InstallerRole,
+		}
+	case config.PlatformLibvirt:
+		md.Libvirt = &libvirtMetadata{
+			URI:            c.Libvirt.URI,
+			NetworkName:    c.Libvirt.Network.Name,
+			ResourcePrefix: c.Name + "-",
+		}
+	}
+	return md
+}
+
+// readClusterMetadata reads the metadata of the cluster of clusterDir.
+func readClusterMetadata(clusterDir string) (*clusterMetadata, error) {
+	data, err := ioutil.ReadFile(filepath.Join(clusterDir, metadataFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster metadata: %v", err)
+	}
+	var md clusterMetadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster metadata: %v", err)
+	}
+	if md.ClusterName == "" || md.ClusterID == "" {
+		return nil, fmt.Errorf("invalid cluster metadata: no cluster name or ID")
+	}
+	return &md, nil
+}
+
+// cluster returns the config of the cluster of the metadata, holding what
+// locates its resources only.
+func (md clusterMetadata) cluster() config.Cluster {
+	c := config.Cluster{
+		Name:       md.ClusterName,
+		BaseDomain: md.BaseDomain,
+		Platform:   md.Platform,
+		Internal:   config.Internal{ClusterID: md.ClusterID},
+	}
+	if md.AWS != nil {
+		c.AWS.Region = md.AWS.Region
+		c.AWS.Profile = md.AWS.Profile
+		c.AWS.InstallerRole = md.AWS.InstallerRole
+	}
+	if md.Libvirt != nil {
+		c.Libvirt.URI = md.Libvirt.URI
+		c.Libvirt.Network.Name = md.Libvirt.NetworkName
+	}
+	return c
+}
+
+func generateMetadataStep(m *metadata) error {
+	data, err := json.MarshalIndent(newClusterMetadata(m.cluster), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster metadata: %v", err)
+	}
+	return writeFile(filepath.Join(m.clusterDir, metadataFileName), string(data))
+}
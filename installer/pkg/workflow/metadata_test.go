@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/installer/installer/pkg/config"
+	"github.com/openshift/installer/installer/pkg/config/aws"
+	"github.com/openshift/installer/installer/pkg/config/libvirt"
+)
+
+func TestNewClusterMetadata(t *testing.T) {
+	testCases := []struct {
+		test     string
+		cluster  config.Cluster
+		expected clusterMetadata
+	}{
+		{
+			test: "AWS",
+			cluster: config.Cluster{
+				Name:       "test",
+				BaseDomain: "example.com",
+				Platform:   config.PlatformAWS,
+				Internal:   config.Internal{ClusterID: "abc"},
+				AWS:        aws.AWS{Region: "eu-west-1", Profile: "dev"},
+			},
+			expected: clusterMetadata{
+				ClusterName: "test",
+				ClusterID:   "abc",
+				Platform:    config.PlatformAWS,
+				BaseDomain:  "example.com",
+				Tags:        map[string]string{"tectonicClusterID": "abc", "kubernetes.io/cluster/test": "owned"},
+				AWS:         &awsMetadata{Region: "eu-west-1", Profile: "dev"},
+			},
+		},
+		{
+			test: "libvirt",
+			cluster: config.Cluster{
+				Name:       "test",
+				BaseDomain: "tt.testing",
+				Platform:   config.PlatformLibvirt,
+				Internal:   config.Internal{ClusterID: "abc"},
+				Libvirt: libvirt.Libvirt{
+					URI:     "qemu:///system",
+					Network: libvirt.Network{Name: "tectonic"},
+				},
+			},
+			expected: clusterMetadata{
+				ClusterName: "test",
+				ClusterID:   "abc",
+				Platform:    config.PlatformLibvirt,
+				BaseDomain:  "tt.testing",
+				Libvirt:     &libvirtMetadata{URI: "qemu:///system", NetworkName: "tectonic", ResourcePrefix: "test-"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := newClusterMetadata(tc.cluster); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Test case %s: expected: %+v, got: %+v", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestReadDestroyConfigFromMetadata(t *testing.T) {
+	clusterDir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatalf("failed to create cluster dir: %v", err)
+	}
+	defer os.RemoveAll(clusterDir)
+	cluster := config.Cluster{
+		Name:       "test",
+		BaseDomain: "example.com",
+		Platform:   config.PlatformAWS,
+		Internal:   config.Internal{ClusterID: "abc"},
+		AWS:        aws.AWS{Region: "eu-west-1", Profile: "dev"},
+	}
+	m := &metadata{clusterDir: clusterDir, cluster: cluster}
+	if err := generateMetadataStep(m); err != nil {
+		t.Fatalf("failed to write cluster metadata: %v", err)
+	}
+
+	// neither the config nor any terraform state is left
+	m = &metadata{clusterDir: clusterDir}
+	if err := readDestroyConfigStep(m); err != nil {
+		t.Fatalf("Test case metadata: expected no error, got: %v", err)
+	}
+	if !m.fromMetadata {
+		t.Error("Test case metadata: expected the cluster to be read from its metadata")
+	}
+	if !reflect.DeepEqual(m.cluster, cluster) {
+		t.Errorf("Test case metadata: expected: %+v, got: %+v", cluster, m.cluster)
+	}
+
+	m = &metadata{clusterDir: clusterDir, retain: []string{"module.vpc"}}
+	if err := readDestroyConfigStep(m); err == nil {
+		t.Error("Test case retain: expected an error, got: <nil>")
+	}
+
+	// with a terraform state, the config is read as usual, and is missing
+	if err := ioutil.WriteFile(filepath.Join(clusterDir, tlsStep+".tfstate"), []byte("{}"), 0600); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	m = &metadata{clusterDir: clusterDir}
+	if err := readDestroyConfigStep(m); err == nil || m.fromMetadata {
+		t.Errorf("Test case state: expected the missing config to be read, got: %v", err)
+	}
+}
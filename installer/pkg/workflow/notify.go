@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	kubeconfigPath = "generated/auth/kubeconfig"
+	notifyTimeout  = 30 * time.Second
+)
+
+// notification is the summary of a workflow run POSTed to the notification URL.
+type notification struct {
+	Command           string  `json:"command"`
+	Result            string  `json:"result"`
+	Error             string  `json:"error,omitempty"`
+	ExitCode          int     `json:"exitCode"`
+	ErrorCode         string  `json:"errorCode,omitempty"`
+	DurationSeconds   float64 `json:"durationSeconds"`
+	ClusterName       string  `json:"clusterName,omitempty"`
+	ClusterID         string  `json:"clusterID,omitempty"`
+	ConsoleURL        string  `json:"consoleURL,omitempty"`
+	Kubeconfig        string  `json:"kubeconfig,omitempty"`
+	AdminPasswordFile string  `json:"adminPasswordFile,omitempty"`
+}
+
+// newNotification summarizes the run of the given command, which took
+// duration and failed with err unless it is nil. The cluster is only
+// described once its config is read; the secrets themselves are left out.
+func newNotification(m *metadata, command string, duration time.Duration, err error) notification {
+	n := notification{
+		Command:         command,
+		Result:          "success",
+		ExitCode:        ExitCode(err),
+		ErrorCode:       ErrorCode(err),
+		DurationSeconds: duration.Seconds(),
+	}
+	if err != nil {
+		n.Result = "failure"
+		n.Error = err.Error()
+	}
+	if m.cluster.Name == "" {
+		return n
+	}
+	n.ClusterName = m.cluster.Name
+	n.ClusterID = m.cluster.ClusterID
+	n.ConsoleURL = fmt.Sprintf("https://%s/", ingressDomain(m))
+	if clusterDir, err := filepath.Abs(m.clusterDir); err == nil {
+		n.Kubeconfig = filepath.Join(clusterDir, kubeconfigPath)
+		n.AdminPasswordFile = filepath.Join(clusterDir, authPath, adminPasswordFileName)
+	}
+	return n
+}
+
+// notify POSTs the notification to the URL. Failing to notify does not fail
+// the workflow, which has already run.
+func notify(url string, n notification) {
+	data, err := json.Marshal(n)
+	if err != nil {
+		log.Warnf("Failed to marshal the notification: %v", err)
+		return
+	}
+	client := &http.Client{Timeout: notifyTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		log.Warnf("Failed to notify %s: %v", url, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		log.Warnf("Failed to notify %s: %s", url, resp.Status)
+	}
+}
@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+func TestNotify(t *testing.T) {
+	received := make(chan notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("Test case notify: failed to decode notification: %v", err)
+		}
+		received <- n
+	}))
+	defer server.Close()
+
+	m := &metadata{
+		clusterDir: "/clusters/test",
+		cluster: config.Cluster{
+			Name:       "test",
+			BaseDomain: "example.com",
+			Internal:   config.Internal{ClusterID: "abc"},
+		},
+	}
+	notify(server.URL, newNotification(m, "destroy", 90*time.Second, withExitCode(errors.New("timed out"), ExitCodeDestroyIncomplete)))
+
+	expected := notification{
+		Command:           "destroy",
+		Result:            "failure",
+		Error:             "timed out",
+		ExitCode:          ExitCodeDestroyIncomplete,
+		ErrorCode:         "destroy-incomplete",
+		DurationSeconds:   90,
+		ClusterName:       "test",
+		ClusterID:         "abc",
+		ConsoleURL:        "https://test.example.com/",
+		Kubeconfig:        "/clusters/test/generated/auth/kubeconfig",
+		AdminPasswordFile: "/clusters/test/auth/admin-password",
+	}
+	if got := <-received; got != expected {
+		t.Errorf("Test case notify: expected: %+v, got: %+v", expected, got)
+	}
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// operatorStatusInterval is how often the status of the operators is
+// reported while waiting for the install to complete.
+const operatorStatusInterval = time.Minute
+
+// appVersionList holds the fields of the AppVersions, through which the
+// operators of the cluster report their rollout, that are reported.
+type appVersionList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			CurrentVersion string `json:"currentVersion"`
+			TargetVersion  string `json:"targetVersion"`
+			Paused         bool   `json:"paused"`
+			FailureStatus  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"failureStatus"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// operatorStatus is the state of an operator, in the terms of the ClusterOperator conditions.
+type operatorStatus struct {
+	name        string
+	available   bool
+	progressing bool
+	degraded    bool
+	message     string
+}
+
+// reportOperatorsUntil logs the status of the operators every operatorStatusInterval until done is closed.
+func reportOperatorsUntil(m *metadata, done <-chan struct{}) {
+	client, err := apiClient(m.clusterDir)
+	if err != nil {
+		log.Debugf("Not reporting the status of the operators: %v", err)
+		return
+	}
+	url := fmt.Sprintf("https://%s:%d/apis/tco.coreos.com/v1/appversions", m.cluster.APIHost(), m.cluster.Networking.APIPort)
+	ticker := time.NewTicker(operatorStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			statuses, err := fetchOperatorStatuses(client, url)
+			if err != nil {
+				log.Debugf("Failed to get the status of the operators: %v", err)
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimSpace(operatorTable(statuses)), "\n") {
+				log.Info(line)
+			}
+		}
+	}
+}
+
+func fetchOperatorStatuses(client *http.Client, url string) ([]operatorStatus, error) {
+	data, err := apiGet(client, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseOperatorStatuses(data)
+}
+
+func parseOperatorStatuses(data []byte) ([]operatorStatus, error) {
+	var list appVersionList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	var statuses []operatorStatus
+	for _, item := range list.Items {
+		s := item.Status
+		status := operatorStatus{
+			name:        item.Metadata.Name,
+			available:   s.CurrentVersion != "",
+			progressing: s.TargetVersion != "" && s.TargetVersion != s.CurrentVersion,
+			degraded:    s.FailureStatus != nil,
+		}
+		switch {
+		case s.FailureStatus != nil:
+			status.message = fmt.Sprintf("%s: %s", s.FailureStatus.Type, s.FailureStatus.Reason)
+		case s.Paused:
+			status.message = "paused"
+		case status.progressing && s.CurrentVersion == "":
+			status.message = fmt.Sprintf("installing %s", s.TargetVersion)
+		case status.progressing:
+			status.message = fmt.Sprintf("updating from %s to %s", s.CurrentVersion, s.TargetVersion)
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].name < statuses[j].name })
+	return statuses, nil
+}
+
+// operatorTable formats the statuses as a table, the degraded operators first.
+func operatorTable(statuses []operatorStatus) string {
+	sorted := append([]operatorStatus(nil), statuses...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].degraded && !sorted[j].degraded })
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATOR\tAVAILABLE\tPROGRESSING\tDEGRADED\tMESSAGE")
+	for _, s := range sorted {
+		fmt.Fprintf(w, "%s\t%t\t%t\t%t\t%s\n", s.name, s.available, s.progressing, s.degraded, s.message)
+	}
+	w.Flush()
+	// the empty messages are padded too
+	lines := strings.Split(buf.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return strings.Join(lines, "\n")
+}
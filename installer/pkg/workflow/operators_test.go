@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOperatorStatuses(t *testing.T) {
+	data := []byte(`{"items": [
+		{"metadata": {"name": "tectonic-ingress"}, "status": {"currentVersion": "1.0", "targetVersion": "1.1"}},
+		{"metadata": {"name": "kube-core"}, "status": {"currentVersion": "1.0", "targetVersion": "1.0"}},
+		{"metadata": {"name": "tectonic-alm"}, "status": {"targetVersion": "1.0", "failureStatus": {"type": "Update failed", "reason": "image pull"}}},
+		{"metadata": {"name": "tectonic-utility"}, "status": {"targetVersion": "1.0"}}
+	]}`)
+	expected := []operatorStatus{
+		{name: "kube-core", available: true},
+		{name: "tectonic-alm", progressing: true, degraded: true, message: "Update failed: image pull"},
+		{name: "tectonic-ingress", available: true, progressing: true, message: "updating from 1.0 to 1.1"},
+		{name: "tectonic-utility", progressing: true, message: "installing 1.0"},
+	}
+	got, err := parseOperatorStatuses(data)
+	if err != nil {
+		t.Fatalf("Test case parse: expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test case parse: expected: %+v, got: %+v", expected, got)
+	}
+
+	expectedTable := `OPERATOR          AVAILABLE  PROGRESSING  DEGRADED  MESSAGE
+tectonic-alm      false      true         true      Update failed: image pull
+kube-core         true       false        false
+tectonic-ingress  true       true         false     updating from 1.0 to 1.1
+tectonic-utility  false      true         false     installing 1.0
+`
+	if table := operatorTable(got); table != expectedTable {
+		t.Errorf("Test case table: expected: %q, got: %q", expectedTable, table)
+	}
+}
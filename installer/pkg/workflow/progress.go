@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Install phases, in the order they are run.
+const (
+	phaseAssets         = "asset generation"
+	phaseInfrastructure = "infrastructure provisioning"
+	phaseBootstrap      = "bootstrap"
+	phaseRollout        = "cluster rollout"
+	phaseComplete       = "complete"
+)
+
+// progress is the machine-readable state written to the progress file.
+type progress struct {
+	Phase   string    `json:"phase"`
+	Percent int       `json:"percent"`
+	Time    time.Time `json:"time"`
+}
+
+// phaseStep returns a step marking the start of the given phase.
+// The percentage reported is that of the workflow's steps already run.
+func phaseStep(phase string) Step {
+	return func(m *metadata) error {
+		m.timings.startPhase(phase)
+		return reportProgress(m, phase)
+	}
+}
+
+func reportProgress(m *metadata, phase string) error {
+	p := progress{
+		Phase:   phase,
+		Percent: m.percent,
+		Time:    time.Now().UTC(),
+	}
+	log.WithFields(log.Fields{
+		"phase":   p.Phase,
+		"percent": p.Percent,
+	}).Infof("Phase %q (%d%%)", p.Phase, p.Percent)
+
+	if m.progressFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(m.progressFile, append(data, '\n'), 0644)
+}
@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+// pxePath is the directory of the cluster directory the PXE configs are written to.
+const pxePath = "pxe"
+
+// PXE roles, i.e. the nodes which can be booted over the network.
+const (
+	PXERoleMaster = "master"
+	PXERoleWorker = "worker"
+)
+
+// pxeArtifacts are the URLs booting a node over the network.
+type pxeArtifacts struct {
+	Kernel    string
+	Initramfs string
+	// KernelArgs makes Container Linux fetch its Ignition config.
+	KernelArgs string
+}
+
+var ipxeTemplate = template.Must(template.New("ipxe").Parse(`#!ipxe
+kernel {{.Kernel}} {{.KernelArgs}}
+initrd {{.Initramfs}}
+boot
+`))
+
+var grubTemplate = template.Must(template.New("grub").Parse(`set timeout=5
+
+menuentry 'Container Linux' {
+	linux {{.Kernel}} {{.KernelArgs}}
+	initrd {{.Initramfs}}
+}
+`))
+
+// PXEWorkflow creates new instances of the 'pxe' workflow, responsible for
+// writing the iPXE and GRUB configs booting the nodes of the given role over
+// the network, with the Ignition config of the cluster directory served at
+// ignitionBaseURL, for datacenters provisioning their machines with PXE only.
+func PXEWorkflow(clusterDir, role, ignitionBaseURL string) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			readClusterConfigStep,
+			func(m *metadata) error {
+				return pxeStep(m, role, ignitionBaseURL)
+			},
+		},
+	}
+}
+
+func pxeStep(m *metadata, role, ignitionBaseURL string) error {
+	ignFile := config.IgnitionWorker
+	if role == PXERoleMaster {
+		ignFile = config.IgnitionMaster
+	}
+	if _, err := os.Stat(filepath.Join(m.clusterDir, ignFile)); err != nil {
+		return fmt.Errorf("no Ignition config for the %s nodes, run 'install assets' first: %v", role, err)
+	}
+	artifacts, err := newPXEArtifacts(m.cluster.ContainerLinux, ignitionBaseURL, ignFile)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(m.clusterDir, pxePath)
+	if err := os.MkdirAll(dir, os.ModeDir|0755); err != nil {
+		return fmt.Errorf("failed to create PXE directory at %s: %v", dir, err)
+	}
+	grubArtifacts, err := artifacts.grub()
+	if err != nil {
+		return err
+	}
+	for file, content := range map[string]struct {
+		t *template.Template
+		a pxeArtifacts
+	}{
+		role + ".ipxe":     {t: ipxeTemplate, a: artifacts},
+		role + ".grub.cfg": {t: grubTemplate, a: grubArtifacts},
+	} {
+		var buf bytes.Buffer
+		if err := content.t.Execute(&buf, content.a); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dir, file), buf.String()); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("PXE configs for the %s nodes written to %s", role, dir)
+	log.Infof("The nodes boot from %s and fetch %s/%s, which must be served there", artifacts.Kernel, strings.TrimSuffix(ignitionBaseURL, "/"), ignFile)
+	return nil
+}
+
+// newPXEArtifacts returns the URLs booting the given Container Linux release
+// with the Ignition config ignFile, served at ignitionBaseURL.
+func newPXEArtifacts(cl config.ContainerLinux, ignitionBaseURL, ignFile string) (pxeArtifacts, error) {
+	base, err := url.Parse(ignitionBaseURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return pxeArtifacts{}, fmt.Errorf("invalid Ignition base URL %q: must be an http or https URL", ignitionBaseURL)
+	}
+	base.Path = path.Join(base.Path, ignFile)
+
+	version := cl.Version
+	if version == config.ContainerLinuxVersionLatest {
+		version = "current"
+	}
+	release := fmt.Sprintf("https://%s.release.core-os.net/amd64-usr/%s", cl.Channel, version)
+	return pxeArtifacts{
+		Kernel:     release + "/coreos_production_pxe.vmlinuz",
+		Initramfs:  release + "/coreos_production_pxe_image.cpio.gz",
+		KernelArgs: fmt.Sprintf("coreos.first_boot=1 coreos.config.url=%s console=tty0 console=ttyS0", base),
+	}, nil
+}
+
+// grub returns the artifacts with the URLs in GRUB syntax, e.g.
+// (http,example.com)/path. GRUB fetches files over plain HTTP only, which
+// the Container Linux release servers support.
+func (a pxeArtifacts) grub() (pxeArtifacts, error) {
+	for _, u := range []*string{&a.Kernel, &a.Initramfs} {
+		parsed, err := url.Parse(*u)
+		if err != nil {
+			return a, err
+		}
+		*u = fmt.Sprintf("(http,%s)%s", parsed.Host, parsed.Path)
+	}
+	return a, nil
+}
@@ -0,0 +1,72 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+func TestNewPXEArtifacts(t *testing.T) {
+	testCases := []struct {
+		test          string
+		cl            config.ContainerLinux
+		url           string
+		expected      pxeArtifacts
+		expectedError bool
+	}{
+		{
+			test: "Latest",
+			cl:   config.ContainerLinux{Channel: config.ContainerLinuxChannelStable, Version: config.ContainerLinuxVersionLatest},
+			url:  "http://10.0.0.1:8080/test",
+			expected: pxeArtifacts{
+				Kernel:     "https://stable.release.core-os.net/amd64-usr/current/coreos_production_pxe.vmlinuz",
+				Initramfs:  "https://stable.release.core-os.net/amd64-usr/current/coreos_production_pxe_image.cpio.gz",
+				KernelArgs: "coreos.first_boot=1 coreos.config.url=http://10.0.0.1:8080/test/ignition-worker.ign console=tty0 console=ttyS0",
+			},
+		},
+		{
+			test: "Pinned",
+			cl:   config.ContainerLinux{Channel: config.ContainerLinuxChannelBeta, Version: "1745.3.1"},
+			url:  "https://ignition.example.com/",
+			expected: pxeArtifacts{
+				Kernel:     "https://beta.release.core-os.net/amd64-usr/1745.3.1/coreos_production_pxe.vmlinuz",
+				Initramfs:  "https://beta.release.core-os.net/amd64-usr/1745.3.1/coreos_production_pxe_image.cpio.gz",
+				KernelArgs: "coreos.first_boot=1 coreos.config.url=https://ignition.example.com/ignition-worker.ign console=tty0 console=ttyS0",
+			},
+		},
+		{
+			test:          "Not a URL",
+			cl:            config.ContainerLinux{Channel: config.ContainerLinuxChannelStable, Version: config.ContainerLinuxVersionLatest},
+			url:           "10.0.0.1/test",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := newPXEArtifacts(tc.cl, tc.url, config.IgnitionWorker)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Test case %s: expected error: %v, got: %v", tc.test, tc.expectedError, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestPXEArtifactsGRUB(t *testing.T) {
+	a := pxeArtifacts{
+		Kernel:     "https://stable.release.core-os.net/amd64-usr/current/coreos_production_pxe.vmlinuz",
+		Initramfs:  "https://stable.release.core-os.net/amd64-usr/current/coreos_production_pxe_image.cpio.gz",
+		KernelArgs: "coreos.first_boot=1",
+	}
+	expected := pxeArtifacts{
+		Kernel:     "(http,stable.release.core-os.net)/amd64-usr/current/coreos_production_pxe.vmlinuz",
+		Initramfs:  "(http,stable.release.core-os.net)/amd64-usr/current/coreos_production_pxe_image.cpio.gz",
+		KernelArgs: "coreos.first_boot=1",
+	}
+	got, err := a.grub()
+	if err != nil || got != expected {
+		t.Errorf("expected: %v, got: %v, %v", expected, got, err)
+	}
+}
@@ -0,0 +1,187 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
+
+const redacted = "REDACTED"
+
+var (
+	// ignition embeds file contents, including TLS keys, as data URLs
+	dataURLRegexp = regexp.MustCompile(`"data:[^"]*"`)
+	// matches PEM encoded private keys of any type
+	privateKeyRegexp      = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+	privateKeyBeginRegexp = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	privateKeyEndRegexp   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY-----`)
+
+	// credentialEnvVars are the environment variables holding cloud credentials.
+	credentialEnvVars = []string{"AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"}
+
+	secretsMu sync.RWMutex
+	// secrets are the literal values which must never be logged nor leave the machine.
+	secrets       []string
+	redactionOnce sync.Once
+)
+
+// enableRedaction makes the logs mask private keys, ignition data URLs and
+// the registered secrets, starting with the cloud credentials of the environment.
+func enableRedaction() {
+	redactionOnce.Do(func() {
+		for _, key := range credentialEnvVars {
+			registerSecrets(os.Getenv(key))
+		}
+		log.AddHook(redactHook{})
+	})
+}
+
+// registerSecrets adds the given values to the secrets masked from now on.
+func registerSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		known := false
+		for _, s := range secrets {
+			known = known || s == v
+		}
+		if !known {
+			secrets = append(secrets, v)
+		}
+	}
+}
+
+// registerClusterSecrets registers the admin password and the pull secrets of the cluster.
+func registerClusterSecrets(cluster config.Cluster) error {
+	registerSecrets(cluster.Admin.Password)
+	for _, path := range cluster.PullSecretPaths() {
+		pullSecret, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read pull secret: %v", err)
+		}
+		registerSecrets(string(bytes.TrimSpace(pullSecret)))
+		registerSecrets(pullSecretTokens(pullSecret)...)
+	}
+	return nil
+}
+
+// pullSecretTokens returns the credentials of every registry of the pull
+// secret, which may be logged on their own, e.g. by the kubelet or a pod.
+// A pull secret failing to parse is left to the config validation.
+func pullSecretTokens(pullSecret []byte) []string {
+	var secret struct {
+		Auths map[string]struct {
+			Auth          string `json:"auth"`
+			Password      string `json:"password"`
+			IdentityToken string `json:"identitytoken"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(pullSecret, &secret); err != nil {
+		return nil
+	}
+	var tokens []string
+	for _, auth := range secret.Auths {
+		tokens = append(tokens, auth.Auth, auth.Password, auth.IdentityToken)
+	}
+	return tokens
+}
+
+// redactSecrets masks private keys, ignition data URLs and the registered secrets in data.
+func redactSecrets(data []byte) []byte {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	return redact(data, secrets)
+}
+
+// redact masks private keys, ignition data URLs and the given secrets in data.
+func redact(data []byte, secrets []string) []byte {
+	data = privateKeyRegexp.ReplaceAll(data, []byte(redacted))
+	data = dataURLRegexp.ReplaceAll(data, []byte(`"data:,`+redacted+`"`))
+	for _, s := range secrets {
+		data = bytes.Replace(data, []byte(s), []byte(redacted), -1)
+	}
+	return data
+}
+
+// redactHook masks secrets in the message and fields of every log entry,
+// whatever the log level.
+type redactHook struct{}
+
+func (redactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (redactHook) Fire(entry *log.Entry) error {
+	entry.Message = string(redactSecrets([]byte(entry.Message)))
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case string:
+			entry.Data[k] = string(redactSecrets([]byte(v)))
+		case error:
+			entry.Data[k] = string(redactSecrets([]byte(v.Error())))
+		}
+	}
+	return nil
+}
+
+// redactWriter masks secrets in what is written through it, a line at a time,
+// so secrets split across writes are masked too. A private key spanning lines
+// is replaced as a whole. Flush writes the last, unterminated, line.
+type redactWriter struct {
+	w     io.Writer
+	buf   []byte
+	inKey bool
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := r.buf[:i+1]
+		r.buf = r.buf[i+1:]
+		if err := r.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes the buffered partial line, if any.
+func (r *redactWriter) Flush() error {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	line := r.buf
+	r.buf = nil
+	return r.writeLine(line)
+}
+
+func (r *redactWriter) writeLine(line []byte) error {
+	if r.inKey {
+		loc := privateKeyEndRegexp.FindIndex(line)
+		if loc == nil {
+			return nil
+		}
+		r.inKey = false
+		line = line[loc[1]:]
+	} else if loc := privateKeyBeginRegexp.FindIndex(line); loc != nil && !privateKeyEndRegexp.Match(line[loc[1]:]) {
+		r.inKey = true
+		line = append(append([]byte{}, line[:loc[0]]...), redacted+"\n"...)
+	}
+	_, err := r.w.Write(redactSecrets(line))
+	return err
+}
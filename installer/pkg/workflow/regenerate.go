@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// RegenerateCertsWorkflow creates new instances of the 'regenerate certs' workflow,
+// responsible for re-issuing the pre-install TLS assets, the manifests and the
+// ignition configs embedding them, e.g. once the certificates of an old cluster
+// directory expired. The assets of the old certificates are removed first.
+func RegenerateCertsWorkflow(clusterDir string) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			refreshConfigStep,
+			checkNotBootstrappedStep,
+			destroyAssetsStep,
+			destroyTLSAssetsStep,
+			installTLSAssetsStep,
+			regenerateNewTLSAssetsStep,
+			generateClusterConfigMaps,
+			hookStep(hookPreManifests),
+			installAssetsStep,
+			hookStep(hookPostManifests),
+			generateIgnConfigStep,
+		},
+	}
+}
+
+// checkNotBootstrappedStep refuses to continue once the cluster was bootstrapped,
+// since running nodes would not trust the re-issued certificates.
+func checkNotBootstrappedStep(m *metadata) error {
+	if clusterIsBootstrapped(m.clusterDir) {
+		return errors.New("the cluster has already been bootstrapped; its certificates can not be regenerated")
+	}
+	return nil
+}
+
+// regenerateNewTLSAssetsStep re-issues the certificates of the experimental
+// TLS engine, if they were generated before.
+func regenerateNewTLSAssetsStep(m *metadata) error {
+	if _, err := os.Stat(filepath.Join(m.clusterDir, newTLSPath)); os.IsNotExist(err) {
+		return nil
+	}
+	return generateTLSConfigStep(m)
+}
@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Environment variables overriding the retry policy.
+const (
+	retryAttemptsEnv = "TECTONIC_RETRY_ATTEMPTS"
+	retryDelayEnv    = "TECTONIC_RETRY_DELAY"
+	awsMaxRetriesEnv = "TECTONIC_AWS_MAX_RETRIES"
+)
+
+// retryPolicy is how the calls to the cloud are retried when they are
+// throttled or fail transiently, by every workflow.
+type retryPolicy struct {
+	// attempts bounds the runs of a TerraForm call failing with transient errors.
+	attempts int
+	// delay is the delay before running it again; it doubles on every attempt.
+	delay time.Duration
+	// awsMaxRetries, if set, bounds the retries of every AWS API call by the
+	// AWS provider itself, instead of tectonic_aws_max_retries.
+	awsMaxRetries int
+}
+
+var defaultRetryPolicy = retryPolicy{
+	attempts: 3,
+	delay:    30 * time.Second,
+}
+
+// retryPolicyFromEnv returns the default retry policy, overridden by the
+// environment. Invalid values are ignored with a warning.
+func retryPolicyFromEnv() retryPolicy {
+	p := defaultRetryPolicy
+	if v, ok := os.LookupEnv(retryAttemptsEnv); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			p.attempts = n
+		} else {
+			log.Warnf("Ignoring %s=%q: not a positive integer", retryAttemptsEnv, v)
+		}
+	}
+	if v, ok := os.LookupEnv(retryDelayEnv); ok {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			p.delay = d
+		} else {
+			log.Warnf("Ignoring %s=%q: not a duration, e.g. 30s", retryDelayEnv, v)
+		}
+	}
+	if v, ok := os.LookupEnv(awsMaxRetriesEnv); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			p.awsMaxRetries = n
+		} else {
+			log.Warnf("Ignoring %s=%q: not a positive integer", awsMaxRetriesEnv, v)
+		}
+	}
+	return p
+}
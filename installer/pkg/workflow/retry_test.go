@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyFromEnv(t *testing.T) {
+	testCases := []struct {
+		test     string
+		env      map[string]string
+		expected retryPolicy
+	}{
+		{
+			test:     "Defaults",
+			expected: defaultRetryPolicy,
+		},
+		{
+			test:     "Overridden",
+			env:      map[string]string{retryAttemptsEnv: "5", retryDelayEnv: "1m", awsMaxRetriesEnv: "40"},
+			expected: retryPolicy{attempts: 5, delay: time.Minute, awsMaxRetries: 40},
+		},
+		{
+			test:     "Invalid",
+			env:      map[string]string{retryAttemptsEnv: "0", retryDelayEnv: "soon", awsMaxRetriesEnv: "-1"},
+			expected: defaultRetryPolicy,
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, name := range []string{retryAttemptsEnv, retryDelayEnv, awsMaxRetriesEnv} {
+			os.Unsetenv(name)
+		}
+		for name, value := range tc.env {
+			os.Setenv(name, value)
+		}
+		if got := retryPolicyFromEnv(); got != tc.expected {
+			t.Errorf("Test case %s: expected: %+v, got: %+v", tc.test, tc.expected, got)
+		}
+	}
+	for _, name := range []string{retryAttemptsEnv, retryDelayEnv, awsMaxRetriesEnv} {
+		os.Unsetenv(name)
+	}
+}
@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// tfState is the subset of a terraform (v3) state file needed to list the resources it holds.
+type tfState struct {
+	Modules []struct {
+		Path      []string `json:"path"`
+		Resources map[string]struct {
+			Type    string `json:"type"`
+			Primary struct {
+				ID         string            `json:"id"`
+				Attributes map[string]string `json:"attributes"`
+			} `json:"primary"`
+		} `json:"resources"`
+	} `json:"modules"`
+}
+
+// stateResource is a resource managed by a terraform step.
+type stateResource struct {
+	Address string
+	ID      string
+	// Name is the name attribute of the resource, if any, e.g. of a libvirt domain.
+	Name string
+	Tags map[string]string
+}
+
+func (r stateResource) String() string {
+	s := fmt.Sprintf("%s (id: %s)", r.Address, r.ID)
+	if len(r.Tags) == 0 {
+		return s
+	}
+	tags := make([]string, 0, len(r.Tags))
+	for k, v := range r.Tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(tags)
+	return fmt.Sprintf("%s [%s]", s, strings.Join(tags, ", "))
+}
+
+// readStateResources returns the resources, excluding data sources, held by
+// the state file of the given step, sorted by address.
+func readStateResources(stateDir, step string) ([]stateResource, error) {
+	data, err := ioutil.ReadFile(filepath.Join(stateDir, fmt.Sprintf("%s.tfstate", step)))
+	if err != nil {
+		return nil, err
+	}
+	var state tfState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s state: %v", step, err)
+	}
+
+	var resources []stateResource
+	for _, module := range state.Modules {
+		prefix := ""
+		// the first element of a module path is always "root"
+		for i, name := range module.Path {
+			if i > 0 {
+				prefix += fmt.Sprintf("module.%s.", name)
+			}
+		}
+		for name, res := range module.Resources {
+			if strings.HasPrefix(name, "data.") {
+				continue
+			}
+			tags := map[string]string{}
+			for k, v := range res.Primary.Attributes {
+				if strings.HasPrefix(k, "tags.") && k != "tags.%" {
+					tags[strings.TrimPrefix(k, "tags.")] = v
+				}
+			}
+			resources = append(resources, stateResource{
+				Address: prefix + name,
+				ID:      res.Primary.ID,
+				Name:    res.Primary.Attributes["name"],
+				Tags:    tags,
+			})
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Address < resources[j].Address })
+	return resources, nil
+}
+
+// logCreatedResources logs the resources held by the terraform states of the
+// cluster, in the order they were created, e.g. once a workflow is interrupted.
+func logCreatedResources(m *metadata) {
+	var count int
+	for i := len(destroyOrder) - 1; i >= 0; i-- {
+		step := destroyOrder[i]
+		if !hasStateFile(m.clusterDir, step) {
+			continue
+		}
+		resources, err := readStateResources(m.clusterDir, step)
+		if err != nil {
+			log.Warnf("Failed to list the resources of step %s: %v", step, err)
+			continue
+		}
+		for _, r := range resources {
+			log.Infof("Created %s: %s", step, r)
+		}
+		count += len(resources)
+	}
+	if count > 0 {
+		log.Warnf("Interrupted with %d resources created: run the command again to resume, or destroy to remove them", count)
+	}
+}
@@ -0,0 +1,72 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadStateResources(t *testing.T) {
+	got, err := readStateResources("./fixtures", topologyStep)
+	if err != nil {
+		t.Fatalf("failed to read state resources: %v", err)
+	}
+
+	expected := []stateResource{
+		{
+			Address: "module.vpc.aws_internet_gateway.igw",
+			ID:      "igw-0123456789",
+			Tags:    map[string]string{},
+		},
+		{
+			Address: "module.vpc.aws_vpc.new_vpc",
+			ID:      "vpc-0123456789",
+			Tags:    map[string]string{"Name": "test-vpc", "tectonicClusterID": "abc"},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected: %v, got: %v", expected, got)
+	}
+
+	if s := got[1].String(); s != "module.vpc.aws_vpc.new_vpc (id: vpc-0123456789) [Name=test-vpc, tectonicClusterID=abc]" {
+		t.Errorf("unexpected resource description: %s", s)
+	}
+
+	if _, err := readStateResources("./fixtures", etcdStep); err == nil {
+		t.Errorf("expected an error reading a missing state file, got none")
+	}
+}
+
+func TestIsRetained(t *testing.T) {
+	retain := []string{"module.vpc", "aws_route53_zone.tectonic_int"}
+	testCases := []struct {
+		address  string
+		expected bool
+	}{
+		{address: "module.vpc.aws_vpc.new_vpc", expected: true},
+		{address: "aws_route53_zone.tectonic_int", expected: true},
+		{address: "aws_route53_zone.tectonic_int.1", expected: true},
+		{address: "module.vpcs.aws_vpc.new_vpc", expected: false},
+		{address: "aws_route53_zone.tectonic_ext", expected: false},
+	}
+	for _, tc := range testCases {
+		if got := isRetained(tc.address, retain); got != tc.expected {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.address, tc.expected, got)
+		}
+	}
+}
+
+func TestRetainedResources(t *testing.T) {
+	resources := []stateResource{
+		{Address: "libvirt_domain.master.0", Name: "test-master0"},
+		{Address: "libvirt_domain.master.1", Name: "test-master1"},
+		{Address: "libvirt_network.tectonic_net", Name: "test-net"},
+		{Address: "module.vpc.aws_vpc.new_vpc"},
+	}
+	addresses, names := retainedResources(resources, []string{"libvirt_domain.master.1", "libvirt_network", "module.vpc", "module.dns"})
+	if expected := []string{"libvirt_domain.master.1", "libvirt_network", "module.vpc"}; !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("Test case addresses: expected: %v, got: %v", expected, addresses)
+	}
+	if expected := []string{"test-master1", "test-net"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Test case names: expected: %v, got: %v", expected, names)
+	}
+}
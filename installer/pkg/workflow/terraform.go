@@ -1,49 +1,114 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+var (
+	// transientErrorRegexp matches cloud errors which are expected to go away on their own:
+	// rate limiting, temporary lack of capacity and not yet consistent reads of the
+	// resources just created, but not other missing resources, e.g. a wrong AMI.
+	transientErrorRegexp = regexp.MustCompile(`RequestLimitExceeded|Throttling|InsufficientInstanceCapacity|InsufficientFreeAddressesInSubnet|Invalid(InstanceID|Group|RouteTableID|NetworkInterfaceID|AllocationID)\.NotFound|RequestError: send request failed|connection reset by peer|TLS handshake timeout`)
 )
 
-func terraformExec(clusterDir string, args ...string) error {
+func terraformExec(m *metadata, args ...string) error {
+	return terraformExecWithTimeout(m, 0, args...)
+}
+
+// terraformExecWithTimeout is like terraformExec, but interrupts every TerraForm attempt
+// which does not complete within the timeout, unless it is zero.
+func terraformExecWithTimeout(m *metadata, timeout time.Duration, args ...string) error {
 	// Create an executor
 	ex, err := newExecutor()
 	if err != nil {
 		return fmt.Errorf("Could not create Terraform executor: %s", err)
 	}
+	ex.stdout, ex.stderr = m.stdout, m.stderr
+	ex.ctx = m.context()
 
-	err = ex.execute(clusterDir, args...)
-	if err != nil {
+	policy := retryPolicyFromEnv()
+	if err := retryTransient(m.context(), func() error { return ex.executeWithTimeout(m.clusterDir, timeout, args...) }, policy.attempts, policy.delay); err != nil {
+		if isQuotaExceeded(err) {
+			return withExitCode(fmt.Errorf("Failed to run Terraform: %s", err), ExitCodeQuota)
+		}
 		return fmt.Errorf("Failed to run Terraform: %s", err)
 	}
 	return nil
 }
 
-func tfApply(clusterDir string, state string, templateDir string, extraArgs ...string) error {
+// retryTransient runs f, up to the given number of attempts, as long as it fails with transient errors.
+// It stops waiting for the next attempt once ctx is done, e.g. on interrupt.
+func retryTransient(ctx context.Context, f func() error, attempts int, delay time.Duration) error {
+	for i := 1; ; i++ {
+		err := f()
+		if err == nil || i == attempts || !isTransient(err) {
+			return err
+		}
+		log.Warnf("Transient error, retrying in %s (attempt %d of %d): %v", delay, i+1, attempts, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func isTransient(err error) bool {
+	e, ok := err.(*errExecution)
+	return ok && transientErrorRegexp.MatchString(e.stderr)
+}
+
+func tfApply(m *metadata, state string, templateDir string, extraArgs ...string) error {
 	defaultArgs := []string{
 		"apply",
 		"-auto-approve",
+		"-input=false",
+		fmt.Sprintf("-state=%s.tfstate", state),
+	}
+	extraArgs = append(extraArgs, templateDir)
+	args := append(defaultArgs, extraArgs...)
+	return terraformExec(m, args...)
+}
+
+func tfPlan(m *metadata, state, templateDir string, extraArgs ...string) error {
+	defaultArgs := []string{
+		"plan",
+		"-input=false",
 		fmt.Sprintf("-state=%s.tfstate", state),
 	}
 	extraArgs = append(extraArgs, templateDir)
 	args := append(defaultArgs, extraArgs...)
-	return terraformExec(clusterDir, args...)
+	return terraformExec(m, args...)
 }
 
-func tfDestroy(clusterDir, state, templateDir string, extraArgs ...string) error {
+func tfDestroy(m *metadata, state, templateDir string, timeout time.Duration, extraArgs ...string) error {
 	defaultArgs := []string{
 		"destroy",
 		"-force",
+		"-input=false",
 		fmt.Sprintf("-state=%s.tfstate", state),
 	}
 	extraArgs = append(extraArgs, templateDir)
 	args := append(defaultArgs, extraArgs...)
-	return terraformExec(clusterDir, args...)
+	return terraformExecWithTimeout(m, timeout, args...)
+}
+
+// tfStateRm stops managing the given resources from the state of a step, without destroying them.
+func tfStateRm(m *metadata, state string, addresses ...string) error {
+	args := append([]string{"state", "rm", fmt.Sprintf("-state=%s.tfstate", state)}, addresses...)
+	return terraformExec(m, args...)
 }
 
-func tfInit(clusterDir, templateDir string) error {
-	return terraformExec(clusterDir, "init", templateDir)
+func tfInit(m *metadata, templateDir string) error {
+	return terraformExec(m, "init", templateDir)
 }
 
 func hasStateFile(stateDir string, stateName string) bool {
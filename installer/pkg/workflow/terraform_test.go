@@ -0,0 +1,210 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransient(t *testing.T) {
+	testCases := []struct {
+		test             string
+		errs             []error
+		expectedAttempts int
+		expectedError    bool
+	}{
+		{
+			test:             "Success",
+			errs:             []error{nil},
+			expectedAttempts: 1,
+			expectedError:    false,
+		},
+		{
+			test: "Transient error, then success",
+			errs: []error{
+				&errExecution{err: errors.New("exit status 1"), stderr: "Error: RequestLimitExceeded: Request limit exceeded."},
+				nil,
+			},
+			expectedAttempts: 2,
+			expectedError:    false,
+		},
+		{
+			test: "Permanent error",
+			errs: []error{
+				&errExecution{err: errors.New("exit status 1"), stderr: "Error: InvalidParameterValue"},
+			},
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			test: "Transient errors until out of attempts",
+			errs: []error{
+				&errExecution{err: errors.New("exit status 1"), stderr: "InvalidInstanceID.NotFound"},
+				&errExecution{err: errors.New("exit status 1"), stderr: "InvalidInstanceID.NotFound"},
+				&errExecution{err: errors.New("exit status 1"), stderr: "InvalidInstanceID.NotFound"},
+				nil,
+			},
+			expectedAttempts: 3,
+			expectedError:    true,
+		},
+		{
+			test: "Missing resource not created by the step",
+			errs: []error{
+				&errExecution{err: errors.New("exit status 1"), stderr: "InvalidAMIID.NotFound: The image id '[ami-0123]' does not exist"},
+			},
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var attempts int
+		err := retryTransient(context.Background(), func() error {
+			err := tc.errs[attempts]
+			attempts++
+			return err
+		}, 3, 0)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Test case %s: retryTransient() expected error: %v, got: %v", tc.test, tc.expectedError, err)
+		}
+		if attempts != tc.expectedAttempts {
+			t.Errorf("Test case %s: expected %d attempts, got: %d", tc.test, tc.expectedAttempts, attempts)
+		}
+	}
+}
+
+func TestRetryTransientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var attempts int
+	err := retryTransient(ctx, func() error {
+		attempts++
+		return &errExecution{err: errors.New("exit status 1"), stderr: "Error: RequestLimitExceeded: Request limit exceeded."}
+	}, 3, time.Hour)
+	if err != context.Canceled {
+		t.Errorf("expected: %v, got: %v", context.Canceled, err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got: %d", attempts)
+	}
+}
+
+func TestErrExecution(t *testing.T) {
+	stderr := `Error: Error applying plan:
+
+1 error(s) occurred:
+
+* module.vpc.aws_vpc.new_vpc (destroy): 1 error(s) occurred:
+
+* aws_vpc.new_vpc: DependencyViolation: The vpc 'vpc-1234' has dependencies and cannot be deleted.
+`
+	testCases := []struct {
+		test     string
+		err      error
+		expected string
+	}{
+		{
+			test:     "No error lines",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: "Error: something went wrong"},
+			expected: "exit status 1",
+		},
+		{
+			test:     "Nested errors",
+			err:      &errExecution{err: errors.New("exit status 1"), stderr: stderr},
+			expected: "exit status 1: aws_vpc.new_vpc: DependencyViolation: The vpc 'vpc-1234' has dependencies and cannot be deleted.",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.err.Error(); got != tc.expected {
+			t.Errorf("Test case %s: expected: %s, got: %s", tc.test, tc.expected, got)
+		}
+	}
+}
+
+func TestExecuteWithTimeout(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+	ex := &executor{binaryPath: sleep}
+
+	if err := ex.executeWithTimeout(".", time.Minute, "0"); err != nil {
+		t.Errorf("Test case completed: expected no error, got: %v", err)
+	}
+	err = ex.executeWithTimeout(".", 10*time.Millisecond, "60")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Test case timed out: expected a timeout error, got: %v", err)
+	}
+}
+
+func TestExecuteOutput(t *testing.T) {
+	echo, err := exec.LookPath("echo")
+	if err != nil {
+		t.Skip("echo is not available")
+	}
+	var stdout, stderr bytes.Buffer
+	ex := &executor{binaryPath: echo, stdout: &stdout, stderr: &stderr}
+
+	if err := ex.executeWithTimeout(".", time.Minute, "hello"); err != nil {
+		t.Fatalf("Test case output: expected no error, got: %v", err)
+	}
+	if got := stdout.String(); got != "hello\n" {
+		t.Errorf("Test case stdout: expected: %q, got: %q", "hello\n", got)
+	}
+	if got := stderr.String(); got != "" {
+		t.Errorf("Test case stderr: expected: %q, got: %q", "", got)
+	}
+}
+
+func TestExecuteInterrupted(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ex := &executor{binaryPath: sleep, ctx: ctx}
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err = ex.executeWithTimeout(".", time.Minute, "60")
+	if e, ok := err.(*errExecution); !ok || e.err != errInterrupted {
+		t.Errorf("Test case interrupted: expected an interrupted error, got: %v", err)
+	}
+}
+
+func TestKillTerraform(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	ex := &executor{binaryPath: sh}
+	done := make(chan error, 1)
+	// the output is only copied until every process of the group exited
+	go func() { done <- ex.executeWithTimeout(".", time.Minute, "-c", "sleep 60 & wait") }()
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		runningMu.Lock()
+		n := len(running)
+		runningMu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("Test case killed: the command did not start")
+		}
+	}
+	KillTerraform()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Test case killed: expected an error, got: <nil>")
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("Test case killed: expected the process group to be killed")
+	}
+}
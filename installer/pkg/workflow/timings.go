@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/openshift/installer/installer/pkg/version"
+)
+
+// timings is the machine-readable record of how long a workflow run took,
+// written to the timings file, e.g. to track performance across releases.
+type timings struct {
+	InstallerVersion string   `json:"installerVersion"`
+	Platform         string   `json:"platform,omitempty"`
+	Result           string   `json:"result"`
+	TotalSeconds     float64  `json:"totalSeconds"`
+	Phases           []timing `json:"phases,omitempty"`
+	Steps            []timing `json:"steps,omitempty"`
+
+	// phase is the current phase, started at phaseStart.
+	phase      string
+	phaseStart time.Time
+}
+
+// timing is how long a phase or step took.
+type timing struct {
+	Name    string  `json:"name"`
+	Seconds float64 `json:"seconds"`
+}
+
+// startPhase ends the current phase, if any, and starts the given one.
+func (t *timings) startPhase(phase string) {
+	t.endPhase()
+	t.phase = phase
+	t.phaseStart = time.Now()
+}
+
+// endPhase records the duration of the current phase, if any.
+func (t *timings) endPhase() {
+	if t.phase == "" {
+		return
+	}
+	t.Phases = append(t.Phases, timing{Name: t.phase, Seconds: time.Since(t.phaseStart).Seconds()})
+	t.phase = ""
+}
+
+// timeStep records how long the step, started at start, took.
+func (t *timings) timeStep(step string, start time.Time) {
+	t.Steps = append(t.Steps, timing{Name: step, Seconds: time.Since(start).Seconds()})
+}
+
+// writeTimings writes the timings of the run, which took duration and failed
+// with err unless it is nil, to the timings file. Failing to write it does
+// not fail the workflow.
+func writeTimings(m *metadata, duration time.Duration, err error) {
+	t := m.timings
+	t.endPhase()
+	t.InstallerVersion = version.Get().Version
+	t.Platform = string(m.cluster.Platform)
+	t.Result = "success"
+	if err != nil {
+		t.Result = "failure"
+	}
+	t.TotalSeconds = duration.Seconds()
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(m.timingsFile, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		log.Warnf("Failed to write the timings to %s: %v", m.timingsFile, err)
+	}
+}
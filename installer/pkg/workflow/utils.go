@@ -5,31 +5,61 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/openshift/installer/installer/pkg/config"
 	configgenerator "github.com/openshift/installer/installer/pkg/config-generator"
+	"github.com/openshift/installer/installer/pkg/validate"
 )
 
 const (
-	assetsStep       = "assets"
-	binaryPrefix     = "installer"
-	bootstrapOff     = "-var=tectonic_bootstrap=false"
-	bootstrapOn      = "-var=tectonic_bootstrap=true"
-	configFileName   = "config.yaml"
-	etcdStep         = "etcd"
-	internalFileName = "internal.yaml"
-	joinWorkersStep  = "joining_workers"
-	mastersStep      = "masters"
-	newTLSStep       = "newtls"
-	stepsBaseDir     = "steps"
-	tlsStep          = "tls"
-	tncDNSStep       = "tnc_dns"
-	topologyStep     = "topology"
+	assetsStep           = "assets"
+	binaryPrefix         = "installer"
+	bootstrappedFileName = "bootstrapped"
+	bootstrapOff         = "-var=tectonic_bootstrap=false"
+	bootstrapOn          = "-var=tectonic_bootstrap=true"
+	configFileName       = "config.yaml"
+	etcdStep             = "etcd"
+	internalFileName     = "internal.yaml"
+	joinWorkersStep      = "joining_workers"
+	mastersStep          = "masters"
+	newTLSStep           = "newtls"
+	stepsBaseDir         = "steps"
+	tlsStep              = "tls"
+	tncDNSStep           = "tnc_dns"
+	topologyStep         = "topology"
 )
 
+// confirm asks the given yes/no question, reading the answer from in,
+// and returns an error unless the answer is yes.
+func confirm(in io.Reader, question string) error {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errors.New("aborted by the user")
+}
+
+// editorCommand returns the command line of the user's editor, from $VISUAL
+// or $EDITOR, falling back to vi.
+func editorCommand() []string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if args := strings.Fields(os.Getenv(key)); len(args) != 0 {
+			return args
+		}
+	}
+	return []string{"vi"}
+}
+
 func copyFile(fromFilePath, toFilePath string) error {
 	from, err := os.Open(fromFilePath)
 	if err != nil {
@@ -37,7 +67,7 @@ func copyFile(fromFilePath, toFilePath string) error {
 	}
 	defer from.Close()
 
-	to, err := os.OpenFile(toFilePath, os.O_RDWR|os.O_CREATE, 0666)
+	to, err := os.OpenFile(toFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 	if err != nil {
 		return err
 	}
@@ -102,6 +132,12 @@ func generateClusterConfigMaps(m *metadata) error {
 		return err
 	}
 
+	// read by the assets step, even empty
+	cloudProviderConfigFilePath := filepath.Join(clusterGeneratedPath, cloudProviderConfigFileName)
+	if err := ioutil.WriteFile(cloudProviderConfigFilePath, []byte(configGenerator.CloudProviderConfig()), 0644); err != nil {
+		return err
+	}
+
 	tncoConfig, err := configGenerator.TncoConfig()
 	if err != nil {
 		return err
@@ -123,7 +159,7 @@ func generateClusterConfigMaps(m *metadata) error {
 	}
 
 	kubeSystemConfigFilePath := filepath.Join(kubePath, kubeSystemFileName)
-	if err := writeFile(kubeSystemConfigFilePath, kubeSystem); err != nil {
+	if err := writeManifest(kubeSystemConfigFilePath, kubeSystem); err != nil {
 		return err
 	}
 
@@ -138,7 +174,98 @@ func generateClusterConfigMaps(m *metadata) error {
 	}
 
 	tectonicSystemConfigFilePath := filepath.Join(tectonicPath, tectonicSystemFileName)
-	return writeFile(tectonicSystemConfigFilePath, tectonicSystem)
+	if err := writeManifest(tectonicSystemConfigFilePath, tectonicSystem); err != nil {
+		return err
+	}
+
+	if err := copyExtraManifests(m.clusterDir); err != nil {
+		return err
+	}
+
+	if m.cluster.TLS.AdditionalTrustBundlePath == "" {
+		return nil
+	}
+	userCABundle, err := configGenerator.UserCABundle()
+	if err != nil {
+		return err
+	}
+	return writeManifest(filepath.Join(kubePath, userCABundleFileName), userCABundle)
+}
+
+// generatedManifestNames are the names of the manifests written to the
+// manifests directory of the cluster by the installer and by the assets step,
+// i.e. the manifest_names of modules/bootkube, which the user supplied ones
+// must not overwrite.
+var generatedManifestNames = map[string]bool{
+	kubeSystemFileName:                        true,
+	userCABundleFileName:                      true,
+	"01-tectonic-namespace.yaml":              true,
+	"02-ingress-namespace.yaml":               true,
+	"03-openshift-web-console-namespace.yaml": true,
+	"app-version-kind.yaml":                   true,
+	"app-version-tectonic-network.yaml":       true,
+	"app-version-tnc.yaml":                    true,
+	"kube-apiserver-secret.yaml":              true,
+	"kube-cloud-config.yaml":                  true,
+	"kube-controller-manager-secret.yaml":     true,
+	"node-config-kind.yaml":                   true,
+	"openshift-apiserver-secret.yaml":         true,
+	"tectonic-network-operator.yaml":          true,
+	"tectonic-node-controller-operator.yaml":  true,
+	"tnc-tls-secret.yaml":                     true,
+}
+
+// listExtraManifests returns the names of the user supplied manifests
+// found in the extra manifests directory of the cluster, after validating them.
+func listExtraManifests(clusterDir string) ([]string, error) {
+	dir := filepath.Join(clusterDir, extraManifestsPath)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read extra manifests directory %s: %v", dir, err)
+	}
+
+	var names []string
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		name := f.Name()
+		if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		if generatedManifestNames[name] {
+			return nil, fmt.Errorf("extra manifest %s conflicts with a generated manifest", name)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := validate.Manifest(string(data)); err != nil {
+			return nil, fmt.Errorf("extra manifest %s: %v", name, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// copyExtraManifests copies the user supplied manifests next to the generated ones,
+// from where they are installed onto the bootstrap node.
+func copyExtraManifests(clusterDir string) error {
+	names, err := listExtraManifests(clusterDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		from := filepath.Join(clusterDir, extraManifestsPath, name)
+		to := filepath.Join(clusterDir, kubeSystemPath, name)
+		if err := copyFile(from, to); err != nil {
+			return fmt.Errorf("failed to copy extra manifest %s: %v", name, err)
+		}
+	}
+	return nil
 }
 
 func readClusterConfig(configFilePath string, internalFilePath string) (*config.Cluster, error) {
@@ -152,7 +279,16 @@ func readClusterConfig(configFilePath string, internalFilePath string) (*config.
 		if err != nil {
 			return nil, fmt.Errorf("%s is not a valid internal file: %s", internalFilePath, err)
 		}
+		if id := requestedClusterID(*cfg); id != "" && id != internal.ClusterID {
+			return nil, fmt.Errorf("%s requests cluster ID %s, but the cluster was initialized with %s, which cannot be changed", configFilePath, id, internal.ClusterID)
+		}
 		cfg.Internal = *internal
+		if cfg.Admin.Password == "" {
+			cfg.Admin.Password = internal.AdminPassword
+		}
+		if cfg.Admin.Password == "" {
+			return nil, fmt.Errorf("%s has no admin password and %s no generated one", configFilePath, internalFilePath)
+		}
 	}
 
 	return cfg, nil
@@ -169,9 +305,12 @@ func readClusterConfigStep(m *metadata) error {
 	if err != nil {
 		return err
 	}
+	if err := registerClusterSecrets(*cluster); err != nil {
+		return err
+	}
 
 	if err := cluster.ValidateAndLog(); err != nil {
-		return err
+		return withExitCode(err, ExitCodeValidation)
 	}
 
 	m.cluster = *cluster
@@ -179,6 +318,15 @@ func readClusterConfigStep(m *metadata) error {
 	return nil
 }
 
+// writeManifest validates the given manifest before writing it,
+// so mistakes surface now rather than at bootstrap time.
+func writeManifest(path, content string) error {
+	if err := validate.Manifest(content); err != nil {
+		return fmt.Errorf("generated manifest %s: %v", path, err)
+	}
+	return writeFile(path, content)
+}
+
 func writeFile(path, content string) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -207,10 +355,19 @@ func baseLocation() (string, error) {
 	return path.Dir(ex), nil
 }
 
+// clusterIsBootstrapped reports whether the bootstrap step of the cluster completed.
+// A masters state file alone is not enough: terraform writes one even when the
+// apply fails halfway, and re-running the install must then retry the bootstrap.
+// Clusters installed before the marker was introduced are recognized by the state
+// of the etcd step, which only runs after bootstrapping.
 func clusterIsBootstrapped(stateDir string) bool {
+	if _, err := os.Stat(filepath.Join(stateDir, bootstrappedFileName)); err == nil {
+		return true
+	}
 	return hasStateFile(stateDir, topologyStep) &&
 		hasStateFile(stateDir, mastersStep) &&
-		hasStateFile(stateDir, tncDNSStep)
+		hasStateFile(stateDir, tncDNSStep) &&
+		hasStateFile(stateDir, etcdStep)
 }
 
 func createTNCCNAME(m *metadata) error {
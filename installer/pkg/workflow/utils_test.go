@@ -0,0 +1,152 @@
+package workflow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	testCases := []struct {
+		test          string
+		answer        string
+		expectedError bool
+	}{
+		{
+			test:          "Yes",
+			answer:        "y\n",
+			expectedError: false,
+		},
+		{
+			test:          "Yes, without a newline",
+			answer:        " YES",
+			expectedError: false,
+		},
+		{
+			test:          "No",
+			answer:        "n\n",
+			expectedError: true,
+		},
+		{
+			test:          "No answer",
+			answer:        "",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := confirm(strings.NewReader(tc.answer), "Continue?")
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Test case %s: confirm() expected error: %v, got: %v", tc.test, tc.expectedError, err)
+		}
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "copy")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	from := filepath.Join(dir, "from")
+	to := filepath.Join(dir, "to")
+	if err := ioutil.WriteFile(from, []byte("short"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", from, err)
+	}
+	if err := ioutil.WriteFile(to, []byte("a longer previous copy"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", to, err)
+	}
+	if err := copyFile(from, to); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	got, err := ioutil.ReadFile(to)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", to, err)
+	}
+	if string(got) != "short" {
+		t.Errorf("expected: short, got: %s", got)
+	}
+}
+
+func TestClusterIsBootstrapped(t *testing.T) {
+	testCases := []struct {
+		test     string
+		files    []string
+		expected bool
+	}{
+		{
+			test:     "Nothing installed",
+			expected: false,
+		},
+		{
+			test:     "Bootstrap failed halfway",
+			files:    []string{"topology.tfstate", "tnc_dns.tfstate", "masters.tfstate"},
+			expected: false,
+		},
+		{
+			test:     "Bootstrap completed",
+			files:    []string{"topology.tfstate", "tnc_dns.tfstate", "masters.tfstate", bootstrappedFileName},
+			expected: true,
+		},
+		{
+			test:     "Installed before the bootstrap marker",
+			files:    []string{"topology.tfstate", "tnc_dns.tfstate", "masters.tfstate", "etcd.tfstate"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir, err := ioutil.TempDir("", "bootstrapped")
+		if err != nil {
+			t.Fatalf("failed to create cluster dir: %v", err)
+		}
+		for _, f := range tc.files {
+			if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", f, err)
+			}
+		}
+		if got := clusterIsBootstrapped(dir); got != tc.expected {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	testCases := []struct {
+		test     string
+		visual   string
+		editor   string
+		expected []string
+	}{
+		{
+			test:     "VISUAL",
+			visual:   "code --wait",
+			editor:   "nano",
+			expected: []string{"code", "--wait"},
+		},
+		{
+			test:     "EDITOR",
+			editor:   "nano",
+			expected: []string{"nano"},
+		},
+		{
+			test:     "Default",
+			expected: []string{"vi"},
+		},
+	}
+
+	defer os.Setenv("VISUAL", os.Getenv("VISUAL"))
+	defer os.Setenv("EDITOR", os.Getenv("EDITOR"))
+	for _, tc := range testCases {
+		os.Setenv("VISUAL", tc.visual)
+		os.Setenv("EDITOR", tc.editor)
+		if got := editorCommand(); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Test case %s: expected: %v, got: %v", tc.test, tc.expected, got)
+		}
+	}
+}
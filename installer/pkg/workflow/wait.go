@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	adminCertPath     = "generated/tls/admin.crt"
+	adminKeyPath      = "generated/tls/admin.key"
+	ingressCACertPath = "generated/tls/ingress-ca.crt"
+	kubeCACertPath    = "generated/tls/kube-ca.crt"
+	waitRetryInterval = 10 * time.Second
+)
+
+// WaitForBootstrapCompleteWorkflow creates new instances of the 'wait-for bootstrap-complete' workflow,
+// responsible for waiting until the API of the cluster, bootstrapped by `install bootstrap`, is healthy.
+func WaitForBootstrapCompleteWorkflow(clusterDir string, timeout time.Duration) Workflow {
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			readClusterConfigStep,
+			func(m *metadata) error {
+				return waitForAPIStep(m, timeout)
+			},
+		},
+	}
+}
+
+// WaitForInstallCompleteWorkflow creates new instances of the 'wait-for install-complete' workflow,
+// responsible for waiting until the console of the cluster is served and printing how to log in.
+func WaitForInstallCompleteWorkflow(clusterDir string, timeout time.Duration) Workflow {
+	deadline := time.Now().Add(timeout)
+	return Workflow{
+		metadata: metadata{clusterDir: clusterDir},
+		steps: []Step{
+			readClusterConfigStep,
+			func(m *metadata) error {
+				return waitForAPIStep(m, time.Until(deadline))
+			},
+			func(m *metadata) error {
+				return waitForConsoleStep(m, time.Until(deadline))
+			},
+		},
+	}
+}
+
+func waitForAPIStep(m *metadata, timeout time.Duration) error {
+	client, err := apiClient(m.clusterDir)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://%s:%d/healthz", m.cluster.APIHost(), m.cluster.Networking.APIPort)
+	log.Infof("Waiting up to %s for the API at %s...", timeout, url)
+	defer m.timings.timeStep("bootstrap-complete", time.Now())
+	if err := waitForURL(m.context(), client, url, "ok", timeout, waitRetryInterval); err != nil {
+		return withExitCode(err, ExitCodeBootstrapTimeout)
+	}
+	log.Info("The API is up; bootstrapping is complete")
+	return nil
+}
+
+func waitForConsoleStep(m *metadata, timeout time.Duration) error {
+	pool, err := certPool(m.clusterDir, ingressCACertPath)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Timeout:   waitRetryInterval,
+		Transport: httpTransport(&tls.Config{RootCAs: pool}),
+	}
+	url := fmt.Sprintf("https://%s/", ingressDomain(m))
+	log.Infof("Waiting up to %s for the console at %s...", timeout, url)
+	defer m.timings.timeStep("install-complete", time.Now())
+	done := make(chan struct{})
+	go reportOperatorsUntil(m, done)
+	err = waitForURL(m.context(), client, url, "", timeout, waitRetryInterval)
+	close(done)
+	if err != nil {
+		return withExitCode(err, ExitCodeInstallTimeout)
+	}
+	log.Infof("Install complete! The console is available at %s", url)
+	log.Info(loginMessage(m))
+	return nil
+}
+
+// loginMessage tells how to log in to the console. The password itself is a
+// registered secret, which the logs mask, so it points at where it is kept.
+func loginMessage(m *metadata) string {
+	path := filepath.Join(m.clusterDir, authPath, adminPasswordFileName)
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(m.clusterDir, configFileName)
+	}
+	return fmt.Sprintf("Log in as user %q with the password in %s", m.cluster.Admin.Email, path)
+}
+
+// ingressDomain returns the domain applications, including the console, are exposed under.
+func ingressDomain(m *metadata) string {
+	if m.cluster.IngressDomain != "" {
+		return m.cluster.IngressDomain
+	}
+	return fmt.Sprintf("%s.%s", m.cluster.Name, m.cluster.DNSDomain())
+}
+
+// apiClient returns an HTTP client trusting the cluster API and authenticating as the cluster admin.
+func apiClient(clusterDir string) (*http.Client, error) {
+	pool, err := certPool(clusterDir, kubeCACertPath)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(clusterDir, adminCertPath), filepath.Join(clusterDir, adminKeyPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin certificate: %v", err)
+	}
+	return &http.Client{
+		Timeout: waitRetryInterval,
+		Transport: httpTransport(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      pool,
+		}),
+	}, nil
+}
+
+// httpTransport returns a transport with the given TLS config which, like
+// the default one, honors the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables, since the installer itself may run behind a proxy.
+func httpTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+}
+
+func certPool(clusterDir, caCertPath string) (*x509.CertPool, error) {
+	ca, err := ioutil.ReadFile(filepath.Join(clusterDir, caCertPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to parse CA certificate %s", caCertPath)
+	}
+	return pool, nil
+}
+
+// waitForURL polls the given URL until it responds with 200 OK, and the
+// expected body if one is given, the timeout expires or ctx is canceled.
+func waitForURL(ctx context.Context, client *http.Client, url, expectedBody string, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkURL(client, url, expectedBody)
+		if err == nil {
+			return nil
+		}
+		log.Debugf("Still waiting for %s: %v", url, err)
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %s: %v", timeout, url, err)
+		}
+		select {
+		case <-ctx.Done():
+			return errInterrupted
+		case <-time.After(interval):
+		}
+	}
+}
+
+func checkURL(client *http.Client, url, expectedBody string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if expectedBody != "" && string(body) != expectedBody {
+		return fmt.Errorf("unexpected response %q", body)
+	}
+	return nil
+}
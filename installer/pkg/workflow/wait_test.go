@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func TestWaitForURL(t *testing.T) {
+	var calls int
+	eventuallyHealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer eventuallyHealthy.Close()
+
+	wrongBody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}))
+	defer wrongBody.Close()
+
+	neverHealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer neverHealthy.Close()
+
+	testCases := []struct {
+		test          string
+		server        *httptest.Server
+		expectedBody  string
+		timeout       time.Duration
+		expectedError bool
+	}{
+		{
+			test:          "API becomes healthy",
+			server:        eventuallyHealthy,
+			expectedBody:  "ok",
+			timeout:       time.Second,
+			expectedError: false,
+		},
+		{
+			test:          "API never becomes healthy",
+			server:        neverHealthy,
+			timeout:       10 * time.Millisecond,
+			expectedError: true,
+		},
+		{
+			test:          "Any body accepted",
+			server:        wrongBody,
+			timeout:       time.Second,
+			expectedError: false,
+		},
+		{
+			test:          "Unexpected body",
+			server:        wrongBody,
+			expectedBody:  "ok",
+			timeout:       10 * time.Millisecond,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := waitForURL(context.Background(), tc.server.Client(), tc.server.URL, tc.expectedBody, tc.timeout, time.Millisecond)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Test case %s: waitForURL() expected error: %v, got: %v", tc.test, tc.expectedError, err)
+		}
+	}
+}
+
+func TestLoginMessage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "login")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	m := &metadata{clusterDir: dir}
+	m.cluster.Admin.Email = "admin@example.com"
+	m.cluster.Admin.Password = "verysecure"
+	registerSecrets(m.cluster.Admin.Password)
+
+	passwordFile := filepath.Join(dir, authPath, adminPasswordFileName)
+	testCases := []struct {
+		test     string
+		setup    func() error
+		expected string
+	}{
+		{
+			test:     "Password of the config",
+			setup:    func() error { return nil },
+			expected: `Log in as user "admin@example.com" with the password in ` + filepath.Join(dir, configFileName),
+		},
+		{
+			test: "Generated password",
+			setup: func() error {
+				if err := os.MkdirAll(filepath.Dir(passwordFile), 0700); err != nil {
+					return err
+				}
+				return ioutil.WriteFile(passwordFile, []byte("verysecure\n"), 0600)
+			},
+			expected: `Log in as user "admin@example.com" with the password in ` + passwordFile,
+		},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.setup(); err != nil {
+			t.Fatalf("Test case %s: failed to set up: %v", tc.test, err)
+		}
+		entry := log.NewEntry(log.StandardLogger())
+		entry.Message = loginMessage(m)
+		if err := (redactHook{}).Fire(entry); err != nil {
+			t.Fatalf("Test case %s: failed to fire the hook: %v", tc.test, err)
+		}
+		if entry.Message != tc.expected {
+			t.Errorf("Test case %s: expected: %q, got: %q", tc.test, tc.expected, entry.Message)
+		}
+	}
+}
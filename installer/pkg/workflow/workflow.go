@@ -1,6 +1,21 @@
+// Package workflow drives the installer: the *Workflow functions return the
+// workflows behind the tectonic commands, e.g. InstallFullWorkflow,
+// WaitForInstallCompleteWorkflow or DestroyWorkflow, which programs embedding
+// the installer run with Execute, or ExecuteContext to be able to interrupt
+// them, instead of executing the binary. ExitCode maps the errors they return
+// to the exit codes of the commands. Workflows log through the standard logrus
+// logger and write the output of TerraForm to the standard output and error
+// unless SetOutput is called.
 package workflow
 
-import "github.com/openshift/installer/installer/pkg/config"
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/openshift/installer/installer/pkg/config"
+)
 
 // metadata is the state store of the current workflow execution.
 // It is meant to carry state for one step to another.
@@ -12,6 +27,52 @@ type metadata struct {
 	cluster        config.Cluster
 	configFilePath string
 	clusterDir     string
+	// plan, if set, makes install workflows show the infrastructure
+	// plan and ask for confirmation before creating anything.
+	plan bool
+	// editConfig, if set, makes the init workflow open the cluster config in the user's editor.
+	editConfig bool
+	// progressFile, if set, is where phase changes are reported.
+	progressFile string
+	// percent is the share of the workflow's steps already run.
+	percent int
+	// destroyTimeout, if set, bounds the time each step of the destroy workflow may take.
+	destroyTimeout time.Duration
+	// destroyParallelism, if set, bounds the number of resources terraform destroys concurrently.
+	destroyParallelism int
+	// manifestBundle, if set, is the format the generated manifests are also bundled in.
+	manifestBundle string
+	// notifyURL, if set, is sent a summary of the run of command once it finishes.
+	notifyURL string
+	command   string
+	// retain lists the addresses of the resources the destroy workflow leaves in place.
+	retain []string
+	// fromMetadata is set once the destroy workflow, finding no terraform
+	// state, only read the cluster metadata.
+	fromMetadata bool
+	// retainedNames are the names of the retained resources, which the sweep
+	// of the leftovers of the destroy workflow leaves in place too.
+	retainedNames []string
+	// stdout and stderr, if set, receive the output of TerraForm instead of
+	// the standard output and error.
+	stdout io.Writer
+	stderr io.Writer
+	// timingsFile, if set, is where the timings of the run are written once it finishes.
+	timingsFile string
+	timings     timings
+	// ctx is canceled when the workflow is interrupted.
+	ctx context.Context
+}
+
+// errInterrupted is returned when the context of a workflow is canceled.
+var errInterrupted = errors.New("interrupted")
+
+// context returns the context of the workflow execution.
+func (m *metadata) context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
 }
 
 // Step is the entrypoint of a workflow step implementation.
@@ -26,13 +87,75 @@ type Workflow struct {
 	steps    []Step
 }
 
+// ReportProgressTo makes the workflow write the current phase of its
+// execution, as JSON, to the given file.
+func (w *Workflow) ReportProgressTo(path string) {
+	w.metadata.progressFile = path
+}
+
+// ReportTimingsTo makes the workflow write how long its run, and each of
+// its phases and TerraForm steps, took, as JSON, to the given file once it
+// finishes, whether it succeeds or not.
+func (w *Workflow) ReportTimingsTo(path string) {
+	w.metadata.timingsFile = path
+}
+
+// SetOutput makes the workflow write the output of TerraForm to the given
+// writers instead of the standard output and error, e.g. when the installer
+// is embedded in another program. The output is redacted all the same.
+func (w *Workflow) SetOutput(stdout, stderr io.Writer) {
+	w.metadata.stdout = stdout
+	w.metadata.stderr = stderr
+}
+
+// NotifyTo makes the workflow POST a JSON summary of its run of the given
+// command to the URL once it finishes, whether it succeeds or not.
+func (w *Workflow) NotifyTo(url, command string) {
+	w.metadata.notifyURL = url
+	w.metadata.command = command
+}
+
 // Execute runs all steps in order.
 func (w Workflow) Execute() error {
-	for _, step := range w.steps {
-		if err := step(&w.metadata); err != nil {
+	return w.ExecuteContext(context.Background())
+}
+
+// ExecuteContext runs all steps in order, until ctx is canceled. The current
+// TerraForm step is then interrupted, to let it save its state, and the
+// resources created so far are listed.
+func (w Workflow) ExecuteContext(ctx context.Context) error {
+	w.metadata.ctx = ctx
+	enableRedaction()
+	start := time.Now()
+	err := w.execute()
+	if w.metadata.timingsFile != "" {
+		writeTimings(&w.metadata, time.Since(start), err)
+	}
+	if w.metadata.notifyURL != "" {
+		notify(w.metadata.notifyURL, newNotification(&w.metadata, w.metadata.command, time.Since(start), err))
+	}
+	return err
+}
+
+func (w *Workflow) execute() error {
+	for i, step := range w.steps {
+		w.metadata.percent = i * 100 / len(w.steps)
+		err := w.metadata.context().Err()
+		if err == nil {
+			err = step(&w.metadata)
+		}
+		if err != nil && w.metadata.context().Err() != nil {
+			logCreatedResources(&w.metadata)
+			return withExitCode(errInterrupted, ExitCodeInterrupted)
+		}
+		if err != nil {
 			return err
 		}
 	}
 
+	if w.metadata.progressFile != "" {
+		w.metadata.percent = 100
+		return reportProgress(&w.metadata, phaseComplete)
+	}
 	return nil
 }
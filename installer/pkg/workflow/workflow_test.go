@@ -1,8 +1,14 @@
 package workflow
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func test1Step(m *metadata) error {
@@ -51,3 +57,125 @@ func TestWorkflowTypeExecute(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkflowProgress(t *testing.T) {
+	f, err := ioutil.TempFile("", "progress")
+	if err != nil {
+		t.Fatalf("failed to create progress file: %v", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	var got []progress
+	recordStep := func(m *metadata) error {
+		data, err := ioutil.ReadFile(m.progressFile)
+		if err != nil {
+			return err
+		}
+		var p progress
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		got = append(got, p)
+		return nil
+	}
+
+	wf := Workflow{
+		steps: []Step{
+			phaseStep(phaseAssets),
+			recordStep,
+			phaseStep(phaseRollout),
+			recordStep,
+		},
+	}
+	wf.ReportProgressTo(f.Name())
+	if err := wf.Execute(); err != nil {
+		t.Fatalf("failed to execute workflow: %v", err)
+	}
+	if err := recordStep(&wf.metadata); err != nil {
+		t.Fatalf("failed to read final progress: %v", err)
+	}
+
+	expected := []progress{
+		{Phase: phaseAssets, Percent: 0},
+		{Phase: phaseRollout, Percent: 50},
+		{Phase: phaseComplete, Percent: 100},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d progress reports, got: %v", len(expected), got)
+	}
+	for i, p := range got {
+		if p.Phase != expected[i].Phase || p.Percent != expected[i].Percent || p.Time.IsZero() {
+			t.Errorf("Test case %d: expected progress: %v, got: %v", i, expected[i], p)
+		}
+	}
+}
+
+func TestWorkflowTimings(t *testing.T) {
+	f, err := ioutil.TempFile("", "timings")
+	if err != nil {
+		t.Fatalf("failed to create timings file: %v", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	timedStep := func(m *metadata) error {
+		m.timings.timeStep("test", time.Now())
+		return nil
+	}
+	wf := Workflow{
+		steps: []Step{
+			phaseStep(phaseAssets),
+			timedStep,
+			phaseStep(phaseRollout),
+			test3Step,
+		},
+	}
+	wf.ReportTimingsTo(f.Name())
+	if err := wf.Execute(); err == nil {
+		t.Fatal("expected the workflow to fail")
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("failed to read timings: %v", err)
+	}
+	var got timings
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to parse timings: %v", err)
+	}
+	if got.Result != "failure" {
+		t.Errorf("Test case result: expected: failure, got: %s", got.Result)
+	}
+	if len(got.Phases) != 2 || got.Phases[0].Name != phaseAssets || got.Phases[1].Name != phaseRollout {
+		t.Errorf("Test case phases: expected: [%s %s], got: %v", phaseAssets, phaseRollout, got.Phases)
+	}
+	if len(got.Steps) != 1 || got.Steps[0].Name != "test" {
+		t.Errorf("Test case steps: expected: [test], got: %v", got.Steps)
+	}
+}
+
+func TestWorkflowInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran []string
+	wf := Workflow{
+		steps: []Step{
+			func(m *metadata) error {
+				ran = append(ran, "first")
+				cancel()
+				return nil
+			},
+			func(m *metadata) error {
+				ran = append(ran, "second")
+				return nil
+			},
+		},
+	}
+	err := wf.ExecuteContext(ctx)
+	if code := ExitCode(err); code != ExitCodeInterrupted {
+		t.Errorf("Test case exit code: expected: %d, got: %d (%v)", ExitCodeInterrupted, code, err)
+	}
+	if expected := []string{"first"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("Test case steps: expected: %v, got: %v", expected, ran)
+	}
+}
@@ -6,7 +6,7 @@ data "ignition_config" "tnc" {
   count = "${var.instance_count}"
 
   append {
-    source = "${format("http://${var.cluster_name}-tnc.${var.base_domain}/config/etcd?etcd_index=%d", count.index)}"
+    source = "${format("http://${var.cluster_name}-tnc.${var.cluster_domain}/config/etcd?etcd_index=%d", count.index)}"
 
     # TODO: add verification
   }
@@ -1,5 +1,6 @@
-variable "base_domain" {
-  type = "string"
+variable "cluster_domain" {
+  type        = "string"
+  description = "The domain the host names of the cluster are in"
 }
 
 variable "cluster_id" {
@@ -33,7 +33,8 @@ resource "aws_autoscaling_group" "masters" {
   launch_configuration = "${aws_launch_configuration.master_conf.id}"
   vpc_zone_identifier  = ["${var.subnet_ids}"]
 
-  load_balancers = ["${var.aws_lbs}"]
+  load_balancers    = ["${var.aws_lbs}"]
+  target_group_arns = ["${var.target_group_arns}"]
 
   tags = [
     {
@@ -76,6 +76,12 @@ variable "aws_lbs" {
   default     = []
 }
 
+variable "target_group_arns" {
+  description = "List of target group ARNs of the Network Load Balancers for the API"
+  type        = "list"
+  default     = []
+}
+
 variable "root_volume_iops" {
   type        = "string"
   default     = "100"
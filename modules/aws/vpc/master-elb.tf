@@ -45,7 +45,7 @@ resource "aws_elb" "api_internal" {
   listener {
     instance_port     = 6443
     instance_protocol = "tcp"
-    lb_port           = 6443
+    lb_port           = "${var.api_port}"
     lb_protocol       = "tcp"
   }
 
@@ -65,7 +65,7 @@ resource "aws_elb" "api_internal" {
 }
 
 resource "aws_elb" "api_external" {
-  count           = "${var.public_master_endpoints ? 1 : 0}"
+  count           = "${var.public_master_endpoints && var.api_external_lb_type == "classic" ? 1 : 0}"
   name            = "${var.cluster_name}-ext"
   subnets         = ["${local.master_subnet_ids}"]
   internal        = false
@@ -78,7 +78,7 @@ resource "aws_elb" "api_external" {
   listener {
     instance_port     = 6443
     instance_protocol = "tcp"
-    lb_port           = 6443
+    lb_port           = "${var.api_port}"
     lb_protocol       = "tcp"
   }
 
@@ -97,6 +97,57 @@ resource "aws_elb" "api_external" {
     ), var.extra_tags)}"
 }
 
+# A Network Load Balancer preserves the client IP and has no security group,
+# the master security group allows the API traffic instead.
+resource "aws_lb" "api_external" {
+  count              = "${var.public_master_endpoints && var.api_external_lb_type == "network" ? 1 : 0}"
+  name               = "${var.cluster_name}-ext"
+  load_balancer_type = "network"
+  subnets            = ["${local.master_subnet_ids}"]
+  internal           = false
+
+  tags = "${merge(map(
+      "Name", "${var.cluster_name}-api-external",
+      "kubernetes.io/cluster/${var.cluster_name}", "owned",
+      "tectonicClusterID", "${var.cluster_id}"
+    ), var.extra_tags)}"
+}
+
+resource "aws_lb_target_group" "api_external" {
+  count    = "${var.public_master_endpoints && var.api_external_lb_type == "network" ? 1 : 0}"
+  name     = "${var.cluster_name}-api-ext"
+  protocol = "TCP"
+  port     = 6443
+  vpc_id   = "${data.aws_vpc.cluster_vpc.id}"
+
+  # Network Load Balancers require equal thresholds
+  health_check {
+    protocol            = "TCP"
+    port                = 6443
+    healthy_threshold   = 2
+    unhealthy_threshold = 2
+    interval            = 10
+  }
+
+  tags = "${merge(map(
+      "Name", "${var.cluster_name}-api-external",
+      "kubernetes.io/cluster/${var.cluster_name}", "owned",
+      "tectonicClusterID", "${var.cluster_id}"
+    ), var.extra_tags)}"
+}
+
+resource "aws_lb_listener" "api_external" {
+  count             = "${var.public_master_endpoints && var.api_external_lb_type == "network" ? 1 : 0}"
+  load_balancer_arn = "${aws_lb.api_external.arn}"
+  protocol          = "TCP"
+  port              = "${var.api_port}"
+
+  default_action {
+    type             = "forward"
+    target_group_arn = "${aws_lb_target_group.api_external.arn}"
+  }
+}
+
 resource "aws_elb" "console" {
   name            = "${var.cluster_name}-con"
   subnets         = ["${local.master_subnet_ids}"]
@@ -48,12 +48,16 @@ output "aws_lbs" {
   value = ["${compact(concat(aws_elb.api_internal.*.id, list(aws_elb.console.id), aws_elb.api_external.*.id, aws_elb.tnc.*.id))}"]
 }
 
+output "aws_target_group_arns" {
+  value = ["${aws_lb_target_group.api_external.*.arn}"]
+}
+
 output "aws_api_external_dns_name" {
-  value = "${element(concat(aws_elb.api_external.*.dns_name, list("")), 0)}"
+  value = "${element(concat(aws_elb.api_external.*.dns_name, aws_lb.api_external.*.dns_name, list("")), 0)}"
 }
 
 output "aws_elb_api_external_zone_id" {
-  value = "${element(concat(aws_elb.api_external.*.zone_id, list("")), 0)}"
+  value = "${element(concat(aws_elb.api_external.*.zone_id, aws_lb.api_external.*.zone_id, list("")), 0)}"
 }
 
 output "aws_api_internal_dns_name" {
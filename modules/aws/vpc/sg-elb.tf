@@ -50,8 +50,8 @@ resource "aws_security_group" "api" {
   ingress {
     protocol    = "tcp"
     cidr_blocks = ["0.0.0.0/0"]
-    from_port   = 6443
-    to_port     = 6443
+    from_port   = "${var.api_port}"
+    to_port     = "${var.api_port}"
   }
 }
 
@@ -68,6 +68,17 @@ resource "aws_security_group_rule" "master_ingress_https" {
   to_port     = 6445
 }
 
+resource "aws_security_group_rule" "master_ingress_api_external" {
+  count             = "${var.public_master_endpoints && var.api_external_lb_type == "network" ? 1 : 0}"
+  type              = "ingress"
+  security_group_id = "${aws_security_group.master.id}"
+
+  protocol    = "tcp"
+  cidr_blocks = ["0.0.0.0/0"]
+  from_port   = 6443
+  to_port     = 6443
+}
+
 resource "aws_security_group_rule" "master_ingress_heapster" {
   type              = "ingress"
   security_group_id = "${aws_security_group.master.id}"
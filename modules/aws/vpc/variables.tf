@@ -1,3 +1,15 @@
+variable "api_external_lb_type" {
+  description = "The type of the public-facing API load balancer, classic or network."
+  type        = "string"
+  default     = "classic"
+}
+
+variable "api_port" {
+  description = "The port the API load balancers listen on."
+  type        = "string"
+  default     = "6443"
+}
+
 variable "cidr_block" {
   type = "string"
 }
@@ -14,6 +26,12 @@ variable "cluster_name" {
   type = "string"
 }
 
+variable "dns_servers" {
+  description = "DNS servers of the DHCP options of the new VPC. Empty means the Amazon DNS server."
+  type        = "list"
+  default     = []
+}
+
 variable "external_vpc_id" {
   type = "string"
 }
@@ -42,6 +60,11 @@ variable "new_worker_subnet_configs" {
   type        = "map"
 }
 
+variable "region" {
+  description = "The AWS region, whose default domain the DHCP options of the new VPC keep."
+  type        = "string"
+}
+
 variable "private_master_endpoints" {
   description = "If set to true, private-facing ingress resources are created."
   default     = true
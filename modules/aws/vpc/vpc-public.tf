@@ -65,10 +65,22 @@ resource "aws_eip" "nat_eip" {
   # this can cause the internet gateway to be deleted/detached before the EIPs.
   # https://github.com/coreos/tectonic-installer/issues/1017#issuecomment-307780549
   depends_on = ["aws_internet_gateway.igw"]
+
+  tags = "${merge(map(
+      "Name", "${var.cluster_name}-eip-${count.index}",
+      "kubernetes.io/cluster/${var.cluster_name}", "owned",
+      "tectonicClusterID", "${var.cluster_id}"
+    ), var.extra_tags)}"
 }
 
 resource "aws_nat_gateway" "nat_gw" {
   count         = "${min(local.new_master_az_count,local.new_worker_az_count)}"
   allocation_id = "${aws_eip.nat_eip.*.id[count.index]}"
   subnet_id     = "${aws_subnet.master_subnet.*.id[count.index]}"
+
+  tags = "${merge(map(
+      "Name", "${var.cluster_name}-nat-${count.index}",
+      "kubernetes.io/cluster/${var.cluster_name}", "owned",
+      "tectonicClusterID", "${var.cluster_id}"
+    ), var.extra_tags)}"
 }
@@ -15,3 +15,23 @@ resource "aws_vpc" "new_vpc" {
       "tectonicClusterID", "${var.cluster_id}"
     ), var.extra_tags)}"
 }
+
+# The domain stays the default one of the region, the AWS cloud provider
+# expects the node names to be their private DNS names.
+resource "aws_vpc_dhcp_options" "new_vpc" {
+  count               = "${var.external_vpc_id == "" && length(var.dns_servers) > 0 ? 1 : 0}"
+  domain_name         = "${var.region == "us-east-1" ? "ec2.internal" : "${var.region}.compute.internal"}"
+  domain_name_servers = ["${var.dns_servers}"]
+
+  tags = "${merge(map(
+      "Name", "${var.cluster_name}.${var.base_domain}",
+      "kubernetes.io/cluster/${var.cluster_name}", "owned",
+      "tectonicClusterID", "${var.cluster_id}"
+    ), var.extra_tags)}"
+}
+
+resource "aws_vpc_dhcp_options_association" "new_vpc" {
+  count           = "${var.external_vpc_id == "" && length(var.dns_servers) > 0 ? 1 : 0}"
+  vpc_id          = "${aws_vpc.new_vpc.id}"
+  dhcp_options_id = "${aws_vpc_dhcp_options.new_vpc.id}"
+}
@@ -1,3 +1,5 @@
+# Keep in sync with generatedManifestNames of installer/pkg/workflow, which
+# rejects user supplied manifests of the same names.
 variable "manifest_names" {
   default = [
     "01-tectonic-namespace.yaml",
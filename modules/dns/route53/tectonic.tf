@@ -27,7 +27,7 @@ resource "aws_route53_record" "tectonic_api" {
 resource "aws_route53_record" "tectonic_api_external" {
   count   = "${var.elb_alias_enabled ? local.public_endpoints_count : 0}"
   zone_id = "${local.public_zone_id}"
-  name    = "${var.cluster_name}-api.${var.base_domain}"
+  name    = "${var.cluster_name}-api.${var.cluster_domain}"
   type    = "A"
 
   alias {
@@ -40,7 +40,7 @@ resource "aws_route53_record" "tectonic_api_external" {
 resource "aws_route53_record" "tectonic_api_internal" {
   count   = "${var.elb_alias_enabled ? local.private_endpoints_count : 0}"
   zone_id = "${var.private_zone_id}"
-  name    = "${var.cluster_name}-api.${var.base_domain}"
+  name    = "${var.cluster_name}-api.${var.cluster_domain}"
   type    = "A"
 
   alias {
@@ -62,7 +62,7 @@ resource "aws_route53_record" "tectonic-console" {
 resource "aws_route53_record" "tectonic_ingress_public" {
   count   = "${var.elb_alias_enabled ? local.public_endpoints_count : 0}"
   zone_id = "${local.public_zone_id}"
-  name    = "${var.cluster_name}.${var.base_domain}"
+  name    = "${var.cluster_name}.${var.cluster_domain}"
   type    = "A"
 
   alias {
@@ -75,7 +75,7 @@ resource "aws_route53_record" "tectonic_ingress_public" {
 resource "aws_route53_record" "tectonic_ingress_private" {
   count   = "${var.elb_alias_enabled ? local.private_endpoints_count : 0}"
   zone_id = "${var.private_zone_id}"
-  name    = "${var.cluster_name}.${var.base_domain}"
+  name    = "${var.cluster_name}.${var.cluster_domain}"
   type    = "A"
 
   alias {
@@ -88,7 +88,7 @@ resource "aws_route53_record" "tectonic_ingress_private" {
 resource "aws_route53_record" "routes_ingress_public" {
   count   = "${var.elb_alias_enabled ? local.public_endpoints_count : 0}"
   zone_id = "${local.public_zone_id}"
-  name    = "*.${var.cluster_name}.${var.base_domain}"
+  name    = "*.${var.cluster_name}.${var.cluster_domain}"
   type    = "A"
 
   alias {
@@ -101,7 +101,7 @@ resource "aws_route53_record" "routes_ingress_public" {
 resource "aws_route53_record" "routes_ingress_private" {
   count   = "${var.elb_alias_enabled ? local.private_endpoints_count : 0}"
   zone_id = "${var.private_zone_id}"
-  name    = "*.${var.cluster_name}.${var.base_domain}"
+  name    = "*.${var.cluster_name}.${var.cluster_domain}"
   type    = "A"
 
   alias {
@@ -9,7 +9,12 @@ variable "cluster_name" {
 }
 
 variable "base_domain" {
-  description = "The base domain used in records"
+  description = "The base domain, whose zone the records are created in"
+  type        = "string"
+}
+
+variable "cluster_domain" {
+  description = "The domain the host names of the cluster are in, the base domain or one of its subdomains"
   type        = "string"
 }
 
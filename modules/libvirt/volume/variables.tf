@@ -1,3 +1,8 @@
+variable "cluster_name" {
+  description = "The name of the cluster, prefixing the name of the volume"
+  type        = "string"
+}
+
 variable "coreos_qcow_path" {
   description = "The path on disk to the coreos disk image"
   type        = "string"
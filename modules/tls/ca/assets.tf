@@ -4,6 +4,9 @@ resource "local_file" "root_ca_cert" {
 }
 
 resource "local_file" "root_ca_key" {
+  # the root CA key is not known when it is held by a signer command
+  count = "${var.root_ca_cert_pem_path == "" || var.root_ca_key_pem_path != "" ? 1 : 0}"
+
   content  = "${var.root_ca_key_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : file(local._root_ca_key_pem_path )}"
   filename = "./generated/tls/root-ca.key"
 }
@@ -13,10 +13,10 @@ locals {
 }
 
 resource "tls_private_key" "root_ca" {
-  count = "${var.root_ca_key_pem_path == "" ? 1 : 0}"
+  count = "${var.root_ca_cert_pem_path == "" ? 1 : 0}"
 
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_self_signed_cert" "root_ca" {
@@ -33,8 +33,7 @@ resource "tls_self_signed_cert" "root_ca" {
 
   is_ca_certificate = true
 
-  # root ca defaults to being valid for 10 years.
-  validity_period_hours = "87600"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -52,7 +51,7 @@ resource "tls_private_key" "etcd_ca" {
   count = "${var.etcd_ca_key_pem_path == "" ? 1 : 0}"
 
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "etcd_ca" {
@@ -77,12 +76,11 @@ resource "tls_locally_signed_cert" "etcd_ca" {
 
   cert_request_pem   = "${tls_cert_request.etcd_ca.cert_request_pem}"
   ca_key_algorithm   = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.key_algorithm) : var.root_ca_key_alg}"
-  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : var.root_ca_key_pem_path}"
-  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : var.root_ca_cert_pem_path}"
+  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : file(local._root_ca_key_pem_path)}"
+  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : file(local._root_ca_cert_pem_path)}"
   is_ca_certificate  = true
 
-  # intermediate certs are valid for 3 years.
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -96,7 +94,7 @@ resource "tls_private_key" "kube_ca" {
   count = "${var.kube_ca_key_pem_path == "" ? 1 : 0}"
 
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "kube_ca" {
@@ -122,12 +120,11 @@ resource "tls_locally_signed_cert" "kube_ca" {
   cert_request_pem = "${tls_cert_request.kube_ca.cert_request_pem}"
 
   ca_key_algorithm   = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.key_algorithm) : var.root_ca_key_alg}"
-  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : var.root_ca_key_pem_path}"
-  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : var.root_ca_cert_pem_path}"
+  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : file(local._root_ca_key_pem_path)}"
+  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : file(local._root_ca_cert_pem_path)}"
   is_ca_certificate  = true
 
-  # intermediate certs are valid for 3 years.
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -141,7 +138,7 @@ resource "tls_private_key" "aggregator_ca" {
   count = "${var.aggregator_ca_key_pem_path == "" ? 1 : 0}"
 
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "aggregator_ca" {
@@ -166,12 +163,11 @@ resource "tls_locally_signed_cert" "aggregator_ca" {
 
   cert_request_pem   = "${tls_cert_request.aggregator_ca.cert_request_pem}"
   ca_key_algorithm   = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.key_algorithm) : var.root_ca_key_alg}"
-  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : var.root_ca_key_pem_path}"
-  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : var.root_ca_cert_pem_path}"
+  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : file(local._root_ca_key_pem_path)}"
+  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : file(local._root_ca_cert_pem_path)}"
   is_ca_certificate  = true
 
-  # intermediate certs are valid for 3 years.
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -182,11 +178,15 @@ resource "tls_locally_signed_cert" "aggregator_ca" {
 
 # Intermediate service serving CA (resources/generated/tls/{service-serving-ca.crt,service-serving-ca.key})
 resource "tls_private_key" "service_serving_ca" {
+  count = "${var.service_serving_ca_key_pem_path == "" ? 1 : 0}"
+
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "service_serving_ca" {
+  count = "${var.service_serving_ca_cert_pem_path == "" ? 1 : 0}"
+
   key_algorithm   = "${tls_private_key.service_serving_ca.algorithm}"
   private_key_pem = "${tls_private_key.service_serving_ca.private_key_pem}"
 
@@ -202,15 +202,16 @@ resource "tls_cert_request" "service_serving_ca" {
 }
 
 resource "tls_locally_signed_cert" "service_serving_ca" {
+  count = "${var.service_serving_ca_cert_pem_path == "" ? 1 : 0}"
+
   cert_request_pem = "${tls_cert_request.service_serving_ca.cert_request_pem}"
 
   ca_key_algorithm   = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.key_algorithm) : var.root_ca_key_alg}"
-  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : var.root_ca_key_pem_path}"
-  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : var.root_ca_cert_pem_path}"
+  ca_private_key_pem = "${var.root_ca_cert_pem_path == "" ? join("", tls_private_key.root_ca.*.private_key_pem) : file(local._root_ca_key_pem_path)}"
+  ca_cert_pem        = "${var.root_ca_cert_pem_path == "" ? join("", tls_self_signed_cert.root_ca.*.cert_pem) : file(local._root_ca_cert_pem_path)}"
   is_ca_certificate  = true
 
-  # intermediate certs are valid for 3 years.
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -62,7 +62,7 @@ output "id" {
   value = "${sha1("
   ${join(" ",
     list(local_file.root_ca_cert.id,
-    local_file.root_ca_key.id,
+    join("", local_file.root_ca_key.*.id),
     local_file.kube_ca_key.id,
     local_file.kube_ca_cert.id,
     local_file.aggregator_ca_key.id,
@@ -77,3 +77,15 @@ variable "service_serving_ca_key_pem_path" {
   type    = "string"
   default = ""
 }
+
+variable "rsa_bits" {
+  type        = "string"
+  default     = "2048"
+  description = "The size, in bits, of the generated RSA keys."
+}
+
+variable "validity_hours" {
+  type        = "string"
+  default     = "26280"
+  description = "The validity period, in hours, of the generated certificate authorities."
+}
@@ -2,7 +2,7 @@
 # These are used for "api server"-to-etcd and "etcd operator"-to-etcd client communication
 resource "tls_private_key" "etcd_client" {
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "etcd_client" {
@@ -21,7 +21,7 @@ resource "tls_locally_signed_cert" "etcd_client" {
   ca_key_algorithm      = "${var.etcd_ca_key_alg}"
   ca_private_key_pem    = "${var.etcd_ca_key_pem}"
   ca_cert_pem           = "${var.etcd_ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -9,3 +9,15 @@ variable "etcd_ca_key_alg" {
 variable "etcd_ca_key_pem" {
   type = "string"
 }
+
+variable "rsa_bits" {
+  type        = "string"
+  default     = "2048"
+  description = "The size, in bits, of the generated RSA keys."
+}
+
+variable "validity_hours" {
+  type        = "string"
+  default     = "26280"
+  description = "The validity period, in hours, of the generated certificates."
+}
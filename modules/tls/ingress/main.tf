@@ -9,7 +9,7 @@ resource "tls_private_key" "ingress" {
   count = "${var.key_pem_path == "" ? 1 : 0}"
 
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "ingress" {
@@ -37,7 +37,7 @@ resource "tls_locally_signed_cert" "ingress" {
   ca_key_algorithm      = "${var.ca_key_alg}"
   ca_private_key_pem    = "${var.ca_key_pem}"
   ca_cert_pem           = "${var.ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -31,3 +31,15 @@ variable "cert_pem_path" {
   default     = ""
   description = "The path to the signed public ingress certificate."
 }
+
+variable "rsa_bits" {
+  type        = "string"
+  default     = "2048"
+  description = "The size, in bits, of the generated RSA keys."
+}
+
+variable "validity_hours" {
+  type        = "string"
+  default     = "26280"
+  description = "The validity period, in hours, of the generated certificates."
+}
@@ -2,7 +2,7 @@
 # Used to create kubeconfig (generated/auth/kubeconfig) with admin level privileges.
 resource "tls_private_key" "admin" {
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "admin" {
@@ -10,8 +10,8 @@ resource "tls_cert_request" "admin" {
   private_key_pem = "${tls_private_key.admin.private_key_pem}"
 
   subject {
-    common_name  = "system:admin"
-    organization = "system:masters"
+    common_name  = "${var.admin_cert_common_name}"
+    organization = "${var.admin_cert_organization}"
   }
 }
 
@@ -21,7 +21,7 @@ resource "tls_locally_signed_cert" "admin" {
   ca_key_algorithm      = "${var.kube_ca_key_alg}"
   ca_private_key_pem    = "${var.kube_ca_key_pem}"
   ca_cert_pem           = "${var.kube_ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.admin_cert_validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
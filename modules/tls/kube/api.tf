@@ -1,7 +1,7 @@
 # Kubernetes API Server (resources/generated/tls/{apiserver.key,apiserver.crt})
 resource "tls_private_key" "apiserver" {
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "apiserver" {
@@ -13,17 +13,15 @@ resource "tls_cert_request" "apiserver" {
     organization = "kube-master"
   }
 
-  dns_names = [
-    "${replace(element(split(":", var.kube_apiserver_url), 1), "/", "")}",
+  dns_names = ["${concat(list(
+    replace(element(split(":", var.kube_apiserver_url), 1), "/", ""),
     "kubernetes",
     "kubernetes.default",
     "kubernetes.default.svc",
     "kubernetes.default.svc.cluster.local",
-  ]
+  ), var.extra_dns_names)}"]
 
-  ip_addresses = [
-    "${cidrhost(var.service_cidr, 1)}",
-  ]
+  ip_addresses = ["${concat(list(cidrhost(var.service_cidr, 1)), var.extra_ip_addresses)}"]
 }
 
 resource "tls_locally_signed_cert" "apiserver" {
@@ -32,7 +30,7 @@ resource "tls_locally_signed_cert" "apiserver" {
   ca_key_algorithm      = "${var.kube_ca_key_alg}"
   ca_private_key_pem    = "${var.kube_ca_key_pem}"
   ca_cert_pem           = "${var.kube_ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -45,7 +43,7 @@ resource "tls_locally_signed_cert" "apiserver" {
 # Openshift API Server (resources/generated/tls/{openshift-apiserver.key,openshift-apiserver.crt})
 resource "tls_private_key" "openshift_apiserver" {
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "openshift_apiserver" {
@@ -78,7 +76,7 @@ resource "tls_locally_signed_cert" "openshift_apiserver" {
   ca_key_algorithm      = "${var.aggregator_ca_key_alg}"
   ca_private_key_pem    = "${var.aggregator_ca_key_pem}"
   ca_cert_pem           = "${var.aggregator_ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -1,7 +1,7 @@
 # Kubernetes API Server Proxy (resources/generated/tls/{apiserver-proxy.key,apiserver-proxy.crt})
 resource "tls_private_key" "apiserver_proxy" {
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "apiserver_proxy" {
@@ -20,7 +20,7 @@ resource "tls_locally_signed_cert" "apiserver_proxy" {
   ca_key_algorithm      = "${var.aggregator_ca_key_alg}"
   ca_private_key_pem    = "${var.aggregator_ca_key_pem}"
   ca_cert_pem           = "${var.aggregator_ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "key_encipherment",
@@ -2,7 +2,7 @@
 # Used to create kubeconfig (generated/auth/kubeconfig-kubelet) with CSR only privileges.
 resource "tls_private_key" "kubelet" {
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "kubelet" {
@@ -1,3 +1,21 @@
+variable "admin_cert_common_name" {
+  description = "Common name, i.e. user, of the admin client certificate"
+  type        = "string"
+  default     = "system:admin"
+}
+
+variable "admin_cert_organization" {
+  description = "Organization, i.e. group, of the admin client certificate"
+  type        = "string"
+  default     = "system:masters"
+}
+
+variable "admin_cert_validity_hours" {
+  description = "Validity period of the admin client certificate, in hours"
+  type        = "string"
+  default     = "26280"
+}
+
 variable "kube_ca_cert_pem" {
   description = "PEM-encoded CA certificate"
   type        = "string"
@@ -50,3 +68,27 @@ variable "kube_apiserver_url" {
 variable "service_cidr" {
   type = "string"
 }
+
+variable "extra_dns_names" {
+  description = "Additional DNS names of the API server certificate"
+  type        = "list"
+  default     = []
+}
+
+variable "extra_ip_addresses" {
+  description = "Additional IP addresses of the API server certificate"
+  type        = "list"
+  default     = []
+}
+
+variable "rsa_bits" {
+  type        = "string"
+  default     = "2048"
+  description = "The size, in bits, of the generated RSA keys."
+}
+
+variable "validity_hours" {
+  type        = "string"
+  default     = "26280"
+  description = "The validity period, in hours, of the generated certificates."
+}
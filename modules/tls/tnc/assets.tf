@@ -1,9 +1,9 @@
 resource "local_file" "tnc_cert" {
-  content  = "${tls_locally_signed_cert.tnc.cert_pem}"
+  content  = "${var.cert_pem_path == "" ? join("", tls_locally_signed_cert.tnc.*.cert_pem) : file(local._cert_pem_path)}"
   filename = "./generated/tls/tnc.crt"
 }
 
 resource "local_file" "tnc_key" {
-  content  = "${tls_private_key.tnc.private_key_pem}"
+  content  = "${var.key_pem_path == "" ? join("", tls_private_key.tnc.*.private_key_pem) : file(local._key_pem_path)}"
   filename = "./generated/tls/tnc.key"
 }
@@ -1,10 +1,19 @@
 # These are used for Ignition-to-TNC communication
+locals {
+  _cert_pem_path = "${var.cert_pem_path == "" ? "/dev/null" : var.cert_pem_path}"
+  _key_pem_path  = "${var.key_pem_path == "" ? "/dev/null" : var.key_pem_path}"
+}
+
 resource "tls_private_key" "tnc" {
+  count = "${var.key_pem_path == "" ? 1 : 0}"
+
   algorithm = "RSA"
-  rsa_bits  = "2048"
+  rsa_bits  = "${var.rsa_bits}"
 }
 
 resource "tls_cert_request" "tnc" {
+  count = "${var.cert_pem_path == "" ? 1 : 0}"
+
   key_algorithm   = "${tls_private_key.tnc.algorithm}"
   private_key_pem = "${tls_private_key.tnc.private_key_pem}"
 
@@ -12,18 +21,19 @@ resource "tls_cert_request" "tnc" {
     common_name = "${var.domain}"
   }
 
-  dns_names = [
-    "${var.domain}",
-  ]
+  dns_names    = ["${concat(list(var.domain), var.extra_dns_names)}"]
+  ip_addresses = ["${var.extra_ip_addresses}"]
 }
 
 resource "tls_locally_signed_cert" "tnc" {
+  count = "${var.cert_pem_path == "" ? 1 : 0}"
+
   cert_request_pem = "${tls_cert_request.tnc.cert_request_pem}"
 
   ca_key_algorithm      = "${var.ca_key_alg}"
   ca_private_key_pem    = "${var.ca_key_pem}"
   ca_cert_pem           = "${var.ca_cert_pem}"
-  validity_period_hours = "26280"
+  validity_period_hours = "${var.validity_hours}"
 
   allowed_uses = [
     "server_auth",
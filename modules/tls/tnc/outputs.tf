@@ -1,9 +1,9 @@
 output "tnc_cert_pem" {
-  value = "${tls_locally_signed_cert.tnc.cert_pem}"
+  value = "${var.cert_pem_path == "" ? join("", tls_locally_signed_cert.tnc.*.cert_pem) : file(local._cert_pem_path)}"
 }
 
 output "tnc_key_pem" {
-  value = "${tls_private_key.tnc.private_key_pem}"
+  value = "${var.key_pem_path == "" ? join("", tls_private_key.tnc.*.private_key_pem) : file(local._key_pem_path)}"
 }
 
 output "id" {
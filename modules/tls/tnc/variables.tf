@@ -2,6 +2,30 @@ variable "domain" {
   type = "string"
 }
 
+variable "cert_pem_path" {
+  description = "Path of the PEM-encoded TNC certificate, if it is not to be generated"
+  type        = "string"
+  default     = ""
+}
+
+variable "key_pem_path" {
+  description = "Path of the PEM-encoded TNC key, if it is not to be generated"
+  type        = "string"
+  default     = ""
+}
+
+variable "extra_dns_names" {
+  description = "Additional DNS names of the TNC certificate"
+  type        = "list"
+  default     = []
+}
+
+variable "extra_ip_addresses" {
+  description = "Additional IP addresses of the TNC certificate"
+  type        = "list"
+  default     = []
+}
+
 variable "ca_cert_pem" {
   type = "string"
 }
@@ -13,3 +37,15 @@ variable "ca_key_alg" {
 variable "ca_key_pem" {
   type = "string"
 }
+
+variable "rsa_bits" {
+  type        = "string"
+  default     = "2048"
+  description = "The size, in bits, of the generated RSA keys."
+}
+
+variable "validity_hours" {
+  type        = "string"
+  default     = "26280"
+  description = "The validity period, in hours, of the generated certificates."
+}
@@ -1,12 +1,22 @@
 provider "aws" {
-  region  = "${var.tectonic_aws_region}"
-  profile = "${var.tectonic_aws_profile}"
-  version = "1.8.0"
+  region      = "${var.tectonic_aws_region}"
+  profile     = "${var.tectonic_aws_profile}"
+  max_retries = "${var.tectonic_aws_max_retries}"
+  version     = "1.8.0"
 
   assume_role {
     role_arn     = "${var.tectonic_aws_installer_role == "" ? "" : "${var.tectonic_aws_installer_role}"}"
     session_name = "TECTONIC_INSTALLER_${var.tectonic_cluster_name}"
   }
+
+  endpoints {
+    ec2 = "${lookup(var.tectonic_aws_service_endpoints, "ec2", "")}"
+    elb = "${lookup(var.tectonic_aws_service_endpoints, "elb", "")}"
+    iam = "${lookup(var.tectonic_aws_service_endpoints, "iam", "")}"
+    r53 = "${lookup(var.tectonic_aws_service_endpoints, "route53", "")}"
+    s3  = "${lookup(var.tectonic_aws_service_endpoints, "s3", "")}"
+    sts = "${lookup(var.tectonic_aws_service_endpoints, "sts", "")}"
+  }
 }
 
 data "aws_availability_zones" "azs" {}
@@ -26,9 +36,12 @@ module assets_base {
   tectonic_cluster_cidr            = "${var.tectonic_cluster_cidr}"
   tectonic_cluster_id              = "${var.tectonic_cluster_id}"
   tectonic_cluster_name            = "${var.tectonic_cluster_name}"
-  tectonic_container_images        = "${var.tectonic_container_images}"
+  tectonic_container_images        = "${local.tectonic_container_images}"
   tectonic_container_linux_channel = "${var.tectonic_container_linux_channel}"
   tectonic_container_linux_version = "${var.tectonic_container_linux_version}"
+  tectonic_extra_manifests         = "${var.tectonic_extra_manifests}"
+  tectonic_ingress_domain          = "${var.tectonic_ingress_domain}"
+  tectonic_ntp_servers             = "${var.tectonic_ntp_servers}"
   tectonic_image_re                = "${var.tectonic_image_re}"
   tectonic_kubelet_debug_config    = "${var.tectonic_kubelet_debug_config}"
   tectonic_license_path            = "${var.tectonic_license_path}"
@@ -47,8 +60,8 @@ data "template_file" "rm_assets_sh" {
 
   vars {
     cluster_name       = "${var.tectonic_cluster_name}"
-    awscli_image       = "${var.tectonic_container_images["awscli"]}"
-    bucket_s3_location = "${var.tectonic_cluster_name}-tnc.${var.tectonic_base_domain}"
+    awscli_image       = "${local.tectonic_container_images["awscli"]}"
+    bucket_s3_location = "${var.tectonic_cluster_name}-tnc.${local.tectonic_cluster_domain}"
   }
 }
 
@@ -73,4 +86,9 @@ data "ignition_config" "bootstrap" {
   systemd = [
     "${module.assets_base.ignition_bootstrap_systemd}",
   ]
+
+  # The files and units of the bootstrap-overrides directory of the cluster
+  append {
+    source = "data:text/plain;charset=utf-8;base64,${base64encode(file("${path.cwd}/${var.tectonic_ignition_bootstrap_overrides}"))}"
+  }
 }
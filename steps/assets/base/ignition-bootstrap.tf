@@ -1,19 +1,20 @@
 module "ignition_bootstrap" {
   source = "../../../modules/ignition"
 
-  cloud_provider       = "${var.cloud_provider}"
-  container_images     = "${var.tectonic_container_images}"
-  etcd_ca_cert_pem     = "${local.etcd_ca_cert_pem}"
-  etcd_count           = "${length(data.template_file.etcd_hostname_list.*.id)}"
-  image_re             = "${var.tectonic_image_re}"
-  ingress_ca_cert_pem  = "${local.ingress_ca_cert_pem}"
-  root_ca_cert_pem     = "${local.root_ca_cert_pem}"
-  kube_dns_service_ip  = "${module.bootkube.kube_dns_service_ip}"
-  kubelet_debug_config = "${var.tectonic_kubelet_debug_config}"
-  kubelet_node_label   = "node-role.kubernetes.io/master"
-  kubelet_node_taints  = "node-role.kubernetes.io/master=:NoSchedule"
-  tnc_cert_pem         = "${local.tnc_cert_pem}"
-  tnc_key_pem          = "${local.tnc_key_pem}"
+  cloud_provider        = "${var.cloud_provider}"
+  cloud_provider_config = "${local.cloud_provider_config}"
+  container_images      = "${local.tectonic_container_images}"
+  etcd_ca_cert_pem      = "${local.etcd_ca_cert_pem}"
+  etcd_count            = "${length(data.template_file.etcd_hostname_list.*.id)}"
+  image_re              = "${var.tectonic_image_re}"
+  ingress_ca_cert_pem   = "${local.ingress_ca_cert_pem}"
+  root_ca_cert_pem      = "${local.root_ca_cert_pem}"
+  kube_dns_service_ip   = "${module.bootkube.kube_dns_service_ip}"
+  kubelet_debug_config  = "${var.tectonic_kubelet_debug_config}"
+  kubelet_node_label    = "node-role.kubernetes.io/master"
+  kubelet_node_taints   = "${var.tectonic_worker_count == "0" ? "" : "node-role.kubernetes.io/master=:NoSchedule"}"
+  tnc_cert_pem          = "${local.tnc_cert_pem}"
+  tnc_key_pem           = "${local.tnc_key_pem}"
 }
 
 # The cluster configs written by the install binary external to Terraform.
@@ -28,6 +29,55 @@ data "ignition_file" "kube-system_cluster_config" {
   }
 }
 
+# The manifests supplied by the user in the manifests-extra directory.
+data "ignition_file" "extra_manifests" {
+  count      = "${length(var.tectonic_extra_manifests)}"
+  filesystem = "root"
+  mode       = "0644"
+  path       = "/opt/tectonic/manifests/${var.tectonic_extra_manifests[count.index]}"
+
+  content {
+    content = "${file("./generated/manifests/${var.tectonic_extra_manifests[count.index]}")}"
+  }
+}
+
+# The kubelet of the bootstrap node reads the cloud provider config, if any,
+# like those of the other nodes, which get it from the node controller.
+data "ignition_file" "cloud_provider_config" {
+  count      = "${local.cloud_provider_config != "" ? 1 : 0}"
+  filesystem = "root"
+  mode       = "0644"
+  path       = "/etc/kubernetes/cloud/config"
+
+  content {
+    content = "${local.cloud_provider_config}"
+  }
+}
+
+# The NTP servers supplied by the user, if any.
+data "ignition_file" "timesyncd" {
+  count      = "${length(var.tectonic_ntp_servers) > 0 ? 1 : 0}"
+  filesystem = "root"
+  mode       = "0644"
+  path       = "/etc/systemd/timesyncd.conf"
+
+  content {
+    content = "[Time]\nNTP=${join(" ", var.tectonic_ntp_servers)}\n"
+  }
+}
+
+# The additional trust bundle supplied by the user, if any, like the other nodes get it.
+data "ignition_file" "additional_trust_bundle" {
+  count      = "${var.tectonic_tls_additional_trust_bundle != "" ? 1 : 0}"
+  filesystem = "root"
+  mode       = "0644"
+  path       = "/etc/ssl/certs/additional-trust-bundle.pem"
+
+  content {
+    content = "${local.additional_trust_bundle}"
+  }
+}
+
 data "ignition_file" "tectonic_cluster_config" {
   filesystem = "root"
   mode       = "0644"
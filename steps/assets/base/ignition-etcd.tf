@@ -1,5 +1,5 @@
 data "ignition_config" "etcd" {
   count = "${var.etcd_count}"
 
-  files = ["${module.ignition_bootstrap.etcd_crt_id_list}"]
+  files = ["${concat(module.ignition_bootstrap.etcd_crt_id_list, data.ignition_file.timesyncd.*.id)}"]
 }
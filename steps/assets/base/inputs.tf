@@ -27,4 +27,8 @@ locals {
   tnc_key_pem                  = "${file("${local.tls_path}/tnc.key")}"
   oidc_ca_cert                 = "${file("${local.tls_path}/ingress-ca.crt")}"
   root_ca_cert_pem             = "${file("${local.tls_path}/root-ca.crt")}"
+
+  # the additional trust bundle supplied by the user, if any
+  _additional_trust_bundle_path = "${var.tectonic_tls_additional_trust_bundle == "" ? "/dev/null" : var.tectonic_tls_additional_trust_bundle}"
+  additional_trust_bundle       = "${file(local._additional_trust_bundle_path)}"
 }
@@ -14,6 +14,10 @@ output "ignition_bootstrap_files" {
       data.ignition_file.bootstrap_kubeconfig.id,
       data.ignition_file.kubelet_kubeconfig.id,
     ),
+    data.ignition_file.extra_manifests.*.id,
+    data.ignition_file.cloud_provider_config.*.id,
+    data.ignition_file.timesyncd.*.id,
+    data.ignition_file.additional_trust_bundle.*.id,
     module.ignition_bootstrap.ignition_file_id_list,
     module.bootkube.ignition_file_id_list,
     module.tectonic.ignition_file_id_list,
@@ -41,5 +45,5 @@ output "ignition_etcd" {
 
 # TODO(cdc) clean this up, get rid of ignition_etcd
 output "ignition_etcd_files" {
-  value = ["${module.ignition_bootstrap.etcd_crt_id_list}"]
+  value = ["${concat(module.ignition_bootstrap.etcd_crt_id_list, data.ignition_file.timesyncd.*.id)}"]
 }
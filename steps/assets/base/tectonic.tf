@@ -1,26 +1,32 @@
 locals {
-  ingress_internal_fqdn = "${var.tectonic_cluster_name}.${var.tectonic_base_domain}"
-  api_internal_fqdn     = "${var.tectonic_cluster_name}-api.${var.tectonic_base_domain}"
+  ingress_internal_fqdn = "${local.tectonic_ingress_domain}"
+  api_internal_fqdn     = "${var.tectonic_cluster_name}-api.${local.tectonic_cluster_domain}"
+
+  # The config of the cloud provider of Kubernetes, written by the install
+  # binary; empty when the provider runs with its defaults.
+  cloud_provider_config = "${file("./generated/cloud-provider-config")}"
 }
 
 data "template_file" "etcd_hostname_list" {
   count    = "${var.etcd_count}"
-  template = "${var.tectonic_cluster_name}-etcd-${count.index}.${var.tectonic_base_domain}"
+  template = "${var.tectonic_cluster_name}-etcd-${count.index}.${local.tectonic_cluster_domain}"
 }
 
 module "bootkube" {
   source = "../../../modules/bootkube"
 
   cluster_name       = "${var.tectonic_cluster_name}"
-  kube_apiserver_url = "https://${local.api_internal_fqdn}:6443"
+  kube_apiserver_url = "https://${local.api_internal_fqdn}:${var.tectonic_api_port}"
 
   # Platform-independent variables wiring, do not modify.
-  container_images = "${var.tectonic_container_images}"
+  container_images = "${local.tectonic_container_images}"
 
   service_cidr = "${var.tectonic_service_cidr}"
 
   pull_secret_path = "${pathexpand(var.tectonic_pull_secret_path)}"
 
+  cloud_provider_config = "${local.cloud_provider_config}"
+
   admin_cert_pem               = "${local.admin_cert_pem}"
   admin_key_pem                = "${local.admin_key_pem}"
   aggregator_ca_cert_pem       = "${local.aggregator_ca_cert_pem}"
@@ -54,7 +60,7 @@ module "tectonic" {
   base_address = "${local.ingress_internal_fqdn}"
 
   # Platform-independent variables wiring, do not modify.
-  container_images      = "${var.tectonic_container_images}"
+  container_images      = "${local.tectonic_container_images}"
   container_base_images = "${var.tectonic_container_base_images}"
   versions              = "${var.tectonic_versions}"
 
@@ -10,7 +10,7 @@ module assets_base {
 
   tectonic_base_domain             = "${var.tectonic_base_domain}"
   tectonic_cluster_name            = "${var.tectonic_cluster_name}"
-  tectonic_container_images        = "${var.tectonic_container_images}"
+  tectonic_container_images        = "${local.tectonic_container_images}"
   tectonic_image_re                = "${var.tectonic_image_re}"
   tectonic_kubelet_debug_config    = "${var.tectonic_kubelet_debug_config}"
   tectonic_cluster_cidr            = "${var.tectonic_cluster_cidr}"
@@ -26,6 +26,9 @@ module assets_base {
   tectonic_cluster_id              = "${var.tectonic_cluster_id}"
   tectonic_container_linux_channel = "${var.tectonic_container_linux_channel}"
   tectonic_container_linux_version = "${var.tectonic_container_linux_version}"
+  tectonic_extra_manifests         = "${var.tectonic_extra_manifests}"
+  tectonic_ingress_domain          = "${var.tectonic_ingress_domain}"
+  tectonic_ntp_servers             = "${var.tectonic_ntp_servers}"
 }
 
 # Removing assets is platform-specific
@@ -63,6 +66,11 @@ data "ignition_config" "bootstrap" {
   users = [
     "${data.ignition_user.core.id}",
   ]
+
+  # The files and units of the bootstrap-overrides directory of the cluster
+  append {
+    source = "data:text/plain;charset=utf-8;base64,${base64encode(file("${path.cwd}/${var.tectonic_ignition_bootstrap_overrides}"))}"
+  }
 }
 
 data "ignition_config" "etcd" {
@@ -77,6 +85,6 @@ data "ignition_config" "etcd" {
   ]
 
   append {
-    source = "${format("http://${var.tectonic_cluster_name}-tnc.${var.tectonic_base_domain}:49500/config/etcd?etcd_index=%d", count.index)}"
+    source = "${format("http://${var.tectonic_cluster_name}-tnc.${local.tectonic_cluster_domain}:49500/config/etcd?etcd_index=%d", count.index)}"
   }
 }
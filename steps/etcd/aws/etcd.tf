@@ -1,12 +1,22 @@
 provider "aws" {
-  region  = "${var.tectonic_aws_region}"
-  profile = "${var.tectonic_aws_profile}"
-  version = "1.8.0"
+  region      = "${var.tectonic_aws_region}"
+  profile     = "${var.tectonic_aws_profile}"
+  max_retries = "${var.tectonic_aws_max_retries}"
+  version     = "1.8.0"
 
   assume_role {
     role_arn     = "${var.tectonic_aws_installer_role == "" ? "" : "${var.tectonic_aws_installer_role}"}"
     session_name = "TECTONIC_INSTALLER_${var.tectonic_cluster_name}"
   }
+
+  endpoints {
+    ec2 = "${lookup(var.tectonic_aws_service_endpoints, "ec2", "")}"
+    elb = "${lookup(var.tectonic_aws_service_endpoints, "elb", "")}"
+    iam = "${lookup(var.tectonic_aws_service_endpoints, "iam", "")}"
+    r53 = "${lookup(var.tectonic_aws_service_endpoints, "route53", "")}"
+    s3  = "${lookup(var.tectonic_aws_service_endpoints, "s3", "")}"
+    sts = "${lookup(var.tectonic_aws_service_endpoints, "sts", "")}"
+  }
 }
 
 module "container_linux" {
@@ -20,7 +30,7 @@ data "aws_availability_zones" "azs" {}
 
 data "template_file" "etcd_hostname_list" {
   count    = "${var.tectonic_etcd_count > 0 ? var.tectonic_etcd_count : length(data.aws_availability_zones.azs.names) == 5 ? 5 : 3}"
-  template = "${var.tectonic_cluster_name}-etcd-${count.index}.${var.tectonic_base_domain}"
+  template = "${var.tectonic_cluster_name}-etcd-${count.index}.${local.tectonic_cluster_domain}"
 }
 
 resource "aws_s3_bucket_object" "ignition_etcd" {
@@ -42,10 +52,10 @@ resource "aws_s3_bucket_object" "ignition_etcd" {
 module "etcd" {
   source = "../../../modules/aws/etcd"
 
-  base_domain             = "${var.tectonic_base_domain}"
+  cluster_domain          = "${local.tectonic_cluster_domain}"
   cluster_id              = "${var.tectonic_cluster_id}"
   cluster_name            = "${var.tectonic_cluster_name}"
-  container_image         = "${var.tectonic_container_images["etcd"]}"
+  container_image         = "${local.tectonic_container_images["etcd"]}"
   container_linux_channel = "${var.tectonic_container_linux_channel}"
   container_linux_version = "${module.container_linux.version}"
   ec2_type                = "${var.tectonic_aws_etcd_ec2_type}"
@@ -67,6 +77,6 @@ resource "aws_route53_record" "etcd_a_nodes" {
   type    = "A"
   ttl     = "60"
   zone_id = "${local.private_zone_id}"
-  name    = "${var.tectonic_cluster_name}-etcd-${count.index}"
+  name    = "${var.tectonic_cluster_name}-etcd-${count.index}.${local.tectonic_cluster_domain}"
   records = ["${module.etcd.ip_addresses[count.index]}"]
 }
@@ -1,26 +1,36 @@
 provider "libvirt" {
-  uri = "qemu:///system" #XXX fixme
+  uri = "${var.tectonic_libvirt_uri}"
 }
 
 resource "libvirt_volume" "etcd" {
   count          = "${var.tectonic_etcd_count}"
-  name           = "etcd${count.index}"
+  name           = "${var.tectonic_cluster_name}-etcd${count.index}"
   base_volume_id = "${local.libvirt_base_volume_id}"
+  size           = "${var.tectonic_libvirt_etcd_disk_size * 1073741824}"
 }
 
 resource "libvirt_ignition" "etcd" {
   count   = "${var.tectonic_etcd_count}"
-  name    = "etcd${count.index}.ign"
+  name    = "${var.tectonic_cluster_name}-etcd${count.index}.ign"
   content = "${local.ignition[count.index]}"
 }
 
 resource "libvirt_domain" "etcd" {
   count = "${var.tectonic_etcd_count}"
 
-  name            = "etcd${count.index}"
+  name            = "${var.tectonic_cluster_name}-etcd${count.index}"
   memory          = "${var.tectonic_libvirt_etcd_memory}"
+  vcpu            = "${var.tectonic_libvirt_etcd_vcpu}"
   coreos_ignition = "${element(libvirt_ignition.etcd.*.id,count.index)}"
 
+  # The nvram is only used with the uefi firmware
+  firmware = "${var.tectonic_libvirt_firmware == "uefi" ? var.tectonic_libvirt_firmware_loader : ""}"
+
+  nvram {
+    file     = "/var/lib/libvirt/qemu/nvram/${var.tectonic_cluster_name}-etcd${count.index}_VARS.fd"
+    template = "${var.tectonic_libvirt_firmware_nvram_template}"
+  }
+
   disk {
     volume_id = "${element(libvirt_volume.etcd.*.id, count.index)}"
   }
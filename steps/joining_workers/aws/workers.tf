@@ -1,12 +1,22 @@
 provider "aws" {
-  region  = "${var.tectonic_aws_region}"
-  profile = "${var.tectonic_aws_profile}"
-  version = "1.8.0"
+  region      = "${var.tectonic_aws_region}"
+  profile     = "${var.tectonic_aws_profile}"
+  max_retries = "${var.tectonic_aws_max_retries}"
+  version     = "1.8.0"
 
   assume_role {
     role_arn     = "${var.tectonic_aws_installer_role == "" ? "" : "${var.tectonic_aws_installer_role}"}"
     session_name = "TECTONIC_INSTALLER_${var.tectonic_cluster_name}"
   }
+
+  endpoints {
+    ec2 = "${lookup(var.tectonic_aws_service_endpoints, "ec2", "")}"
+    elb = "${lookup(var.tectonic_aws_service_endpoints, "elb", "")}"
+    iam = "${lookup(var.tectonic_aws_service_endpoints, "iam", "")}"
+    r53 = "${lookup(var.tectonic_aws_service_endpoints, "route53", "")}"
+    s3  = "${lookup(var.tectonic_aws_service_endpoints, "s3", "")}"
+    sts = "${lookup(var.tectonic_aws_service_endpoints, "sts", "")}"
+  }
 }
 
 module "container_linux" {
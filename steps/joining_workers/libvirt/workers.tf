@@ -1,25 +1,35 @@
 provider "libvirt" {
-  uri = "qemu:///system"
+  uri = "${var.tectonic_libvirt_uri}"
 }
 
 resource "libvirt_volume" "worker" {
   count          = "${var.tectonic_worker_count}"
-  name           = "worker${count.index}"
+  name           = "${var.tectonic_cluster_name}-worker${count.index}"
   base_volume_id = "${local.libvirt_base_volume_id}"
+  size           = "${var.tectonic_libvirt_worker_disk_size * 1073741824}"
 }
 
 resource "libvirt_ignition" "worker" {
-  name    = "worker.ign"
+  name    = "${var.tectonic_cluster_name}-worker.ign"
   content = "${file("${path.cwd}/${var.tectonic_ignition_worker}")}"
 }
 
 resource "libvirt_domain" "worker" {
   count = "${var.tectonic_worker_count}"
 
-  name            = "worker${count.index}"
+  name            = "${var.tectonic_cluster_name}-worker${count.index}"
   memory          = "${var.tectonic_libvirt_worker_memory}"
+  vcpu            = "${var.tectonic_libvirt_worker_vcpu}"
   coreos_ignition = "${libvirt_ignition.worker.id}"
 
+  # The nvram is only used with the uefi firmware
+  firmware = "${var.tectonic_libvirt_firmware == "uefi" ? var.tectonic_libvirt_firmware_loader : ""}"
+
+  nvram {
+    file     = "/var/lib/libvirt/qemu/nvram/${var.tectonic_cluster_name}-worker${count.index}_VARS.fd"
+    template = "${var.tectonic_libvirt_firmware_nvram_template}"
+  }
+
   disk {
     volume_id = "${element(libvirt_volume.worker.*.id, count.index)}"
   }
@@ -7,7 +7,8 @@ data "terraform_remote_state" "topology" {
 }
 
 locals {
-  subnet_ids = "${data.terraform_remote_state.topology.subnet_ids_masters}"
-  aws_lbs    = "${data.terraform_remote_state.topology.aws_lbs}"
-  sg_id      = "${data.terraform_remote_state.topology.master_sg_id}"
+  subnet_ids        = "${data.terraform_remote_state.topology.subnet_ids_masters}"
+  aws_lbs           = "${data.terraform_remote_state.topology.aws_lbs}"
+  sg_id             = "${data.terraform_remote_state.topology.master_sg_id}"
+  target_group_arns = "${data.terraform_remote_state.topology.aws_target_group_arns}"
 }
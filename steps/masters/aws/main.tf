@@ -4,14 +4,24 @@ locals {
 }
 
 provider "aws" {
-  region  = "${var.tectonic_aws_region}"
-  profile = "${var.tectonic_aws_profile}"
-  version = "1.8.0"
+  region      = "${var.tectonic_aws_region}"
+  profile     = "${var.tectonic_aws_profile}"
+  max_retries = "${var.tectonic_aws_max_retries}"
+  version     = "1.8.0"
 
   assume_role {
     role_arn     = "${var.tectonic_aws_installer_role == "" ? "" : "${var.tectonic_aws_installer_role}"}"
     session_name = "TECTONIC_INSTALLER_${var.tectonic_cluster_name}"
   }
+
+  endpoints {
+    ec2 = "${lookup(var.tectonic_aws_service_endpoints, "ec2", "")}"
+    elb = "${lookup(var.tectonic_aws_service_endpoints, "elb", "")}"
+    iam = "${lookup(var.tectonic_aws_service_endpoints, "iam", "")}"
+    r53 = "${lookup(var.tectonic_aws_service_endpoints, "route53", "")}"
+    s3  = "${lookup(var.tectonic_aws_service_endpoints, "s3", "")}"
+    sts = "${lookup(var.tectonic_aws_service_endpoints, "sts", "")}"
+  }
 }
 
 module "container_linux" {
@@ -29,10 +39,10 @@ module "masters" {
   base_domain                  = "${var.tectonic_base_domain}"
   cluster_id                   = "${var.tectonic_cluster_id}"
   cluster_name                 = "${var.tectonic_cluster_name}"
-  container_images             = "${var.tectonic_container_images}"
+  container_images             = "${local.tectonic_container_images}"
   container_linux_channel      = "${var.tectonic_container_linux_channel}"
   container_linux_version      = "${module.container_linux.version}"
-  ec2_type                     = "${var.tectonic_aws_master_ec2_type}"
+  ec2_type                     = "${var.tectonic_bootstrap == "true" && var.tectonic_aws_bootstrap_ec2_type != "" ? var.tectonic_aws_bootstrap_ec2_type : var.tectonic_aws_master_ec2_type}"
   extra_tags                   = "${var.tectonic_aws_extra_tags}"
   instance_count               = "${var.tectonic_bootstrap == "true" ? 1 : var.tectonic_master_count}"
   master_iam_role              = "${var.tectonic_aws_master_iam_role_name}"
@@ -44,6 +54,7 @@ module "masters" {
   root_volume_type             = "${var.tectonic_aws_master_root_volume_type}"
   ssh_key                      = "${var.tectonic_aws_ssh_key}"
   subnet_ids                   = "${local.subnet_ids}"
+  target_group_arns            = "${local.target_group_arns}"
   ec2_ami                      = "${var.tectonic_aws_ec2_ami_override}"
   user_data_ign                = "${file("${path.cwd}/${var.tectonic_ignition_master}")}"
 }
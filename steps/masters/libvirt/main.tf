@@ -1,5 +1,5 @@
 provider "libvirt" {
-  uri = "qemu:///system"
+  uri = "${var.tectonic_libvirt_uri}"
 }
 
 locals {
@@ -9,33 +9,46 @@ locals {
 resource "libvirt_volume" "master" {
   count = "${local.master_count}"
 
-  name           = "master${count.index}"
+  name           = "${var.tectonic_cluster_name}-master${count.index}"
   base_volume_id = "${local.libvirt_base_volume_id}"
+
+  # 0 keeps the size of the base volume
+  size = "${(count.index == 0 && var.tectonic_libvirt_bootstrap_disk_size != "" ? var.tectonic_libvirt_bootstrap_disk_size : var.tectonic_libvirt_master_disk_size) * 1073741824}"
 }
 
 # The first master node should be booted with the bootstrap ignition configuration
 resource "libvirt_ignition" "master_bootstrap" {
-  name    = "master-bootstrap.ign"
+  name    = "${var.tectonic_cluster_name}-master-bootstrap.ign"
   content = "${local.ignition_bootstrap}"
 }
 
 # Ignition for the remaining masters
 resource "libvirt_ignition" "master" {
-  name    = "master.ign"
+  name    = "${var.tectonic_cluster_name}-master.ign"
   content = "${file("${path.cwd}/${var.tectonic_ignition_master}")}"
 }
 
 resource "libvirt_domain" "master" {
   count = "${local.master_count}"
 
-  name = "master${count.index}"
+  name = "${var.tectonic_cluster_name}-master${count.index}"
 
-  memory = "${var.tectonic_libvirt_master_memory}"
+  # The first master is the bootstrap node and keeps its size once the other masters join
+  memory = "${count.index == 0 && var.tectonic_libvirt_bootstrap_memory != "" ? var.tectonic_libvirt_bootstrap_memory : var.tectonic_libvirt_master_memory}"
+  vcpu   = "${count.index == 0 && var.tectonic_libvirt_bootstrap_vcpu != "" ? var.tectonic_libvirt_bootstrap_vcpu : var.tectonic_libvirt_master_vcpu}"
 
   # Override ignition for the first (bootstrap) node. It can't be re-ignited,
   # but that's okay for us
   coreos_ignition = "${count.index == 0 ? libvirt_ignition.master_bootstrap.id : libvirt_ignition.master.id}"
 
+  # The nvram is only used with the uefi firmware
+  firmware = "${var.tectonic_libvirt_firmware == "uefi" ? var.tectonic_libvirt_firmware_loader : ""}"
+
+  nvram {
+    file     = "/var/lib/libvirt/qemu/nvram/${var.tectonic_cluster_name}-master${count.index}_VARS.fd"
+    template = "${var.tectonic_libvirt_firmware_nvram_template}"
+  }
+
   disk {
     volume_id = "${element(libvirt_volume.master.*.id, count.index)}"
   }
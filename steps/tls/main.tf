@@ -1,7 +1,10 @@
 locals {
-  api_internal_fqdn     = "${var.tectonic_cluster_name}-api.${var.tectonic_base_domain}"
-  ingress_internal_fqdn = "${var.tectonic_cluster_name}.${var.tectonic_base_domain}"
-  tnc_fqdn              = "${var.tectonic_cluster_name}-tnc.${var.tectonic_base_domain}"
+  api_internal_fqdn     = "${var.tectonic_cluster_name}-api.${local.tectonic_cluster_domain}"
+  ingress_internal_fqdn = "${local.tectonic_ingress_domain}"
+  tnc_fqdn              = "${var.tectonic_cluster_name}-tnc.${local.tectonic_cluster_domain}"
+
+  # the certificates signed by the installer with the root CA signer command, if any
+  signed_path = "${var.tectonic_ca_signed_path == "" ? "" : "${path.cwd}/${var.tectonic_ca_signed_path}"}"
 }
 
 module "ca_certs" {
@@ -10,11 +13,26 @@ module "ca_certs" {
   root_ca_cert_pem_path = "${var.tectonic_ca_cert}"
   root_ca_key_alg       = "${var.tectonic_ca_key_alg}"
   root_ca_key_pem_path  = "${var.tectonic_ca_key}"
+  rsa_bits              = "${var.tectonic_tls_rsa_bits}"
+  validity_hours        = "${var.tectonic_tls_ca_validity_hours}"
+
+  kube_ca_cert_pem_path            = "${local.signed_path == "" ? "" : "${local.signed_path}/kube-ca.crt"}"
+  kube_ca_key_pem_path             = "${local.signed_path == "" ? "" : "${local.signed_path}/kube-ca.key"}"
+  aggregator_ca_cert_pem_path      = "${local.signed_path == "" ? "" : "${local.signed_path}/aggregator-ca.crt"}"
+  aggregator_ca_key_pem_path       = "${local.signed_path == "" ? "" : "${local.signed_path}/aggregator-ca.key"}"
+  service_serving_ca_cert_pem_path = "${local.signed_path == "" ? "" : "${local.signed_path}/service-serving-ca.crt"}"
+  service_serving_ca_key_pem_path  = "${local.signed_path == "" ? "" : "${local.signed_path}/service-serving-ca.key"}"
+  etcd_ca_cert_pem_path            = "${local.signed_path == "" ? "" : "${local.signed_path}/etcd-ca.crt"}"
+  etcd_ca_key_pem_path             = "${local.signed_path == "" ? "" : "${local.signed_path}/etcd-ca.key"}"
 }
 
 module "kube_certs" {
   source = "../../modules/tls/kube"
 
+  admin_cert_common_name    = "${var.tectonic_admin_cert_common_name}"
+  admin_cert_organization   = "${var.tectonic_admin_cert_organization}"
+  admin_cert_validity_hours = "${var.tectonic_admin_cert_validity_hours}"
+
   kube_ca_cert_pem            = "${module.ca_certs.kube_ca_cert_pem}"
   kube_ca_key_alg             = "${module.ca_certs.kube_ca_key_alg}"
   kube_ca_key_pem             = "${module.ca_certs.kube_ca_key_pem}"
@@ -24,8 +42,12 @@ module "kube_certs" {
   service_serving_ca_cert_pem = "${module.ca_certs.service_serving_ca_cert_pem}"
   service_serving_ca_key_alg  = "${module.ca_certs.service_serving_ca_key_alg}"
   service_serving_ca_key_pem  = "${module.ca_certs.service_serving_ca_key_pem}"
-  kube_apiserver_url          = "https://${local.api_internal_fqdn}:6443"
+  kube_apiserver_url          = "https://${local.api_internal_fqdn}:${var.tectonic_api_port}"
   service_cidr                = "${var.tectonic_service_cidr}"
+  rsa_bits                    = "${var.tectonic_tls_rsa_bits}"
+  validity_hours              = "${var.tectonic_tls_cert_validity_hours}"
+  extra_dns_names             = "${var.tectonic_tls_extra_dns_names}"
+  extra_ip_addresses          = "${var.tectonic_tls_extra_ip_addresses}"
 }
 
 module "etcd_certs" {
@@ -34,22 +56,34 @@ module "etcd_certs" {
   etcd_ca_cert_pem = "${module.ca_certs.etcd_ca_cert_pem}"
   etcd_ca_key_alg  = "${module.ca_certs.etcd_ca_key_alg}"
   etcd_ca_key_pem  = "${module.ca_certs.etcd_ca_key_pem}"
+  rsa_bits         = "${var.tectonic_tls_rsa_bits}"
+  validity_hours   = "${var.tectonic_tls_cert_validity_hours}"
 }
 
 module "ingress_certs" {
   source = "../../modules/tls/ingress"
 
-  base_address = "${local.ingress_internal_fqdn}"
-  ca_cert_pem  = "${module.ca_certs.kube_ca_cert_pem}"
-  ca_key_alg   = "${module.ca_certs.kube_ca_key_alg}"
-  ca_key_pem   = "${module.ca_certs.kube_ca_key_pem}"
+  base_address   = "${local.ingress_internal_fqdn}"
+  ca_cert_pem    = "${module.ca_certs.kube_ca_cert_pem}"
+  ca_key_alg     = "${module.ca_certs.kube_ca_key_alg}"
+  ca_key_pem     = "${module.ca_certs.kube_ca_key_pem}"
+  rsa_bits       = "${var.tectonic_tls_rsa_bits}"
+  validity_hours = "${var.tectonic_tls_cert_validity_hours}"
 }
 
 module "tnc_certs" {
   source = "../../modules/tls/tnc"
 
-  domain      = "${local.tnc_fqdn}"
-  ca_cert_pem = "${module.ca_certs.root_ca_cert_pem}"
-  ca_key_alg  = "${module.ca_certs.root_ca_key_alg}"
-  ca_key_pem  = "${module.ca_certs.root_ca_key_pem}"
+  domain         = "${local.tnc_fqdn}"
+  ca_cert_pem    = "${module.ca_certs.root_ca_cert_pem}"
+  ca_key_alg     = "${module.ca_certs.root_ca_key_alg}"
+  ca_key_pem     = "${module.ca_certs.root_ca_key_pem}"
+  rsa_bits       = "${var.tectonic_tls_rsa_bits}"
+  validity_hours = "${var.tectonic_tls_cert_validity_hours}"
+
+  extra_dns_names    = "${var.tectonic_tls_extra_dns_names}"
+  extra_ip_addresses = "${var.tectonic_tls_extra_ip_addresses}"
+
+  cert_pem_path = "${local.signed_path == "" ? "" : "${local.signed_path}/tnc.crt"}"
+  key_pem_path  = "${local.signed_path == "" ? "" : "${local.signed_path}/tnc.key"}"
 }
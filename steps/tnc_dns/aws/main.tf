@@ -1,18 +1,28 @@
 provider "aws" {
-  region  = "${var.tectonic_aws_region}"
-  profile = "${var.tectonic_aws_profile}"
-  version = "1.8.0"
+  region      = "${var.tectonic_aws_region}"
+  profile     = "${var.tectonic_aws_profile}"
+  max_retries = "${var.tectonic_aws_max_retries}"
+  version     = "1.8.0"
 
   assume_role {
     role_arn     = "${var.tectonic_aws_installer_role == "" ? "" : "${var.tectonic_aws_installer_role}"}"
     session_name = "TECTONIC_INSTALLER_${var.tectonic_cluster_name}"
   }
+
+  endpoints {
+    ec2 = "${lookup(var.tectonic_aws_service_endpoints, "ec2", "")}"
+    elb = "${lookup(var.tectonic_aws_service_endpoints, "elb", "")}"
+    iam = "${lookup(var.tectonic_aws_service_endpoints, "iam", "")}"
+    r53 = "${lookup(var.tectonic_aws_service_endpoints, "route53", "")}"
+    s3  = "${lookup(var.tectonic_aws_service_endpoints, "s3", "")}"
+    sts = "${lookup(var.tectonic_aws_service_endpoints, "sts", "")}"
+  }
 }
 
 resource "aws_route53_record" "tectonic_tnc_cname" {
   count   = "${var.tectonic_bootstrap == "true" ? 1 : 0}"
   zone_id = "${local.private_zone_id}"
-  name    = "${var.tectonic_cluster_name}-tnc.${var.tectonic_base_domain}"
+  name    = "${var.tectonic_cluster_name}-tnc.${local.tectonic_cluster_domain}"
   type    = "CNAME"
   ttl     = "1"
 
@@ -23,7 +33,7 @@ resource "aws_route53_record" "tectonic_tnc_a" {
   depends_on = ["aws_route53_record.tectonic_tnc_cname"]
   count      = "${var.tectonic_bootstrap == "true" ? 0 : 1}"
   zone_id    = "${local.private_zone_id}"
-  name       = "${var.tectonic_cluster_name}-tnc.${var.tectonic_base_domain}"
+  name       = "${var.tectonic_cluster_name}-tnc.${local.tectonic_cluster_domain}"
   type       = "A"
 
   alias {
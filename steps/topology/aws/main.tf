@@ -4,14 +4,24 @@ locals {
 }
 
 provider "aws" {
-  region  = "${var.tectonic_aws_region}"
-  profile = "${var.tectonic_aws_profile}"
-  version = "1.8.0"
+  region      = "${var.tectonic_aws_region}"
+  profile     = "${var.tectonic_aws_profile}"
+  max_retries = "${var.tectonic_aws_max_retries}"
+  version     = "1.8.0"
 
   assume_role {
     role_arn     = "${var.tectonic_aws_installer_role == "" ? "" : "${var.tectonic_aws_installer_role}"}"
     session_name = "TECTONIC_INSTALLER_${var.tectonic_cluster_name}"
   }
+
+  endpoints {
+    ec2 = "${lookup(var.tectonic_aws_service_endpoints, "ec2", "")}"
+    elb = "${lookup(var.tectonic_aws_service_endpoints, "elb", "")}"
+    iam = "${lookup(var.tectonic_aws_service_endpoints, "iam", "")}"
+    r53 = "${lookup(var.tectonic_aws_service_endpoints, "route53", "")}"
+    s3  = "${lookup(var.tectonic_aws_service_endpoints, "s3", "")}"
+    sts = "${lookup(var.tectonic_aws_service_endpoints, "sts", "")}"
+  }
 }
 
 data "aws_availability_zones" "azs" {}
@@ -40,11 +50,14 @@ resource "aws_route53_zone" "tectonic_int" {
 module "vpc" {
   source = "../../../modules/aws/vpc"
 
-  base_domain     = "${var.tectonic_base_domain}"
-  cidr_block      = "${var.tectonic_aws_vpc_cidr_block}"
-  cluster_id      = "${var.tectonic_cluster_id}"
-  cluster_name    = "${var.tectonic_cluster_name}"
-  external_vpc_id = "${var.tectonic_aws_external_vpc_id}"
+  api_external_lb_type = "${var.tectonic_aws_api_external_lb_type}"
+  api_port             = "${var.tectonic_api_port}"
+  base_domain          = "${var.tectonic_base_domain}"
+  cidr_block           = "${var.tectonic_aws_vpc_cidr_block}"
+  cluster_id           = "${var.tectonic_cluster_id}"
+  cluster_name         = "${var.tectonic_cluster_name}"
+  dns_servers          = "${var.tectonic_aws_vpc_dns_servers}"
+  external_vpc_id      = "${var.tectonic_aws_external_vpc_id}"
 
   external_master_subnet_ids = "${compact(var.tectonic_aws_external_master_subnet_ids)}"
   external_worker_subnet_ids = "${compact(var.tectonic_aws_external_worker_subnet_ids)}"
@@ -56,6 +69,7 @@ module "vpc" {
 
   private_master_endpoints = "${local.private_endpoints}"
   public_master_endpoints  = "${local.public_endpoints}"
+  region                   = "${var.tectonic_aws_region}"
 }
 
 module "dns" {
@@ -67,6 +81,7 @@ module "dns" {
   api_internal_elb_zone_id  = "${module.vpc.aws_elb_api_internal_zone_id}"
   api_ip_addresses          = "${module.vpc.aws_lbs}"
   base_domain               = "${var.tectonic_base_domain}"
+  cluster_domain            = "${local.tectonic_cluster_domain}"
   cluster_id                = "${var.tectonic_cluster_id}"
   cluster_name              = "${var.tectonic_cluster_name}"
   console_elb_dns_name      = "${module.vpc.aws_console_dns_name}"
@@ -16,6 +16,10 @@ output "aws_lbs" {
   value = "${module.vpc.aws_lbs}"
 }
 
+output "aws_target_group_arns" {
+  value = "${module.vpc.aws_target_group_arns}"
+}
+
 output "master_sg_id" {
   value = "${module.vpc.master_sg_id}"
 }
@@ -8,7 +8,7 @@
 resource "aws_s3_bucket" "tectonic" {
   # This bucket name must match the CNAME
   # https://docs.aws.amazon.com/AmazonS3/latest/dev/VirtualHosting.html#VirtualHostingCustomURLs
-  bucket = "${lower(var.tectonic_cluster_name)}-tnc.${var.tectonic_base_domain}"
+  bucket = "${lower(var.tectonic_cluster_name)}-tnc.${local.tectonic_cluster_domain}"
 
   acl = "private"
 
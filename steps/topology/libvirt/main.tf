@@ -1,5 +1,5 @@
 provider "libvirt" {
-  uri = "qemu:///system" #XXX fixme
+  uri = "${var.tectonic_libvirt_uri}"
 }
 
 # Create the bridge for libvirt
@@ -9,7 +9,7 @@ resource "libvirt_network" "tectonic_net" {
   mode   = "nat"
   bridge = "${var.tectonic_libvirt_network_if}"
 
-  domain = "${var.tectonic_base_domain}"
+  domain = "${local.tectonic_cluster_domain}"
 
   addresses = [
     "${var.tectonic_libvirt_ip_range}",
@@ -23,17 +23,32 @@ resource "libvirt_network" "tectonic_net" {
 module "libvirt_base_volume" {
   source = "../../../modules/libvirt/volume"
 
+  cluster_name     = "${var.tectonic_cluster_name}"
   coreos_qcow_path = "${var.tectonic_coreos_qcow_path}"
 }
 
 locals {
   first_worker_ip = "${cidrhost(var.tectonic_libvirt_ip_range, var.tectonic_libvirt_first_ip_worker)}"
+
+  # a single-node cluster serves the console from its master
+  console_ip = "${var.tectonic_worker_count == "0" ? element(var.tectonic_libvirt_master_ips, 0) : local.first_worker_ip}"
 }
 
 # Set up the cluster domain name
-# This is currently limited to the first worker, due to an issue with net-update, even though libvirt supports multiple a-records
+# This is currently limited to the first worker (or the master of a single-node cluster), due to an issue with net-update, even though libvirt supports multiple a-records
 resource "null_resource" "console_dns" {
   provisioner "local-exec" {
-    command = "virsh -c qemu:///system net-update ${var.tectonic_libvirt_network_name} add dns-host \"<host ip='${local.first_worker_ip}'><hostname>${var.tectonic_cluster_name}</hostname></host>\" --live --config"
+    command = "virsh -c '${var.tectonic_libvirt_uri}' net-update ${var.tectonic_libvirt_network_name} add dns-host \"<host ip='${local.console_ip}'><hostname>${var.tectonic_cluster_name}</hostname></host>\" --live --config"
   }
 }
+
+# Add the extra host records of the network, one per IP
+resource "null_resource" "dns_hosts" {
+  count = "${length(keys(var.tectonic_libvirt_dns_hosts))}"
+
+  provisioner "local-exec" {
+    command = "virsh -c '${var.tectonic_libvirt_uri}' net-update ${var.tectonic_libvirt_network_name} add dns-host \"<host ip='${lookup(var.tectonic_libvirt_dns_hosts, element(keys(var.tectonic_libvirt_dns_hosts), count.index))}'><hostname>${element(keys(var.tectonic_libvirt_dns_hosts), count.index)}</hostname></host>\" --live --config"
+  }
+
+  depends_on = ["libvirt_network.tectonic_net"]
+}
@@ -10,6 +10,8 @@ EOF
 variable "tectonic_aws_profile" {
   description = <<EOF
 (optional) This declares the AWS credentials profile to use.
+It may be defined in the shared credentials file or in the shared config file,
+e.g. to assume a role, use SSO or a credential_process.
 EOF
 
   type = "string"
@@ -26,6 +28,12 @@ variable "tectonic_aws_master_ec2_type" {
   default     = "t2.medium"
 }
 
+variable "tectonic_aws_bootstrap_ec2_type" {
+  type        = "string"
+  description = "(optional) Instance size for the bootstrap node, the first master, while the cluster bootstraps. Defaults to `tectonic_aws_master_ec2_type`. Example: `m4.xlarge`."
+  default     = ""
+}
+
 variable "tectonic_aws_worker_ec2_type" {
   type        = "string"
   description = "Instance size for the worker node(s). Example: `t2.medium`."
@@ -90,6 +98,18 @@ This should not overlap with any other networks, such as a private datacenter co
 EOF
 }
 
+variable "tectonic_aws_vpc_dns_servers" {
+  type    = "list"
+  default = []
+
+  description = <<EOF
+(optional) DNS servers the nodes of the VPC created by the installer use instead of the Amazon DNS server, e.g. corporate DNS servers.
+They must forward the cluster domain to the Amazon DNS server of the VPC, for the nodes to resolve the records of the private zone.
+
+Example: `["10.1.0.2", "10.1.0.3"]`
+EOF
+}
+
 variable "tectonic_aws_external_vpc_id" {
   type = "string"
 
@@ -103,6 +123,16 @@ EOF
   default = ""
 }
 
+variable "tectonic_aws_api_external_lb_type" {
+  type    = "string"
+  default = "classic"
+
+  description = <<EOF
+(optional) The type of the public-facing API load balancer: "classic", the default, for an Elastic Load Balancer,
+or "network" for a Network Load Balancer. The private-facing API load balancer stays classic.
+EOF
+}
+
 variable "tectonic_aws_endpoints" {
   description = <<EOF
 (optional) If set to "all", the default, then both public and private ingress resources (ELB, A-records) will be created.
@@ -153,7 +183,7 @@ variable "tectonic_aws_extra_tags" {
   type = "map"
 
   description = <<EOF
-(optional) Extra AWS tags to be applied to created resources.
+(optional) Extra AWS tags to be applied to created resources, e.g. for cost allocation. Keys must not use the `aws:` prefix nor override the `Name`, `tectonicClusterID` and `kubernetes.io/cluster/<name>` tags set by the installer.
 
 Example: `{ "key" = "value", "foo" = "bar" }`
 EOF
@@ -267,6 +297,18 @@ variable "tectonic_aws_region" {
   description = "The target AWS region for the cluster."
 }
 
+variable "tectonic_aws_service_endpoints" {
+  type    = "map"
+  default = {}
+
+  description = <<EOF
+(optional) Custom endpoints of the AWS services used by the installer, e.g. private VPC endpoints.
+The supported services are `ec2`, `elb`, `iam`, `route53`, `s3` and `sts`; the others use the default endpoints.
+
+Example: `{ ec2 = "https://vpce-0123.ec2.eu-west-1.vpce.amazonaws.com" }`
+EOF
+}
+
 variable "tectonic_aws_installer_role" {
   type    = "string"
   default = ""
@@ -280,6 +322,16 @@ Example:
 EOF
 }
 
+variable "tectonic_aws_max_retries" {
+  type    = "string"
+  default = "25"
+
+  description = <<EOF
+(optional) The maximum number of times a throttled or failed AWS API call is retried, with an exponential backoff.
+The TECTONIC_AWS_MAX_RETRIES environment variable overrides it.
+EOF
+}
+
 variable "tectonic_aws_master_iam_role_name" {
   type    = "string"
   default = ""
@@ -1,3 +1,9 @@
+variable "tectonic_libvirt_uri" {
+  type        = "string"
+  description = "libvirt connection URI, e.g. qemu:///system, qemu+ssh://root@host/system or qemu+tls://host/system"
+  default     = "qemu:///system"
+}
+
 variable "tectonic_libvirt_ssh_key" {
   type        = "string"
   description = "Contents of an SSH key to install for the core user"
@@ -23,6 +29,12 @@ variable "tectonic_libvirt_resolver" {
   description = "the upstream dns resolver"
 }
 
+variable "tectonic_libvirt_dns_hosts" {
+  type        = "map"
+  description = "extra host names, mapped to their IP, resolved by the dnsmasq of the libvirt network"
+  default     = {}
+}
+
 variable "tectonic_coreos_qcow_path" {
   type        = "string"
   description = "path to a container linux qcow image"
@@ -45,20 +57,92 @@ variable "tectonic_libvirt_first_ip_worker" {
   default     = "50"
 }
 
+variable "tectonic_libvirt_firmware" {
+  type        = "string"
+  description = "the firmware the nodes boot with, bios or uefi"
+  default     = "bios"
+}
+
+variable "tectonic_libvirt_firmware_loader" {
+  type        = "string"
+  description = "path of the uefi firmware code on the libvirt host"
+  default     = "/usr/share/edk2/ovmf/OVMF_CODE.fd"
+}
+
+variable "tectonic_libvirt_firmware_nvram_template" {
+  type        = "string"
+  description = "path of the uefi variables store on the libvirt host the nvram of each node is copied from"
+  default     = "/usr/share/edk2/ovmf/OVMF_VARS.fd"
+}
+
 variable "tectonic_libvirt_etcd_memory" {
   type        = "string"
   description = "ram to allocate for each etcd node"
   default     = "1024"
 }
 
+variable "tectonic_libvirt_etcd_vcpu" {
+  type        = "string"
+  description = "virtual cpus to allocate for each etcd node"
+  default     = "1"
+}
+
+variable "tectonic_libvirt_etcd_disk_size" {
+  type        = "string"
+  description = "disk size in GiB of each etcd node. Defaults to the size of the container linux image"
+  default     = "0"
+}
+
 variable "tectonic_libvirt_master_memory" {
   type        = "string"
-  description = "ram to allocate for each etcd node"
+  description = "ram to allocate for each master node"
   default     = "2048"
 }
 
+variable "tectonic_libvirt_master_vcpu" {
+  type        = "string"
+  description = "virtual cpus to allocate for each master node"
+  default     = "1"
+}
+
+variable "tectonic_libvirt_master_disk_size" {
+  type        = "string"
+  description = "disk size in GiB of each master node. Defaults to the size of the container linux image"
+  default     = "0"
+}
+
+variable "tectonic_libvirt_bootstrap_memory" {
+  type        = "string"
+  description = "ram to allocate for the bootstrap node, the first master. Defaults to tectonic_libvirt_master_memory"
+  default     = ""
+}
+
+variable "tectonic_libvirt_bootstrap_vcpu" {
+  type        = "string"
+  description = "virtual cpus to allocate for the bootstrap node, the first master. Defaults to tectonic_libvirt_master_vcpu"
+  default     = ""
+}
+
+variable "tectonic_libvirt_bootstrap_disk_size" {
+  type        = "string"
+  description = "disk size in GiB of the bootstrap node, the first master. Defaults to tectonic_libvirt_master_disk_size"
+  default     = ""
+}
+
 variable "tectonic_libvirt_worker_memory" {
   type        = "string"
-  description = "ram to allocate for each etcd node"
+  description = "ram to allocate for each worker node"
   default     = "1024"
 }
+
+variable "tectonic_libvirt_worker_vcpu" {
+  type        = "string"
+  description = "virtual cpus to allocate for each worker node"
+  default     = "1"
+}
+
+variable "tectonic_libvirt_worker_disk_size" {
+  type        = "string"
+  description = "disk size in GiB of each worker node. Defaults to the size of the container linux image"
+  default     = "0"
+}